@@ -2,51 +2,212 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/jaminalder/estimations/internal/adapters/eventfile"
 	httpadapter "github.com/jaminalder/estimations/internal/adapters/http"
 	"github.com/jaminalder/estimations/internal/adapters/idgen"
 	"github.com/jaminalder/estimations/internal/adapters/memory"
+	"github.com/jaminalder/estimations/internal/adapters/metrics"
+	"github.com/jaminalder/estimations/internal/adapters/sqlite"
 	"github.com/jaminalder/estimations/internal/adapters/sse"
 	"github.com/jaminalder/estimations/internal/app"
+	"github.com/jaminalder/estimations/internal/config"
 )
 
 func main() {
+	cfg, printConfig, err := config.Load(os.Args[1:], os.Getenv)
+	if errors.Is(err, flag.ErrHelp) {
+		config.Usage(os.Stdout)
+		return
+	} else if err != nil {
+		log.Fatalf("config: %v", err)
+	}
+	if printConfig {
+		if err := cfg.WriteJSON(os.Stdout); err != nil {
+			log.Fatalf("print config: %v", err)
+		}
+		return
+	}
+	logger := newLogger(cfg)
+	slog.SetDefault(logger)
+
 	// Wire dependencies
-	repo := memory.NewRoomRepo()
-	ids := idgen.NewRandom(10, 8)
-	hub := sse.NewHub(16)
-	svc := &app.Service{Rooms: repo, Ids: ids, Bus: hub}
+	hub := sse.NewHub(cfg.HubBuffer, sse.WithHistory(cfg.HubHistory))
+	svc := &app.Service{
+		Bus:             hub,
+		MaxParticipants: cfg.MaxParticipants,
+		MaxObservers:    cfg.MaxObservers,
+		Logger:          logger,
+	}
+	switch cfg.Storage {
+	case config.StorageMemory:
+		svc.Rooms = memory.NewRoomRepo()
+	case config.StorageSQLite:
+		db, err := sqlite.Open(context.Background(), cfg.StoragePath)
+		if err != nil {
+			fatal("database", err)
+		}
+		defer db.Close()
+		svc.Rooms = db
+		slog.Info("storing rooms", "path", cfg.StoragePath)
+	case config.StorageEvents:
+		store, err := eventfile.Open(cfg.StoragePath)
+		if err != nil {
+			fatal("event store", err)
+		}
+		svc.Rooms = memory.NewRoomRepo()
+		svc.Events = store
+		n, err := svc.ReplayRooms(context.Background())
+		if err != nil {
+			fatal("replay", err)
+		}
+		slog.Info("recording events", "path", cfg.StoragePath, "replayed_rooms", n)
+	}
+	if cfg.RoomIDs == config.RoomIDsCode {
+		svc.Ids = idgen.NewCodes(svc.Rooms, idgen.DefaultCodeLen, cfg.PartIDBytes)
+	} else {
+		svc.Ids = idgen.NewRandom(cfg.RoomIDBytes, cfg.PartIDBytes)
+	}
+
+	// Metrics
+	reg := metrics.NewRegistry()
+	svc.Metrics = metrics.RegisterCommands(reg)
+	metrics.RegisterRooms(reg, svc)
+	metrics.RegisterHub(reg, hub)
 
 	// Renderer and server
 	rend, err := httpadapter.NewRenderer()
 	if err != nil {
-		log.Fatalf("templates: %v", err)
+		fatal("templates", err)
+	}
+	handler := httpadapter.NewServer(svc, rend,
+		httpadapter.WithEvents(hub), httpadapter.WithMetrics(reg), httpadapter.WithLogger(logger),
+		httpadapter.WithAdminToken(cfg.AdminToken), httpadapter.WithSessionKeys(cfg.SessionKeys()...),
+		httpadapter.WithBaseURL(cfg.BaseURL))
+	if cfg.SessionSecrets == "" {
+		slog.Warn("no session-secrets configured; participants lose their session when the server restarts")
 	}
-	handler := httpadapter.NewServer(svc, rend, httpadapter.WithLogger(log.Default()))
 
 	srv := &http.Server{
-		Addr:    ":8080",
+		Addr:    cfg.Listen,
 		Handler: handler,
 	}
 
 	// Graceful shutdown
 	go func() {
-		log.Printf("HTTP listening on %s", srv.Addr)
+		slog.Info("HTTP listening", "addr", srv.Addr, "base_url", cfg.BaseURL)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("listen: %v", err)
+			fatal("listen", err)
 		}
 	}()
 
+	// Metrics are served separately: per-room series carry room IDs, which
+	// must not be public, so the default address is loopback only.
+	var metricsSrv *http.Server
+	if cfg.MetricsListen != "" {
+		mux := http.NewServeMux()
+		mux.Handle("GET /metrics", reg)
+		metricsSrv = &http.Server{Addr: cfg.MetricsListen, Handler: mux}
+		go func() {
+			slog.Info("metrics listening", "addr", metricsSrv.Addr)
+			if err := metricsSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				fatal("metrics listen", err)
+			}
+		}()
+	}
+
+	reapCtx, stopReaper := context.WithCancel(context.Background())
+	go reap(reapCtx, svc, app.ExpiryPolicy{EmptyTTL: cfg.EmptyTTL, IdleTTL: cfg.IdleTTL})
+	go dropDisconnected(reapCtx, svc, cfg.PresenceGrace)
+
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
 	<-stop
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	stopReaper()
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
 	defer cancel()
 	_ = srv.Shutdown(ctx)
+	if metricsSrv != nil {
+		_ = metricsSrv.Shutdown(ctx)
+	}
+}
+
+// newLogger builds the logger for the configured level and format.
+func newLogger(cfg config.Config) *slog.Logger {
+	var level slog.Level
+	_ = level.UnmarshalText([]byte(cfg.LogLevel)) // validated by config
+	opts := &slog.HandlerOptions{Level: level}
+	if cfg.LogFormat == "json" {
+		return slog.New(slog.NewJSONHandler(os.Stderr, opts))
+	}
+	return slog.New(slog.NewTextHandler(os.Stderr, opts))
+}
+
+// fatal logs err and exits.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+// reapInterval is how often expired rooms are collected; expiry times are
+// accurate to one interval.
+const reapInterval = time.Minute
+
+// reap periodically removes empty and idle rooms until ctx is cancelled.
+func reap(ctx context.Context, svc *app.Service, policy app.ExpiryPolicy) {
+	if policy.EmptyTTL <= 0 && policy.IdleTTL <= 0 {
+		return
+	}
+	t := time.NewTicker(reapInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			expired, err := svc.ExpireRooms(ctx, policy)
+			if err != nil {
+				slog.Error("reaper", "error", err)
+			}
+			if len(expired) > 0 {
+				slog.Info("reaper: removed rooms", "rooms", len(expired))
+			}
+		}
+	}
+}
+
+// presenceInterval is how often disconnected participants are removed.
+const presenceInterval = 10 * time.Second
+
+// dropDisconnected periodically removes participants whose event streams
+// have been closed for longer than grace, until ctx is cancelled.
+func dropDisconnected(ctx context.Context, svc *app.Service, grace time.Duration) {
+	if grace <= 0 {
+		return
+	}
+	t := time.NewTicker(presenceInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			n, err := svc.LeaveDisconnected(ctx, grace)
+			if err != nil {
+				slog.Error("presence", "error", err)
+			}
+			if n > 0 {
+				slog.Info("presence: removed disconnected participants", "participants", n)
+			}
+		}
+	}
 }
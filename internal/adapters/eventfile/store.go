@@ -0,0 +1,332 @@
+// Package eventfile provides a file-backed app.EventStore: per room, an
+// append-only JSON Lines file of event records and a snapshot file, all in
+// one directory. Saving a snapshot compacts the room's file down to the
+// records after it, so replay reads at most one snapshot interval.
+package eventfile
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/jaminalder/estimations/internal/app"
+	"github.com/jaminalder/estimations/internal/domain"
+)
+
+const (
+	streamExt   = ".events.jsonl"
+	snapshotExt = ".snapshot.json"
+)
+
+// Store keeps event streams in files under a directory. A record is written
+// as one line; a line torn by a crash mid-append is dropped (and truncated
+// away) the next time the stream is read. Each room's files are locked on
+// their own, so rooms do not wait for each other's fsyncs.
+type Store struct {
+	dir   string
+	mu    sync.Mutex // guards rooms
+	rooms map[domain.RoomID]*stream
+}
+
+// stream serializes access to one room's files.
+type stream struct {
+	mu    sync.Mutex
+	last  uint64 // Seq of the last record, once known
+	known bool   // whether last has been read
+	gone  bool   // deleted; lock the room's new stream instead
+}
+
+var _ app.EventStore = (*Store)(nil)
+
+// Open uses dir (creating it if needed) as the store's directory.
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("open event store: %w", err)
+	}
+	return &Store{dir: dir, rooms: make(map[domain.RoomID]*stream)}, nil
+}
+
+// lock returns the room's stream, locked.
+func (s *Store) lock(id domain.RoomID) *stream {
+	for {
+		s.mu.Lock()
+		st, ok := s.rooms[id]
+		if !ok {
+			st = &stream{}
+			s.rooms[id] = st
+		}
+		s.mu.Unlock()
+		st.mu.Lock()
+		if !st.gone {
+			return st
+		}
+		st.mu.Unlock()
+	}
+}
+
+func (s *Store) Append(ctx context.Context, id domain.RoomID, records []app.EventRecord) error {
+	path, err := s.path(id, streamExt)
+	if err != nil {
+		return err
+	}
+	st := s.lock(id)
+	defer st.mu.Unlock()
+	last, err := s.lastSeq(st, id, path)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	for i, rec := range records {
+		if rec.Seq != last+uint64(i)+1 {
+			return fmt.Errorf("%w: room %s: got seq %d after %d", app.ErrStreamConflict, id, rec.Seq, last+uint64(i))
+		}
+		line, err := json.Marshal(rec)
+		if err != nil {
+			return fmt.Errorf("encode event %d: %w", rec.Seq, err)
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("append events: %w", err)
+	}
+	_, err = f.Write(buf.Bytes())
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		st.known = false // rescan (and repair) before the next append
+		return fmt.Errorf("append events: %w", err)
+	}
+	st.last = last + uint64(len(records))
+	return nil
+}
+
+func (s *Store) Load(ctx context.Context, id domain.RoomID, after uint64) ([]app.EventRecord, error) {
+	path, err := s.path(id, streamExt)
+	if err != nil {
+		return nil, err
+	}
+	st := s.lock(id)
+	defer st.mu.Unlock()
+	var out []app.EventRecord
+	_, _, err = readStream(path, func(rec app.EventRecord, _ []byte) {
+		if rec.Seq > after {
+			out = append(out, rec)
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("load events of %s: %w", id, err)
+	}
+	return out, nil
+}
+
+func (s *Store) SaveSnapshot(ctx context.Context, id domain.RoomID, snap app.StateSnapshot) error {
+	path, err := s.path(id, snapshotExt)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return fmt.Errorf("encode snapshot: %w", err)
+	}
+	events, err := s.path(id, streamExt)
+	if err != nil {
+		return err
+	}
+	st := s.lock(id)
+	defer st.mu.Unlock()
+	if err := s.replace(path, data); err != nil {
+		return fmt.Errorf("save snapshot: %w", err)
+	}
+	// Only now that the snapshot is in place can the records it covers go.
+	if _, err := s.lastSeq(st, id, events); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	_, _, err = readStream(events, func(rec app.EventRecord, line []byte) {
+		if rec.Seq > snap.Seq {
+			buf.Write(line)
+		}
+	})
+	if err == nil {
+		err = s.replace(events, buf.Bytes())
+	}
+	if err != nil {
+		st.known = false
+		return fmt.Errorf("compact events of %s: %w", id, err)
+	}
+	return nil
+}
+
+// replace writes data to a temporary file and renames it over path, so a
+// crash leaves either the old or the new content in place.
+func (s *Store) replace(path string, data []byte) error {
+	tmp, err := os.CreateTemp(s.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+	return err
+}
+
+func (s *Store) LoadSnapshot(ctx context.Context, id domain.RoomID) (app.StateSnapshot, bool, error) {
+	path, err := s.path(id, snapshotExt)
+	if err != nil {
+		return app.StateSnapshot{}, false, err
+	}
+	st := s.lock(id)
+	defer st.mu.Unlock()
+	snap, ok, err := readSnapshot(path)
+	if err != nil {
+		return app.StateSnapshot{}, false, fmt.Errorf("load snapshot of %s: %w", id, err)
+	}
+	return snap, ok, nil
+}
+
+func (s *Store) Delete(ctx context.Context, id domain.RoomID) error {
+	if _, err := s.path(id, streamExt); err != nil {
+		return err
+	}
+	st := s.lock(id)
+	defer st.mu.Unlock()
+	s.mu.Lock()
+	delete(s.rooms, id)
+	st.gone = true
+	s.mu.Unlock()
+	for _, ext := range []string{streamExt, snapshotExt} {
+		path, err := s.path(id, ext)
+		if err != nil {
+			return err
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("delete %s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+func (s *Store) List(ctx context.Context) ([]domain.RoomID, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("list streams: %w", err)
+	}
+	var ids []domain.RoomID
+	for _, e := range entries {
+		if name, ok := strings.CutSuffix(e.Name(), streamExt); ok && !e.IsDir() {
+			ids = append(ids, domain.RoomID(name))
+		}
+	}
+	return ids, nil
+}
+
+// path returns the file of a room's stream or snapshot. Room IDs become file
+// names, so only letters, digits, '-' and '_' are accepted.
+func (s *Store) path(id domain.RoomID, ext string) (string, error) {
+	if id == "" || strings.IndexFunc(string(id), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_')
+	}) >= 0 {
+		return "", fmt.Errorf("event store: unsupported room id %q", id)
+	}
+	return filepath.Join(s.dir, string(id)+ext), nil
+}
+
+// lastSeq returns the Seq of the stream's last record, reading the file the
+// first time and truncating a torn final line away. A stream compacted down
+// to nothing continues from its snapshot.
+func (s *Store) lastSeq(st *stream, id domain.RoomID, path string) (uint64, error) {
+	if st.known {
+		return st.last, nil
+	}
+	var last uint64
+	end, torn, err := readStream(path, func(rec app.EventRecord, _ []byte) { last = rec.Seq })
+	if err != nil {
+		return 0, fmt.Errorf("read events of %s: %w", id, err)
+	}
+	if torn {
+		if err := os.Truncate(path, end); err != nil {
+			return 0, fmt.Errorf("repair events of %s: %w", id, err)
+		}
+	}
+	if last == 0 {
+		snapPath, err := s.path(id, snapshotExt)
+		if err != nil {
+			return 0, err
+		}
+		snap, _, err := readSnapshot(snapPath)
+		if err != nil {
+			return 0, fmt.Errorf("read snapshot of %s: %w", id, err)
+		}
+		last = snap.Seq
+	}
+	st.last, st.known = last, true
+	return last, nil
+}
+
+// readSnapshot decodes the snapshot file at path, which may not exist.
+func readSnapshot(path string) (app.StateSnapshot, bool, error) {
+	var snap app.StateSnapshot
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return snap, false, nil
+	} else if err != nil {
+		return snap, false, err
+	}
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return snap, false, err
+	}
+	return snap, true, nil
+}
+
+// readStream calls fn for every complete record of the file at path, which
+// may not exist, with the record's line. It returns the offset after the last
+// complete line and whether a torn (unterminated) line follows it.
+func readStream(path string, fn func(rec app.EventRecord, line []byte)) (end int64, torn bool, err error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, false, nil
+	} else if err != nil {
+		return 0, false, err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			return end, len(line) > 0, nil
+		} else if err != nil {
+			return end, false, err
+		}
+		var rec app.EventRecord
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return end, false, fmt.Errorf("record at offset %d: %w", end, err)
+		}
+		fn(rec, line)
+		end += int64(len(line))
+	}
+}
@@ -0,0 +1,152 @@
+package eventfile
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jaminalder/estimations/internal/adapters/memory"
+	"github.com/jaminalder/estimations/internal/adapters/repotest"
+	"github.com/jaminalder/estimations/internal/app"
+	"github.com/jaminalder/estimations/internal/domain"
+)
+
+func openTemp(t *testing.T, dir string) *Store {
+	t.Helper()
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	return s
+}
+
+func TestStore_Contract(t *testing.T) {
+	repotest.EventStore(t, func(t *testing.T) app.EventStore { return openTemp(t, t.TempDir()) })
+}
+
+func TestStore_SurvivesReopen(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	if err := openTemp(t, dir).Append(ctx, "r1", repotest.Records(0, 2)); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+
+	s := openTemp(t, dir)
+	if err := s.Append(ctx, "r1", repotest.Records(1, 1)); err == nil {
+		t.Fatalf("reopened store should know the stream's last seq")
+	}
+	if err := s.Append(ctx, "r1", repotest.Records(2, 1)); err != nil {
+		t.Fatalf("append after reopen: %v", err)
+	}
+	if got, err := s.Load(ctx, "r1", 0); err != nil || len(got) != 3 {
+		t.Fatalf("load after reopen: got %d records, %v", len(got), err)
+	}
+}
+
+func TestStore_DropsTornRecord(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	_ = openTemp(t, dir).Append(ctx, "r1", repotest.Records(0, 2))
+
+	// A crash in the middle of the third append leaves half a line behind.
+	f, err := os.OpenFile(filepath.Join(dir, "r1"+streamExt), os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatalf("open stream file: %v", err)
+	}
+	_, _ = f.WriteString(`{"seq":3,"at":"2025-09`)
+	_ = f.Close()
+
+	s := openTemp(t, dir)
+	if got, err := s.Load(ctx, "r1", 0); err != nil || len(got) != 2 {
+		t.Fatalf("load with torn tail: got %d records, %v", len(got), err)
+	}
+	if err := s.Append(ctx, "r1", repotest.Records(2, 1)); err != nil {
+		t.Fatalf("append after torn tail: %v", err)
+	}
+	if got, err := openTemp(t, dir).Load(ctx, "r1", 0); err != nil || len(got) != 3 || got[2].Seq != 3 {
+		t.Fatalf("torn line should be replaced: got %v, %v", got, err)
+	}
+}
+
+func TestStore_SnapshotCompactsStream(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	s := openTemp(t, dir)
+	_ = s.Append(ctx, "r1", repotest.Records(0, 5))
+	room := domain.NewRoom("r1")
+	if err := s.SaveSnapshot(ctx, "r1", app.StateSnapshot{Seq: 3, State: room.State()}); err != nil {
+		t.Fatalf("save snapshot: %v", err)
+	}
+	if got, err := openTemp(t, dir).Load(ctx, "r1", 0); err != nil || len(got) != 2 || got[0].Seq != 4 {
+		t.Fatalf("stream should keep only records after the snapshot: got %v, %v", got, err)
+	}
+
+	// A stream compacted to nothing continues after its snapshot.
+	if err := s.SaveSnapshot(ctx, "r1", app.StateSnapshot{Seq: 5, State: room.State()}); err != nil {
+		t.Fatalf("save snapshot: %v", err)
+	}
+	reopened := openTemp(t, dir)
+	if err := reopened.Append(ctx, "r1", repotest.Records(0, 1)); err == nil {
+		t.Fatalf("compacted stream should still know its last seq")
+	}
+	if err := reopened.Append(ctx, "r1", repotest.Records(5, 1)); err != nil {
+		t.Fatalf("append after compaction: %v", err)
+	}
+	if ids, err := reopened.List(ctx); err != nil || len(ids) != 1 {
+		t.Fatalf("compacted stream should still be listed: %v, %v", ids, err)
+	}
+}
+
+func TestStore_RejectsUnsafeRoomID(t *testing.T) {
+	s := openTemp(t, t.TempDir())
+	for _, id := range []domain.RoomID{"", "../r1", "a/b", "r1.events"} {
+		if err := s.Append(context.Background(), id, repotest.Records(0, 1)); err == nil {
+			t.Fatalf("room id %q should be rejected", id)
+		}
+	}
+}
+
+func TestService_ReplaysRoomsFromFiles(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	svc := &app.Service{Rooms: memory.NewRoomRepo(), Ids: seqIDs("r1"), Clock: fixedClock{}, Events: openTemp(t, dir)}
+	roomID, err := svc.CreateRoom(ctx, app.WithTitle("Planning"))
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	alice, _ := svc.Join(ctx, roomID, "Alice")
+	_ = svc.AddStory(ctx, roomID, alice, domain.Story{Title: "Login"})
+	_ = svc.Cast(ctx, roomID, alice, "5")
+	_ = svc.Reveal(ctx, roomID, alice)
+	_ = svc.NextStory(ctx, roomID, alice, "5")
+	want, _ := svc.Snapshot(ctx, roomID)
+
+	// A restarted server rebuilds the room from the files alone.
+	restarted := &app.Service{Rooms: memory.NewRoomRepo(), Ids: seqIDs("r2"), Events: openTemp(t, dir)}
+	if n, err := restarted.ReplayRooms(ctx); err != nil || n != 1 {
+		t.Fatalf("replay: %d rooms, %v", n, err)
+	}
+	got, err := restarted.Snapshot(ctx, roomID)
+	if err != nil {
+		t.Fatalf("snapshot after replay: %v", err)
+	}
+	if !reflect.DeepEqual(got.History, want.History) || !reflect.DeepEqual(got.Stories, want.Stories) ||
+		got.Title != want.Title || got.Round != want.Round || len(got.Participants) != 1 {
+		t.Fatalf("replayed room differs:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+// seqIDs hands out one room ID and numbered participant IDs.
+type seqIDs domain.RoomID
+
+func (s seqIDs) NewRoomID() domain.RoomID { return domain.RoomID(s) }
+func (s seqIDs) NewParticipantID() domain.ParticipantID {
+	return domain.ParticipantID(string(s) + "-p")
+}
+
+type fixedClock struct{}
+
+func (fixedClock) Now() time.Time { return time.Date(2025, 9, 5, 9, 0, 0, 0, time.UTC) }
@@ -6,6 +6,7 @@ import (
 
 	"github.com/go-chi/chi/v5"
 
+	"github.com/jaminalder/estimations/internal/app"
 	"github.com/jaminalder/estimations/internal/domain"
 )
 
@@ -26,7 +27,7 @@ func (h *Handler) Cast(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if err := h.svc.Cast(r.Context(), domain.RoomID(roomID), domain.ParticipantID(pid), card); err != nil {
-		http.Error(w, "cast failed", http.StatusBadRequest)
+		h.commandFailed(w, r, roomID, err)
 		return
 	}
 	http.Redirect(w, r, "/rooms/"+roomID, http.StatusSeeOther)
@@ -44,7 +45,7 @@ func (h *Handler) Clear(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if err := h.svc.Clear(r.Context(), domain.RoomID(roomID), domain.ParticipantID(pid)); err != nil {
-		http.Error(w, "clear failed", http.StatusBadRequest)
+		h.commandFailed(w, r, roomID, err)
 		return
 	}
 	http.Redirect(w, r, "/rooms/"+roomID, http.StatusSeeOther)
@@ -56,8 +57,13 @@ func (h *Handler) Reveal(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	roomID := chi.URLParam(r, "roomID")
-	if err := h.svc.Reveal(r.Context(), domain.RoomID(roomID)); err != nil {
-		http.Error(w, "reveal failed", http.StatusBadRequest)
+	pid := h.readPID(r)
+	if pid == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if err := h.svc.Reveal(r.Context(), domain.RoomID(roomID), domain.ParticipantID(pid)); err != nil {
+		h.commandFailed(w, r, roomID, err)
 		return
 	}
 	http.Redirect(w, r, "/rooms/"+roomID, http.StatusSeeOther)
@@ -69,17 +75,133 @@ func (h *Handler) Reset(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	roomID := chi.URLParam(r, "roomID")
-	if err := h.svc.Reset(r.Context(), domain.RoomID(roomID)); err != nil {
-		http.Error(w, "reset failed", http.StatusBadRequest)
+	pid := h.readPID(r)
+	if pid == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if err := h.svc.Reset(r.Context(), domain.RoomID(roomID), domain.ParticipantID(pid)); err != nil {
+		h.commandFailed(w, r, roomID, err)
+		return
+	}
+	http.Redirect(w, r, "/rooms/"+roomID, http.StatusSeeOther)
+}
+
+func (h *Handler) Label(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	roomID := chi.URLParam(r, "roomID")
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	pid := h.readPID(r)
+	if pid == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if err := h.svc.SetLabel(r.Context(), domain.RoomID(roomID), domain.ParticipantID(pid), r.FormValue("label")); err != nil {
+		h.commandFailed(w, r, roomID, err)
 		return
 	}
 	http.Redirect(w, r, "/rooms/"+roomID, http.StatusSeeOther)
 }
 
-func (h *Handler) readPID(r *http.Request) string {
-	c, err := r.Cookie("pid")
-	if err != nil || c == nil {
-		return ""
+// Facilitator hands the facilitator role to the participant named in the form.
+func (h *Handler) Facilitator(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	roomID := chi.URLParam(r, "roomID")
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	pid := h.readPID(r)
+	if pid == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	room, err := h.svc.Snapshot(r.Context(), domain.RoomID(roomID))
+	if err != nil {
+		h.commandFailed(w, r, roomID, err)
+		return
 	}
-	return strings.TrimSpace(c.Value)
+	to, err := namedTarget(room, domain.ParticipantID(pid), r.FormValue("name"))
+	if err != nil {
+		h.commandFailed(w, r, roomID, err)
+		return
+	}
+	if err := h.svc.TransferFacilitator(r.Context(), domain.RoomID(roomID), domain.ParticipantID(pid), to); err != nil {
+		h.commandFailed(w, r, roomID, err)
+		return
+	}
+	http.Redirect(w, r, "/rooms/"+roomID, http.StatusSeeOther)
+}
+
+// Release removes the participant named in the form on the facilitator's
+// behalf, freeing the name of someone who lost their session.
+func (h *Handler) Release(w http.ResponseWriter, r *http.Request) {
+	roomID := chi.URLParam(r, "roomID")
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	pid := h.readPID(r)
+	if pid == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	room, err := h.svc.Snapshot(r.Context(), domain.RoomID(roomID))
+	if err != nil {
+		h.commandFailed(w, r, roomID, err)
+		return
+	}
+	who, err := namedTarget(room, domain.ParticipantID(pid), r.FormValue("name"))
+	if err != nil {
+		h.commandFailed(w, r, roomID, err)
+		return
+	}
+	if err := h.svc.Release(r.Context(), domain.RoomID(roomID), domain.ParticipantID(pid), who); err != nil {
+		h.commandFailed(w, r, roomID, err)
+		return
+	}
+	http.Redirect(w, r, "/rooms/"+roomID, http.StatusSeeOther)
+}
+
+// namedTarget resolves the participant named in a facilitator-only command.
+// The caller's role is checked first, so only the facilitator learns whether
+// a name is in the room.
+func namedTarget(room app.RoomSnapshot, by domain.ParticipantID, name string) (domain.ParticipantID, error) {
+	if room.Facilitator != by {
+		return "", domain.ErrNotFacilitator
+	}
+	p, ok := room.ParticipantByName(name)
+	if !ok {
+		return "", domain.ErrNotParticipant
+	}
+	return p.ID, nil
+}
+
+// Lock locks the room against newcomers (form value locked=true) or unlocks it.
+func (h *Handler) Lock(w http.ResponseWriter, r *http.Request) {
+	roomID := chi.URLParam(r, "roomID")
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	pid := h.readPID(r)
+	if pid == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	locked := r.FormValue("locked") == "true"
+	if err := h.svc.LockRoom(r.Context(), domain.RoomID(roomID), domain.ParticipantID(pid), locked); err != nil {
+		h.commandFailed(w, r, roomID, err)
+		return
+	}
+	http.Redirect(w, r, "/rooms/"+roomID, http.StatusSeeOther)
 }
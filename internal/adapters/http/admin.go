@@ -0,0 +1,91 @@
+package httpadapter
+
+import (
+	"cmp"
+	"crypto/subtle"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/jaminalder/estimations/internal/adapters/sse"
+	"github.com/jaminalder/estimations/internal/app"
+	"github.com/jaminalder/estimations/internal/domain"
+)
+
+// The admin API under /api/v1/admin lets operators inspect and close rooms.
+// It is mounted only with WithAdminToken, and every request must carry that
+// token as "Authorization: Bearer <token>".
+
+type apiAdminRoom struct {
+	ID          string     `json:"id"`
+	Voters      int        `json:"voters"`
+	Observers   int        `json:"observers"`
+	Round       int        `json:"round"`
+	State       string     `json:"state"` // "voting" or "revealed"
+	LastActive  *time.Time `json:"last_active,omitempty"`
+	Subscribers int        `json:"subscribers"` // open SSE and WebSocket streams
+}
+
+// subscriberStats reports live-update subscribers (implemented by sse.Hub).
+type subscriberStats interface {
+	Stats() sse.Stats
+}
+
+// requireToken rejects requests without the bearer token with 401.
+func requireToken(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if subtle.ConstantTimeCompare([]byte(bearerToken(r)), []byte(token)) != 1 {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				writeAPIError(w, http.StatusUnauthorized, "unauthorized", "A valid admin token is required.")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// AdminRooms handles GET /api/v1/admin/rooms: every room, ordered by ID.
+func (h *Handler) AdminRooms(w http.ResponseWriter, r *http.Request) {
+	rooms, err := h.svc.ListRooms(r.Context())
+	if err != nil {
+		writeCommandError(w, err)
+		return
+	}
+	var subs map[domain.RoomID]int
+	if st, ok := h.events.(subscriberStats); ok {
+		subs = st.Stats().Subscribers
+	}
+	slices.SortFunc(rooms, func(a, b app.RoomSummary) int { return cmp.Compare(a.ID, b.ID) })
+	out := make([]apiAdminRoom, 0, len(rooms))
+	for _, rm := range rooms {
+		room := apiAdminRoom{
+			ID:          string(rm.ID),
+			Voters:      rm.Voters,
+			Observers:   rm.Observers,
+			Round:       rm.Round,
+			State:       "voting",
+			Subscribers: subs[rm.ID],
+		}
+		if rm.Revealed {
+			room.State = "revealed"
+		}
+		if !rm.LastActive.IsZero() {
+			room.LastActive = &rm.LastActive
+		}
+		out = append(out, room)
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// AdminCloseRoom handles DELETE /api/v1/admin/rooms/{roomID}: the room is
+// removed and its clients are told it expired.
+func (h *Handler) AdminCloseRoom(w http.ResponseWriter, r *http.Request) {
+	if err := h.svc.CloseRoom(r.Context(), domain.RoomID(chi.URLParam(r, "roomID"))); err != nil {
+		writeCommandError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,85 @@
+package httpadapter
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jaminalder/estimations/internal/adapters/idgen"
+	"github.com/jaminalder/estimations/internal/adapters/memory"
+	"github.com/jaminalder/estimations/internal/adapters/sse"
+	"github.com/jaminalder/estimations/internal/app"
+)
+
+const testAdminToken = "0123456789abcdef"
+
+func TestAdmin_ListAndCloseRooms(t *testing.T) {
+	r, err := NewRenderer()
+	if err != nil {
+		t.Fatalf("renderer: %v", err)
+	}
+	hub := sse.NewHub(4)
+	svc := &app.Service{Rooms: memory.NewRoomRepo(), Ids: idgen.NewRandom(10, 8), Bus: hub}
+	srv := NewServer(svc, r, WithEvents(hub), WithAdminToken(testAdminToken))
+	ctx := context.Background()
+	roomID, _ := svc.CreateRoom(ctx)
+	pid, _ := svc.Join(ctx, roomID, "alice")
+	_ = svc.Cast(ctx, roomID, pid, "5")
+	_ = svc.Reveal(ctx, roomID, pid)
+	events, unsubscribe := hub.Subscribe(roomID, 0)
+	defer unsubscribe()
+
+	admin := func(method, path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for _, token := range []string{"", string(pid), testAdminToken + "x"} {
+		if rec := admin("GET", "/api/v1/admin/rooms", token); rec.Code != http.StatusUnauthorized {
+			t.Fatalf("token %q: got %d want 401", token, rec.Code)
+		}
+	}
+
+	rec := admin("GET", "/api/v1/admin/rooms", testAdminToken)
+	var rooms []apiAdminRoom
+	if err := json.Unmarshal(rec.Body.Bytes(), &rooms); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("list: %d %v: %s", rec.Code, err, rec.Body.String())
+	}
+	if len(rooms) != 1 {
+		t.Fatalf("want one room, got %+v", rooms)
+	}
+	got := rooms[0]
+	if got.ID != string(roomID) || got.Voters != 1 || got.Observers != 0 || got.State != "revealed" || got.Subscribers != 1 || got.LastActive == nil {
+		t.Fatalf("unexpected room: %+v", got)
+	}
+
+	if rec := admin("DELETE", "/api/v1/admin/rooms/"+string(roomID), testAdminToken); rec.Code != http.StatusNoContent {
+		t.Fatalf("close: got %d: %s", rec.Code, rec.Body.String())
+	}
+	if msg := <-events; msg.Event != "RoomExpired" {
+		t.Fatalf("subscriber got %q, want RoomExpired", msg.Event)
+	}
+	if _, open := <-events; open {
+		t.Fatal("closing a room must end its streams")
+	}
+	if rec := admin("DELETE", "/api/v1/admin/rooms/"+string(roomID), testAdminToken); rec.Code != http.StatusNotFound {
+		t.Fatalf("close again: got %d want 404", rec.Code)
+	}
+}
+
+func TestAdmin_DisabledWithoutToken(t *testing.T) {
+	srv := newTestServer(t, io.Discard)
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest("GET", "/api/v1/admin/rooms", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("got %d want 404", rec.Code)
+	}
+}
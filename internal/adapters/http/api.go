@@ -0,0 +1,537 @@
+package httpadapter
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/jaminalder/estimations/internal/app"
+	"github.com/jaminalder/estimations/internal/domain"
+)
+
+// The JSON API under /api/v1 mirrors the room use-cases for scripts and bots:
+// everything the room page can do, from voting to running the story backlog
+// and handing over the facilitator role. Participants authenticate with the
+// token returned by join (or rejoin), sent as "Authorization: Bearer
+// <token>"; like the pid cookie, the token is signed for the room (see
+// sessionKeys) and never exposed by any other endpoint. Commands answer 204
+// No Content; errors use apiError bodies.
+
+// maxAPIBody bounds JSON request bodies.
+const maxAPIBody = 1 << 16
+
+// apiError is the body of every non-2xx API response.
+type apiError struct {
+	Error apiErrorBody `json:"error"`
+}
+
+type apiErrorBody struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type apiCreateRoomRequest struct {
+	Title    string   `json:"title"`
+	Deck     string   `json:"deck"`
+	Cards    []string `json:"cards"` // only with deck "custom"
+	Passcode string   `json:"passcode"`
+}
+
+type apiJoinRequest struct {
+	Name         string `json:"name"`
+	Role         string `json:"role"` // "voter" (default) or "observer"
+	Passcode     string `json:"passcode"`
+	CreatorToken string `json:"creator_token"` // from create: join as facilitator
+}
+
+type apiJoinResponse struct {
+	Token        string `json:"token"`
+	RoomID       string `json:"room_id"`
+	Name         string `json:"name"`
+	Role         string `json:"role"`
+	RecoveryCode string `json:"recovery_code"` // for POST rejoin, e.g. from another client
+}
+
+type apiCastRequest struct {
+	Card string `json:"card"`
+}
+
+type apiRejoinRequest struct {
+	Code string `json:"code"` // recovery code
+}
+
+type apiLabelRequest struct {
+	Label string `json:"label"`
+}
+
+// apiNameRequest names the participant a facilitator command acts on.
+type apiNameRequest struct {
+	Name string `json:"name"`
+}
+
+type apiLockRequest struct {
+	Locked bool `json:"locked"`
+}
+
+type apiStoryRequest struct {
+	Title string `json:"title"`
+	Key   string `json:"key"`
+	Link  string `json:"link"`
+}
+
+type apiMoveStoryRequest struct {
+	To int `json:"to"`
+}
+
+type apiNextStoryRequest struct {
+	Estimate string `json:"estimate"`
+}
+
+type apiRoom struct {
+	ID           string           `json:"id"`
+	Title        string           `json:"title,omitempty"`
+	Deck         apiDeck          `json:"deck"`
+	Round        int              `json:"round"`
+	Label        string           `json:"label,omitempty"`
+	Revealed     bool             `json:"revealed"`
+	Locked       bool             `json:"locked"`
+	HasPasscode  bool             `json:"has_passcode"`
+	Participants []apiParticipant `json:"participants"`
+	Stats        *apiStats        `json:"stats,omitempty"`
+	Stories      []apiStory       `json:"stories"`
+	History      []apiRound       `json:"history"`
+	CreatorToken string           `json:"creator_token,omitempty"` // only in the create response
+}
+
+type apiDeck struct {
+	Name  string   `json:"name"`
+	Cards []string `json:"cards"`
+}
+
+type apiParticipant struct {
+	Name        string `json:"name"`
+	Role        string `json:"role"`
+	Facilitator bool   `json:"facilitator,omitempty"`
+	HasVoted    bool   `json:"has_voted"`
+	Card        string `json:"card,omitempty"` // only once revealed
+	You         bool   `json:"you,omitempty"`
+}
+
+type apiStats struct {
+	Mean      float64        `json:"mean"`
+	Median    float64        `json:"median"`
+	Min       float64        `json:"min"`
+	Max       float64        `json:"max"`
+	StdDev    float64        `json:"stddev"`
+	Mode      []string       `json:"mode"`
+	Nearest   string         `json:"nearest,omitempty"`
+	Consensus bool           `json:"consensus"`
+	Specials  map[string]int `json:"specials,omitempty"`
+}
+
+type apiStory struct {
+	Title    string `json:"title"`
+	Key      string `json:"key,omitempty"`
+	Link     string `json:"link,omitempty"`
+	Estimate string `json:"estimate,omitempty"`
+	Current  bool   `json:"current,omitempty"`
+}
+
+type apiRound struct {
+	Index      int       `json:"index"`
+	Label      string    `json:"label,omitempty"`
+	Estimate   string    `json:"estimate,omitempty"`
+	Votes      []apiVote `json:"votes"`
+	RevealedAt time.Time `json:"revealed_at"`
+	ResetAt    time.Time `json:"reset_at"`
+}
+
+type apiVote struct {
+	Name string `json:"name"`
+	Card string `json:"card"`
+}
+
+// APICreateRoom handles POST /api/v1/rooms.
+func (h *Handler) APICreateRoom(w http.ResponseWriter, r *http.Request) {
+	var req apiCreateRoomRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	deck, err := deckByName(req.Deck, req.Cards)
+	if err != nil {
+		writeAPIError(w, http.StatusUnprocessableEntity, "invalid_deck", err.Error())
+		return
+	}
+	id, err := h.svc.CreateRoom(r.Context(), app.WithDeck(deck), app.WithTitle(req.Title), app.WithPasscode(req.Passcode))
+	if err != nil {
+		writeCommandError(w, err)
+		return
+	}
+	room, err := h.svc.Snapshot(r.Context(), id)
+	if err != nil {
+		writeCommandError(w, err)
+		return
+	}
+	out := newAPIRoom(room, "")
+	out.CreatorToken = h.sessions.signCreator(id)
+	w.Header().Set("Location", h.absURL("/api/v1/rooms/"+string(id)))
+	writeJSON(w, http.StatusCreated, out)
+}
+
+// APIRoom handles GET /api/v1/rooms/{roomID}. A token marks the caller in
+// the participant list; it is only required for rooms with a passcode.
+func (h *Handler) APIRoom(w http.ResponseWriter, r *http.Request) {
+	room, err := h.svc.Snapshot(r.Context(), domain.RoomID(chi.URLParam(r, "roomID")))
+	if err != nil {
+		writeCommandError(w, err)
+		return
+	}
+	me := h.bearerPID(r, room.ID)
+	if hiddenFrom(room, me) {
+		writeAPIError(w, http.StatusUnauthorized, "invalid_token", "this room has a passcode; only its participants can see it")
+		return
+	}
+	writeJSON(w, http.StatusOK, newAPIRoom(room, me))
+}
+
+// APIJoin handles POST /api/v1/rooms/{roomID}/participants.
+func (h *Handler) APIJoin(w http.ResponseWriter, r *http.Request) {
+	roomID := domain.RoomID(chi.URLParam(r, "roomID"))
+	var req apiJoinRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	kind := domain.Voter
+	switch req.Role {
+	case "", domain.Voter.String():
+	case domain.Observer.String():
+		kind = domain.Observer
+	default:
+		writeAPIError(w, http.StatusUnprocessableEntity, "invalid_role", `role must be "voter" or "observer"`)
+		return
+	}
+	opts := []app.JoinOption{app.UsingPasscode(req.Passcode)}
+	if kind == domain.Observer {
+		opts = append(opts, app.AsObserver())
+	}
+	if req.CreatorToken != "" {
+		if !h.sessions.verifyCreator(roomID, req.CreatorToken) {
+			writeAPIError(w, http.StatusUnauthorized, "invalid_token", "the creator token does not belong to this room")
+			return
+		}
+		opts = append(opts, app.AsCreator())
+	}
+	pid, err := h.svc.Join(r.Context(), roomID, req.Name, opts...)
+	if err != nil {
+		writeCommandError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, apiJoinResponse{
+		Token:        h.sessions.sign(roomID, pid),
+		RoomID:       string(roomID),
+		Name:         strings.TrimSpace(req.Name),
+		Role:         kind.String(),
+		RecoveryCode: app.RecoveryCode(pid),
+	})
+}
+
+// APILeave handles DELETE /api/v1/rooms/{roomID}/participants/me.
+func (h *Handler) APILeave(w http.ResponseWriter, r *http.Request) {
+	h.apiCommand(w, r, func(roomID domain.RoomID, pid domain.ParticipantID) error {
+		return h.svc.Leave(r.Context(), roomID, pid)
+	})
+}
+
+// APICast handles PUT /api/v1/rooms/{roomID}/vote.
+func (h *Handler) APICast(w http.ResponseWriter, r *http.Request) {
+	var req apiCastRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	h.apiCommand(w, r, func(roomID domain.RoomID, pid domain.ParticipantID) error {
+		return h.svc.Cast(r.Context(), roomID, pid, strings.TrimSpace(req.Card))
+	})
+}
+
+// APIClear handles DELETE /api/v1/rooms/{roomID}/vote.
+func (h *Handler) APIClear(w http.ResponseWriter, r *http.Request) {
+	h.apiCommand(w, r, func(roomID domain.RoomID, pid domain.ParticipantID) error {
+		return h.svc.Clear(r.Context(), roomID, pid)
+	})
+}
+
+// APIReveal handles POST /api/v1/rooms/{roomID}/reveal.
+func (h *Handler) APIReveal(w http.ResponseWriter, r *http.Request) {
+	h.apiCommand(w, r, func(roomID domain.RoomID, pid domain.ParticipantID) error {
+		return h.svc.Reveal(r.Context(), roomID, pid)
+	})
+}
+
+// APIReset handles POST /api/v1/rooms/{roomID}/reset.
+func (h *Handler) APIReset(w http.ResponseWriter, r *http.Request) {
+	h.apiCommand(w, r, func(roomID domain.RoomID, pid domain.ParticipantID) error {
+		return h.svc.Reset(r.Context(), roomID, pid)
+	})
+}
+
+// APIRejoin handles POST /api/v1/rooms/{roomID}/rejoin: the caller takes over
+// the participant whose recovery code it sends and gets a fresh token.
+func (h *Handler) APIRejoin(w http.ResponseWriter, r *http.Request) {
+	roomID := domain.RoomID(chi.URLParam(r, "roomID"))
+	var req apiRejoinRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	pid, err := h.svc.Rejoin(r.Context(), roomID, strings.TrimSpace(req.Code))
+	if err != nil {
+		writeCommandError(w, err)
+		return
+	}
+	room, err := h.svc.Snapshot(r.Context(), roomID)
+	if err != nil {
+		writeCommandError(w, err)
+		return
+	}
+	out := apiJoinResponse{Token: h.sessions.sign(roomID, pid), RoomID: string(roomID), RecoveryCode: app.RecoveryCode(pid)}
+	for _, p := range room.Participants {
+		if p.ID == pid {
+			out.Name, out.Role = p.Name, p.Kind.String()
+		}
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// APILabel handles PUT /api/v1/rooms/{roomID}/label.
+func (h *Handler) APILabel(w http.ResponseWriter, r *http.Request) {
+	var req apiLabelRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	h.apiCommand(w, r, func(roomID domain.RoomID, pid domain.ParticipantID) error {
+		return h.svc.SetLabel(r.Context(), roomID, pid, req.Label)
+	})
+}
+
+// APIFacilitator handles PUT /api/v1/rooms/{roomID}/facilitator, handing the
+// role to the participant named in the body.
+func (h *Handler) APIFacilitator(w http.ResponseWriter, r *http.Request) {
+	var req apiNameRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	h.apiCommand(w, r, func(roomID domain.RoomID, pid domain.ParticipantID) error {
+		to, err := h.apiTarget(r, roomID, pid, req.Name)
+		if err != nil {
+			return err
+		}
+		return h.svc.TransferFacilitator(r.Context(), roomID, pid, to)
+	})
+}
+
+// APIRelease handles POST /api/v1/rooms/{roomID}/release, removing the
+// participant named in the body.
+func (h *Handler) APIRelease(w http.ResponseWriter, r *http.Request) {
+	var req apiNameRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	h.apiCommand(w, r, func(roomID domain.RoomID, pid domain.ParticipantID) error {
+		who, err := h.apiTarget(r, roomID, pid, req.Name)
+		if err != nil {
+			return err
+		}
+		return h.svc.Release(r.Context(), roomID, pid, who)
+	})
+}
+
+// apiTarget resolves the participant a facilitator command names.
+func (h *Handler) apiTarget(r *http.Request, roomID domain.RoomID, by domain.ParticipantID, name string) (domain.ParticipantID, error) {
+	room, err := h.svc.Snapshot(r.Context(), roomID)
+	if err != nil {
+		return "", err
+	}
+	return namedTarget(room, by, name)
+}
+
+// APILock handles PUT /api/v1/rooms/{roomID}/lock.
+func (h *Handler) APILock(w http.ResponseWriter, r *http.Request) {
+	var req apiLockRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	h.apiCommand(w, r, func(roomID domain.RoomID, pid domain.ParticipantID) error {
+		return h.svc.LockRoom(r.Context(), roomID, pid, req.Locked)
+	})
+}
+
+// APIAddStory handles POST /api/v1/rooms/{roomID}/stories.
+func (h *Handler) APIAddStory(w http.ResponseWriter, r *http.Request) {
+	var req apiStoryRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	h.apiCommand(w, r, func(roomID domain.RoomID, pid domain.ParticipantID) error {
+		return h.svc.AddStory(r.Context(), roomID, pid, domain.Story{Title: req.Title, Key: req.Key, Link: req.Link})
+	})
+}
+
+// APIRemoveStory handles DELETE /api/v1/rooms/{roomID}/stories/{index}.
+func (h *Handler) APIRemoveStory(w http.ResponseWriter, r *http.Request) {
+	index, ok := apiIndex(w, r)
+	if !ok {
+		return
+	}
+	h.apiCommand(w, r, func(roomID domain.RoomID, pid domain.ParticipantID) error {
+		return h.svc.RemoveStory(r.Context(), roomID, pid, index)
+	})
+}
+
+// APIMoveStory handles POST /api/v1/rooms/{roomID}/stories/{index}/move.
+func (h *Handler) APIMoveStory(w http.ResponseWriter, r *http.Request) {
+	index, ok := apiIndex(w, r)
+	if !ok {
+		return
+	}
+	var req apiMoveStoryRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	h.apiCommand(w, r, func(roomID domain.RoomID, pid domain.ParticipantID) error {
+		return h.svc.MoveStory(r.Context(), roomID, pid, index, req.To)
+	})
+}
+
+// APINextStory handles POST /api/v1/rooms/{roomID}/next.
+func (h *Handler) APINextStory(w http.ResponseWriter, r *http.Request) {
+	var req apiNextStoryRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	h.apiCommand(w, r, func(roomID domain.RoomID, pid domain.ParticipantID) error {
+		return h.svc.NextStory(r.Context(), roomID, pid, req.Estimate)
+	})
+}
+
+// apiIndex parses the {index} of a story route.
+func apiIndex(w http.ResponseWriter, r *http.Request) (int, bool) {
+	index, err := strconv.Atoi(chi.URLParam(r, "index"))
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_index", "the story index must be a number")
+		return 0, false
+	}
+	return index, true
+}
+
+// apiCommand authenticates the caller as a participant of the room, runs the
+// command and answers 204 or a mapped error.
+func (h *Handler) apiCommand(w http.ResponseWriter, r *http.Request, run func(domain.RoomID, domain.ParticipantID) error) {
+	roomID := domain.RoomID(chi.URLParam(r, "roomID"))
+	token := bearerToken(r)
+	if token == "" {
+		writeAPIError(w, http.StatusUnauthorized, "missing_token", "a participant token is required")
+		return
+	}
+	room, err := h.svc.Snapshot(r.Context(), roomID)
+	if err != nil {
+		writeCommandError(w, err)
+		return
+	}
+	pid := h.bearerPID(r, roomID)
+	if pid == "" || !room.HasParticipant(pid) {
+		writeAPIError(w, http.StatusUnauthorized, "invalid_token", "the token does not belong to a participant of this room")
+		return
+	}
+	if err := run(roomID, pid); err != nil {
+		writeCommandError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// bearerToken returns the token of an "Authorization: Bearer" header.
+func bearerToken(r *http.Request) string {
+	const prefix = "Bearer "
+	auth := r.Header.Get("Authorization")
+	if len(auth) < len(prefix) || !strings.EqualFold(auth[:len(prefix)], prefix) {
+		return ""
+	}
+	return strings.TrimSpace(auth[len(prefix):])
+}
+
+func decodeJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAPIBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_json", err.Error())
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeAPIError(w http.ResponseWriter, status int, code, msg string) {
+	writeJSON(w, status, apiError{Error: apiErrorBody{Code: code, Message: msg}})
+}
+
+// writeCommandError maps a use-case error to a status and error code.
+func writeCommandError(w http.ResponseWriter, err error) {
+	status, code, msg := describeError(err)
+	writeAPIError(w, status, code, msg)
+}
+
+func newAPIRoom(room app.RoomSnapshot, me domain.ParticipantID) apiRoom {
+	out := apiRoom{
+		ID:           string(room.ID),
+		Title:        room.Title,
+		Deck:         apiDeck{Name: room.DeckName, Cards: room.Deck},
+		Round:        room.Round,
+		Label:        room.Label,
+		Revealed:     room.Revealed,
+		Locked:       room.Locked,
+		HasPasscode:  room.HasPasscode,
+		Participants: make([]apiParticipant, 0, len(room.Participants)),
+		Stories:      make([]apiStory, 0, len(room.Stories)),
+		History:      make([]apiRound, 0, len(room.History)),
+	}
+	for _, p := range room.Participants {
+		card, voted := room.Votes[p.ID]
+		ap := apiParticipant{
+			Name:        p.Name,
+			Role:        p.Kind.String(),
+			Facilitator: p.ID == room.Facilitator,
+			HasVoted:    voted,
+			You:         me != "" && p.ID == me,
+		}
+		if room.Revealed {
+			ap.Card = card
+		}
+		out.Participants = append(out.Participants, ap)
+	}
+	if room.Revealed {
+		st := room.Stats
+		out.Stats = &apiStats{
+			Mean: st.Mean, Median: st.Median, Min: st.Min, Max: st.Max, StdDev: st.StdDev,
+			Mode: append([]string{}, st.Mode...), Nearest: st.Nearest, Consensus: st.Consensus, Specials: st.Specials,
+		}
+	}
+	for i, s := range room.Stories {
+		out.Stories = append(out.Stories, apiStory{Title: s.Title, Key: s.Key, Link: s.Link, Estimate: s.Estimate, Current: i == room.Current})
+	}
+	for _, h := range room.History {
+		votes := make([]apiVote, 0, len(h.Votes))
+		for _, v := range h.Votes {
+			votes = append(votes, apiVote{Name: v.Name, Card: v.Card})
+		}
+		out.History = append(out.History, apiRound{Index: h.Index, Label: h.Label, Estimate: h.Estimate, Votes: votes, RevealedAt: h.RevealedAt, ResetAt: h.ResetAt})
+	}
+	return out
+}
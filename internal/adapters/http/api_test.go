@@ -0,0 +1,268 @@
+package httpadapter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jaminalder/estimations/internal/app"
+)
+
+// apiClient issues JSON requests against the test server.
+type apiClient struct {
+	t   *testing.T
+	srv http.Handler
+}
+
+func (c apiClient) do(method, url, token, body string) *httptest.ResponseRecorder {
+	c.t.Helper()
+	var rd io.Reader
+	if body != "" {
+		rd = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, url, rd)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	c.srv.ServeHTTP(rec, req)
+	return rec
+}
+
+func decodeBody[T any](t *testing.T, rec *httptest.ResponseRecorder) T {
+	t.Helper()
+	var v T
+	if err := json.Unmarshal(rec.Body.Bytes(), &v); err != nil {
+		t.Fatalf("decode %q: %v", rec.Body.String(), err)
+	}
+	return v
+}
+
+func TestAPI_RoomLifecycle(t *testing.T) {
+	var logs strings.Builder
+	c := apiClient{t: t, srv: newTestServer(t, &logs)}
+
+	rec := c.do("POST", "/api/v1/rooms", "", `{"title":"Sprint","deck":"tshirt"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: got %d want 201: %s", rec.Code, rec.Body)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Fatalf("content type: %q", ct)
+	}
+	created := decodeBody[apiRoom](t, rec)
+	if created.ID == "" || created.Title != "Sprint" || created.Deck.Name != "tshirt" || rec.Header().Get("Location") != "/api/v1/rooms/"+created.ID {
+		t.Fatalf("unexpected created room: %+v", created)
+	}
+	base := "/api/v1/rooms/" + created.ID
+
+	join := func(body string) apiJoinResponse {
+		rec := c.do("POST", base+"/participants", "", body)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("join %s: got %d want 201: %s", body, rec.Code, rec.Body)
+		}
+		return decodeBody[apiJoinResponse](t, rec)
+	}
+	alice := join(`{"name":"Alice"}`)
+	bob := join(`{"name":"Bob","role":"voter"}`)
+	if alice.Token == "" || alice.Role != "voter" || alice.RoomID != created.ID {
+		t.Fatalf("unexpected join response: %+v", alice)
+	}
+
+	for _, step := range []struct {
+		method, path, token, body string
+	}{
+		{"PUT", "/vote", alice.Token, `{"card":"M"}`},
+		{"PUT", "/vote", bob.Token, `{"card":"XL"}`},
+		{"DELETE", "/vote", bob.Token, ""},
+		{"PUT", "/vote", bob.Token, `{"card":"L"}`},
+	} {
+		if rec := c.do(step.method, base+step.path, step.token, step.body); rec.Code != http.StatusNoContent {
+			t.Fatalf("%s %s: got %d want 204: %s", step.method, step.path, rec.Code, rec.Body)
+		}
+	}
+
+	// Votes stay hidden until revealed.
+	room := decodeBody[apiRoom](t, c.do("GET", base, bob.Token, ""))
+	if len(room.Participants) != 2 || !room.Participants[0].HasVoted || room.Participants[0].Card != "" || room.Stats != nil {
+		t.Fatalf("votes must be hidden before reveal: %+v", room)
+	}
+	if !room.Participants[0].Facilitator || room.Participants[0].You || !room.Participants[1].You {
+		t.Fatalf("unexpected participant flags: %+v", room.Participants)
+	}
+	if strings.Contains(c.do("GET", base, "", "").Body.String(), alice.Token) {
+		t.Fatalf("snapshot must not leak participant tokens")
+	}
+
+	if rec := c.do("POST", base+"/reveal", alice.Token, ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("reveal: got %d want 204: %s", rec.Code, rec.Body)
+	}
+	room = decodeBody[apiRoom](t, c.do("GET", base, "", ""))
+	if !room.Revealed || room.Participants[0].Card != "M" || room.Participants[1].Card != "L" || room.Stats == nil {
+		t.Fatalf("expected revealed votes and stats: %+v", room)
+	}
+
+	if rec := c.do("POST", base+"/reset", alice.Token, ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("reset: got %d want 204", rec.Code)
+	}
+	room = decodeBody[apiRoom](t, c.do("GET", base, "", ""))
+	if room.Revealed || room.Round != 1 || len(room.History) != 1 || room.History[0].Votes[0] != (apiVote{Name: "Alice", Card: "M"}) {
+		t.Fatalf("expected a new round with history: %+v", room)
+	}
+
+	if rec := c.do("DELETE", base+"/participants/me", bob.Token, ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("leave: got %d want 204", rec.Code)
+	}
+	if rec := c.do("PUT", base+"/vote", bob.Token, `{"card":"S"}`); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("cast after leave: got %d want 401", rec.Code)
+	}
+}
+
+func TestAPI_RunsAFullSession(t *testing.T) {
+	c := apiClient{t: t, srv: newTestServer(t, io.Discard)}
+	created := decodeBody[apiRoom](t, c.do("POST", "/api/v1/rooms", "", `{}`))
+	base := "/api/v1/rooms/" + created.ID
+	alice := decodeBody[apiJoinResponse](t, c.do("POST", base+"/participants", "", `{"name":"Alice","creator_token":"`+created.CreatorToken+`"}`))
+	bob := decodeBody[apiJoinResponse](t, c.do("POST", base+"/participants", "", `{"name":"Bob"}`))
+	expect := func(rec *httptest.ResponseRecorder, status int, what string) {
+		t.Helper()
+		if rec.Code != status {
+			t.Fatalf("%s: got %d want %d: %s", what, rec.Code, status, rec.Body)
+		}
+	}
+
+	expect(c.do("POST", base+"/stories", alice.Token, `{"title":"Login","key":"PROJ-1"}`), http.StatusNoContent, "add story")
+	expect(c.do("POST", base+"/stories", alice.Token, `{"title":"Logout"}`), http.StatusNoContent, "add story")
+	expect(c.do("POST", base+"/stories", alice.Token, `{"title":"Signup"}`), http.StatusNoContent, "add story")
+	expect(c.do("POST", base+"/stories/2/move", alice.Token, `{"to":0}`), http.StatusNoContent, "move story")
+	expect(c.do("DELETE", base+"/stories/2", alice.Token, ""), http.StatusNoContent, "remove story")
+	expect(c.do("DELETE", base+"/stories/x", alice.Token, ""), http.StatusBadRequest, "remove story by a bad index")
+	expect(c.do("POST", base+"/stories", bob.Token, `{"title":"Sneaky"}`), http.StatusForbidden, "add story by non-facilitator")
+	expect(c.do("PUT", base+"/label", alice.Token, `{"label":"Signup (split)"}`), http.StatusNoContent, "label")
+	expect(c.do("PUT", base+"/vote", bob.Token, `{"card":"5"}`), http.StatusNoContent, "cast")
+	expect(c.do("POST", base+"/reveal", alice.Token, ""), http.StatusNoContent, "reveal")
+	expect(c.do("POST", base+"/next", alice.Token, `{"estimate":"5"}`), http.StatusNoContent, "next story")
+	expect(c.do("PUT", base+"/lock", alice.Token, `{"locked":true}`), http.StatusNoContent, "lock")
+	expect(c.do("POST", base+"/participants", "", `{"name":"Carol"}`), http.StatusConflict, "join a locked room")
+
+	room := decodeBody[apiRoom](t, c.do("GET", base, alice.Token, ""))
+	if !room.Locked || len(room.Stories) != 2 || room.Stories[0].Title != "Signup" || room.Stories[0].Estimate != "5" ||
+		!room.Stories[1].Current || len(room.History) != 1 || room.History[0].Label != "Signup (split)" {
+		t.Fatalf("unexpected room after the session: %+v", room)
+	}
+
+	// Facilitator commands that name someone check the caller's role first.
+	for _, name := range []string{"Alice", "Nobody"} {
+		rec := c.do("PUT", base+"/facilitator", bob.Token, `{"name":"`+name+`"}`)
+		if rec.Code != http.StatusForbidden || decodeBody[apiError](t, rec).Error.Code != "not_facilitator" {
+			t.Fatalf("transfer to %s by non-facilitator: got %d %s", name, rec.Code, rec.Body)
+		}
+	}
+	expect(c.do("PUT", base+"/facilitator", alice.Token, `{"name":"Nobody"}`), http.StatusForbidden, "transfer to a stranger")
+	expect(c.do("PUT", base+"/facilitator", alice.Token, `{"name":"bob"}`), http.StatusNoContent, "transfer")
+	expect(c.do("POST", base+"/release", bob.Token, `{"name":"Alice"}`), http.StatusNoContent, "release")
+	expect(c.do("POST", base+"/reset", alice.Token, ""), http.StatusUnauthorized, "command after release")
+
+	// Bob moves to another client with his recovery code.
+	expect(c.do("POST", base+"/rejoin", "", `{"code":"0000-0000"}`), http.StatusForbidden, "rejoin with a wrong code")
+	rec := c.do("POST", base+"/rejoin", "", `{"code":"`+bob.RecoveryCode+`"}`)
+	expect(rec, http.StatusOK, "rejoin")
+	again := decodeBody[apiJoinResponse](t, rec)
+	if again.Name != "Bob" || again.Role != "voter" || again.RecoveryCode != bob.RecoveryCode {
+		t.Fatalf("unexpected rejoin response: %+v", again)
+	}
+	expect(c.do("PUT", base+"/lock", again.Token, `{"locked":false}`), http.StatusNoContent, "unlock after rejoin")
+}
+
+func TestAPI_CreatorTokenMakesFacilitator(t *testing.T) {
+	c := apiClient{t: t, srv: newTestServer(t, io.Discard)}
+	created := decodeBody[apiRoom](t, c.do("POST", "/api/v1/rooms", "", `{"passcode":"s3cret"}`))
+	if created.CreatorToken == "" {
+		t.Fatalf("create should return a creator token: %+v", created)
+	}
+	base := "/api/v1/rooms/" + created.ID
+	c.do("POST", base+"/participants", "", `{"name":"Mallory","passcode":"s3cret"}`)
+	other := decodeBody[apiRoom](t, c.do("POST", "/api/v1/rooms", "", `{}`))
+	if rec := c.do("POST", base+"/participants", "", `{"name":"Alice","creator_token":"`+other.CreatorToken+`"}`); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("creator token of another room: got %d want 401", rec.Code)
+	}
+	rec := c.do("POST", base+"/participants", "", `{"name":"Alice","creator_token":"`+created.CreatorToken+`"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("creator join without passcode: got %d want 201: %s", rec.Code, rec.Body)
+	}
+	alice := decodeBody[apiJoinResponse](t, rec)
+	room := decodeBody[apiRoom](t, c.do("GET", base, alice.Token, ""))
+	for _, p := range room.Participants {
+		if p.Facilitator != (p.Name == "Alice") {
+			t.Fatalf("the creator should be facilitator: %+v", room.Participants)
+		}
+	}
+	if strings.Contains(c.do("GET", base, alice.Token, "").Body.String(), "creator_token") {
+		t.Fatalf("only the create response carries the creator token")
+	}
+	rec = c.do("POST", base+"/participants", "", `{"name":"Eve","creator_token":"`+created.CreatorToken+`"}`)
+	if rec.Code != http.StatusForbidden || decodeBody[apiError](t, rec).Error.Code != "creator_joined" {
+		t.Fatalf("reused creator token: got %d %s, want 403 creator_joined", rec.Code, rec.Body)
+	}
+}
+
+func TestAPI_Errors(t *testing.T) {
+	var logs strings.Builder
+	c := apiClient{t: t, srv: newTestServer(t, &logs)}
+
+	created := decodeBody[apiRoom](t, c.do("POST", "/api/v1/rooms", "", `{}`))
+	base := "/api/v1/rooms/" + created.ID
+	alice := decodeBody[apiJoinResponse](t, c.do("POST", base+"/participants", "", `{"name":"Alice"}`))
+	bob := decodeBody[apiJoinResponse](t, c.do("POST", base+"/participants", "", `{"name":"Bob"}`))
+	guarded := decodeBody[apiRoom](t, c.do("POST", "/api/v1/rooms", "", `{"passcode":"s3cret"}`))
+	if !guarded.HasPasscode {
+		t.Fatalf("room created with a passcode should say so: %+v", guarded)
+	}
+
+	cases := []struct {
+		name, method, path, token, body string
+		status                          int
+		code                            string
+	}{
+		{"unknown room", "GET", "/api/v1/rooms/nope", "", "", http.StatusNotFound, "room_not_found"},
+		{"malformed json", "POST", "/api/v1/rooms", "", `{"title":`, http.StatusBadRequest, "invalid_json"},
+		{"unknown field", "POST", "/api/v1/rooms", "", `{"colour":"red"}`, http.StatusBadRequest, "invalid_json"},
+		{"bad deck", "POST", "/api/v1/rooms", "", `{"deck":"custom","cards":["1"]}`, http.StatusUnprocessableEntity, "invalid_deck"},
+		{"bad role", "POST", base + "/participants", "", `{"name":"Carol","role":"boss"}`, http.StatusUnprocessableEntity, "invalid_role"},
+		{"duplicate name", "POST", base + "/participants", "", `{"name":"alice"}`, http.StatusConflict, "duplicate_name"},
+		{"wrong passcode", "POST", "/api/v1/rooms/" + guarded.ID + "/participants", "", `{"name":"Carol","passcode":"guess"}`, http.StatusForbidden, "wrong_passcode"},
+		{"passcode room to outsider", "GET", "/api/v1/rooms/" + guarded.ID, "", "", http.StatusUnauthorized, "invalid_token"},
+		{"missing token", "POST", base + "/reveal", "", "", http.StatusUnauthorized, "missing_token"},
+		{"foreign token", "POST", base + "/reveal", "forged", "", http.StatusUnauthorized, "invalid_token"},
+		{"bare participant ID", "POST", base + "/reveal", string(cookiePID(alice.Token)), "", http.StatusUnauthorized, "invalid_token"},
+		{"not facilitator", "POST", base + "/reveal", bob.Token, "", http.StatusForbidden, "not_facilitator"},
+		{"nothing to reveal", "POST", base + "/reveal", alice.Token, "", http.StatusConflict, "no_votes"},
+		{"card outside deck", "PUT", base + "/vote", alice.Token, `{"card":"XXL"}`, http.StatusUnprocessableEntity, "invalid_card"},
+	}
+	for _, tc := range cases {
+		rec := c.do(tc.method, tc.path, tc.token, tc.body)
+		if rec.Code != tc.status {
+			t.Fatalf("%s: got %d want %d: %s", tc.name, rec.Code, tc.status, rec.Body)
+		}
+		body := decodeBody[apiError](t, rec)
+		if body.Error.Code != tc.code || body.Error.Message == "" {
+			t.Fatalf("%s: unexpected error body %+v", tc.name, body)
+		}
+	}
+}
+
+func TestCommandErrors_CoverErrorCodes(t *testing.T) {
+	for _, ec := range app.ErrorCodes {
+		if status, code, _ := describeError(fmt.Errorf("cmd: %w", ec.Err)); status == http.StatusInternalServerError || code != ec.Code {
+			t.Errorf("%v: got %d %q, want a status for %q", ec.Err, status, code, ec.Code)
+		}
+	}
+	if status, code, _ := describeError(errors.New("disk on fire")); status != http.StatusInternalServerError || code != app.InternalCode {
+		t.Errorf("unexpected error: got %d %q", status, code)
+	}
+}
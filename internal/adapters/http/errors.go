@@ -0,0 +1,81 @@
+package httpadapter
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/jaminalder/estimations/internal/app"
+	"github.com/jaminalder/estimations/internal/domain"
+)
+
+// commandError describes how a rejected use-case is reported to users: the
+// HTTP status and a message for people; the stable code for API clients
+// comes from app.ErrorCodes. Validation errors (detail set) show the
+// domain's own wording, which names the offending value or limit.
+type commandError struct {
+	status  int
+	message string
+	detail  bool
+}
+
+// commandErrors covers every sentinel in app.ErrorCodes.
+var commandErrors = map[error]commandError{
+	app.ErrRoomNotFound:          {status: http.StatusNotFound, message: "This room does not exist (it may have expired)."},
+	app.ErrInvalidRecoveryCode:   {status: http.StatusForbidden, message: "That recovery code does not belong to anyone in this room."},
+	app.ErrWrongPasscode:         {status: http.StatusForbidden, message: "That passcode is not right."},
+	app.ErrTooManyAttempts:       {status: http.StatusTooManyRequests, message: "Too many wrong codes; please wait a minute and try again."},
+	domain.ErrNotFacilitator:     {status: http.StatusForbidden, message: "Only the facilitator can do that."},
+	domain.ErrNotParticipant:     {status: http.StatusForbidden, message: "Not a participant of this room."},
+	domain.ErrObserverCannotVote: {status: http.StatusForbidden, message: "Observers cannot vote."},
+	domain.ErrDuplicateName:      {status: http.StatusConflict, message: "That name is already taken in this room."},
+	domain.ErrRoomLocked:         {status: http.StatusConflict, message: "This room is locked; ask the facilitator to unlock it."},
+	domain.ErrRoomFull:           {status: http.StatusConflict, message: "This room is full."},
+	domain.ErrVotingClosed:       {status: http.StatusConflict, message: "Votes are revealed; wait for the next round."},
+	domain.ErrNoVotes:            {status: http.StatusConflict, message: "Nobody has voted yet."},
+	domain.ErrNoCurrentStory:     {status: http.StatusConflict, message: "There is no story left to estimate."},
+	domain.ErrTooManyStories:     {status: http.StatusConflict, message: "The story backlog is full."},
+	domain.ErrCreatorJoined:      {status: http.StatusForbidden, message: "The room's creator has already joined; join under your own name."},
+	domain.ErrInvalidName:        {status: http.StatusUnprocessableEntity, message: "Please enter a name."},
+	domain.ErrInvalidCard:        {status: http.StatusUnprocessableEntity, detail: true},
+	domain.ErrInvalidLabel:       {status: http.StatusUnprocessableEntity, detail: true},
+	domain.ErrInvalidTitle:       {status: http.StatusUnprocessableEntity, detail: true},
+	domain.ErrInvalidStory:       {status: http.StatusUnprocessableEntity, detail: true},
+	domain.ErrInvalidEstimate:    {status: http.StatusUnprocessableEntity, detail: true},
+	domain.ErrInvalidDeck:        {status: http.StatusUnprocessableEntity, detail: true},
+	app.ErrInvalidPasscode:       {status: http.StatusUnprocessableEntity, detail: true},
+}
+
+// describeError maps a use-case error to its status, code and user-facing
+// message. Unknown errors are internal: 500 without leaking details.
+func describeError(err error) (status int, code, message string) {
+	ec, ok := app.CodeOf(err)
+	ce, described := commandErrors[ec.Err]
+	if !ok || !described {
+		return http.StatusInternalServerError, app.InternalCode, "Something went wrong. Please try again."
+	}
+	if ce.detail {
+		return ce.status, ec.Code, domainDetail(err, ec.Err)
+	}
+	return ce.status, ec.Code, ce.message
+}
+
+// domainDetail returns err's message from the sentinel onwards (dropping the
+// use-case prefix added by the app layer), capitalized as a sentence.
+func domainDetail(err, sentinel error) string {
+	msg := err.Error()
+	if i := strings.Index(msg, sentinel.Error()); i >= 0 {
+		msg = msg[i:]
+	}
+	return strings.ToUpper(msg[:1]) + msg[1:] + "."
+}
+
+// commandFailed answers a rejected room command by re-rendering the room with
+// the reason; a missing room is a plain 404.
+func (h *Handler) commandFailed(w http.ResponseWriter, r *http.Request, roomID string, err error) {
+	status, _, msg := describeError(err)
+	if status == http.StatusNotFound {
+		http.NotFound(w, r)
+		return
+	}
+	h.renderRoom(w, r, roomID, status, msg)
+}
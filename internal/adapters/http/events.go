@@ -0,0 +1,151 @@
+package httpadapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/jaminalder/estimations/internal/adapters/sse"
+	"github.com/jaminalder/estimations/internal/app"
+	"github.com/jaminalder/estimations/internal/domain"
+)
+
+// EventSource provides room-scoped event subscriptions (implemented by sse.Hub).
+// A non-zero lastEventID asks for the messages after it to be replayed first.
+type EventSource interface {
+	Subscribe(roomID domain.RoomID, lastEventID uint64) (<-chan sse.Message, func())
+}
+
+// Stream timing: browsers reconnect after retryAfter when the stream drops,
+// and an idle stream carries a comment every heartbeat (see WithHeartbeat)
+// so proxies keep it open and dead clients are noticed.
+const (
+	retryAfter       = 3 * time.Second
+	defaultHeartbeat = 15 * time.Second
+)
+
+// Events streams room events as text/event-stream until the client disconnects.
+// Each message is written with its ID and event name so clients can listen per
+// type and resume after a reconnect via the Last-Event-ID header.
+// A participant's open stream marks them present (see app.Service.Connect).
+// Rooms with a passcode stream to their participants only, and only while
+// they are.
+func (h *Handler) Events(w http.ResponseWriter, r *http.Request) {
+	roomID := strings.TrimSpace(chi.URLParam(r, "roomID"))
+	if roomID == "" {
+		http.NotFound(w, r)
+		return
+	}
+	if h.svc == nil || h.events == nil {
+		http.Error(w, "service unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	room, err := h.svc.Snapshot(r.Context(), domain.RoomID(roomID))
+	if errors.Is(err, app.ErrRoomNotFound) {
+		http.NotFound(w, r)
+		return
+	} else if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	pid := domain.ParticipantID(h.readPID(r))
+	if hiddenFrom(room, pid) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	ch, unsubscribe := h.events.Subscribe(domain.RoomID(roomID), lastEventID(r))
+	defer unsubscribe()
+	// The stream doubles as the participant's presence: once all their
+	// streams are gone for the grace period, they are removed from the room.
+	if pid != "" && room.HasParticipant(pid) {
+		defer h.svc.Connect(domain.RoomID(roomID), pid)()
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	// Initial comment so clients (and proxies) see the stream open immediately.
+	_, _ = fmt.Fprintf(w, ": connected\nretry: %d\n\n", retryAfter.Milliseconds())
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case msg, open := <-ch:
+			if !open {
+				return
+			}
+			if room.HasPasscode && !h.keepsAccess(r.Context(), room.ID, pid, msg) {
+				return
+			}
+			if err := writeEvent(w, msg); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// keepsAccess reports whether a participant's stream of a room with a
+// passcode may carry msg. Participants only go away with ParticipantLeft
+// (leaving, being released or being absent too long), so membership is
+// checked again then, and the stream of someone no longer in the room ends.
+func (h *Handler) keepsAccess(ctx context.Context, roomID domain.RoomID, pid domain.ParticipantID, msg sse.Message) bool {
+	if msg.Event != "ParticipantLeft" {
+		return true
+	}
+	room, err := h.svc.Snapshot(ctx, roomID)
+	return err == nil && !hiddenFrom(room, pid)
+}
+
+// writeEvent writes a single SSE frame. Payloads are single-line JSON, but
+// split defensively so embedded newlines never break framing.
+func writeEvent(w http.ResponseWriter, msg sse.Message) error {
+	if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\n", msg.ID, msg.Event); err != nil {
+		return err
+	}
+	for _, line := range strings.Split(string(msg.Data), "\n") {
+		if _, err := fmt.Fprintf(w, "data: %s\n", line); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprint(w, "\n")
+	return err
+}
+
+// lastEventID parses the Last-Event-ID header sent by reconnecting clients.
+// An unparsable ID cannot be resumed from, so it asks for a resync.
+func lastEventID(r *http.Request) uint64 {
+	v := strings.TrimSpace(r.Header.Get("Last-Event-ID"))
+	if v == "" {
+		return 0
+	}
+	id, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		return math.MaxUint64
+	}
+	return id
+}
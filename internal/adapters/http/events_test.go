@@ -0,0 +1,303 @@
+package httpadapter
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jaminalder/estimations/internal/adapters/idgen"
+	"github.com/jaminalder/estimations/internal/adapters/memory"
+	"github.com/jaminalder/estimations/internal/adapters/sse"
+	"github.com/jaminalder/estimations/internal/app"
+	"github.com/jaminalder/estimations/internal/domain"
+)
+
+func newEventsTestServer(t *testing.T, src EventSource, bus app.Broadcaster) (*httptest.Server, *app.Service) {
+	t.Helper()
+	r, err := NewRenderer()
+	if err != nil {
+		t.Fatalf("renderer: %v", err)
+	}
+	svc := &app.Service{Rooms: memory.NewRoomRepo(), Ids: idgen.NewRandom(10, 8), Bus: bus}
+	ts := httptest.NewServer(NewServer(svc, r, WithEvents(src), WithSessionKeys(testSessionKey)))
+	t.Cleanup(ts.Close)
+	return ts, svc
+}
+
+func TestEvents_StreamsNamedEvents(t *testing.T) {
+	hub := sse.NewHub(8)
+	ts, svc := newEventsTestServer(t, hub, hub)
+	ctx := context.Background()
+	roomID, err := svc.CreateRoom(ctx)
+	if err != nil {
+		t.Fatalf("create room: %v", err)
+	}
+
+	reqCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(reqCtx, "GET", ts.URL+"/rooms/"+string(roomID)+"/events", nil)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("get events: %v", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("status: got %d want 200", res.StatusCode)
+	}
+	if ct := res.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/event-stream") {
+		t.Fatalf("content type: %q", ct)
+	}
+
+	lines := bufio.NewScanner(res.Body)
+	if got := readFrame(lines); len(got) != 2 || got[0] != ": connected" || got[1] != "retry: 3000" {
+		t.Fatalf("expected connected comment and retry hint, got %q", got)
+	}
+
+	if _, err := svc.Join(ctx, roomID, "Alice"); err != nil {
+		t.Fatalf("join: %v", err)
+	}
+	got := readFrame(lines)
+	if len(got) != 3 || got[0] != "id: 1" || got[1] != "event: ParticipantJoined" || !strings.Contains(got[2], `"Name":"Alice"`) {
+		t.Fatalf("unexpected frame: %q", got)
+	}
+}
+
+// readFrame returns the lines of the next SSE frame, up to the blank line.
+func readFrame(lines *bufio.Scanner) []string {
+	var frame []string
+	for lines.Scan() {
+		if lines.Text() == "" {
+			break
+		}
+		frame = append(frame, lines.Text())
+	}
+	return frame
+}
+
+func TestEvents_LastEventID_ReplaysMissed(t *testing.T) {
+	hub := sse.NewHub(8)
+	ts, svc := newEventsTestServer(t, hub, hub)
+	ctx := context.Background()
+	roomID, _ := svc.CreateRoom(ctx)
+	for _, name := range []string{"Alice", "Bob", "Carol"} {
+		if _, err := svc.Join(ctx, roomID, name); err != nil {
+			t.Fatalf("join %s: %v", name, err)
+		}
+	}
+
+	stream := func(lastID string) *bufio.Scanner {
+		reqCtx, cancel := context.WithCancel(ctx)
+		t.Cleanup(cancel)
+		req, _ := http.NewRequestWithContext(reqCtx, "GET", ts.URL+"/rooms/"+string(roomID)+"/events", nil)
+		req.Header.Set("Last-Event-ID", lastID)
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("get events: %v", err)
+		}
+		t.Cleanup(func() { res.Body.Close() })
+		lines := bufio.NewScanner(res.Body)
+		readFrame(lines) // connected
+		return lines
+	}
+
+	// A client that saw event 1 gets Bob and Carol again.
+	lines := stream("1")
+	for _, want := range []string{"Bob", "Carol"} {
+		got := readFrame(lines)
+		if len(got) != 3 || got[1] != "event: ParticipantJoined" || !strings.Contains(got[2], want) {
+			t.Fatalf("replay: expected %s, got %q", want, got)
+		}
+	}
+
+	// An ID the hub never issued cannot be resumed from.
+	if got := readFrame(stream("garbage")); len(got) != 3 || got[0] != "id: 3" || got[1] != "event: Resync" {
+		t.Fatalf("expected resync frame, got %q", got)
+	}
+}
+
+func TestEvents_Heartbeat(t *testing.T) {
+	r, err := NewRenderer()
+	if err != nil {
+		t.Fatalf("renderer: %v", err)
+	}
+	hub := sse.NewHub(8)
+	svc := &app.Service{Rooms: memory.NewRoomRepo(), Ids: idgen.NewRandom(10, 8), Bus: hub}
+	ts := httptest.NewServer(NewServer(svc, r, WithEvents(hub), WithHeartbeat(10*time.Millisecond)))
+	t.Cleanup(ts.Close)
+	roomID, _ := svc.CreateRoom(context.Background())
+
+	reqCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(reqCtx, "GET", ts.URL+"/rooms/"+string(roomID)+"/events", nil)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("get events: %v", err)
+	}
+	defer res.Body.Close()
+	lines := bufio.NewScanner(res.Body)
+	readFrame(lines) // connected
+	if got := readFrame(lines); len(got) != 1 || got[0] != ": ping" {
+		t.Fatalf("expected heartbeat comment, got %q", got)
+	}
+}
+
+func TestEvents_UnknownRoom_404(t *testing.T) {
+	hub := sse.NewHub(8)
+	ts, _ := newEventsTestServer(t, hub, hub)
+
+	res, err := http.Get(ts.URL + "/rooms/unknown/events")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusNotFound {
+		t.Fatalf("status: got %d want 404", res.StatusCode)
+	}
+}
+
+func TestEvents_PasscodeRoom_ParticipantsOnly(t *testing.T) {
+	hub := sse.NewHub(8)
+	ts, svc := newEventsTestServer(t, hub, hub)
+	roomID, _ := svc.CreateRoom(context.Background(), app.WithPasscode("s3cret"))
+
+	for _, path := range []string{"/events", "/ws"} {
+		res, err := http.Get(ts.URL + "/rooms/" + string(roomID) + path)
+		if err != nil {
+			t.Fatalf("get %s: %v", path, err)
+		}
+		res.Body.Close()
+		if res.StatusCode != http.StatusForbidden {
+			t.Fatalf("%s for outsider: got %d want 403", path, res.StatusCode)
+		}
+	}
+}
+
+// countingSource wraps a hub and reports when subscribers go away.
+type countingSource struct {
+	hub  *sse.Hub
+	mu   sync.Mutex
+	subs int
+	gone chan struct{}
+}
+
+func (c *countingSource) Subscribe(roomID domain.RoomID, lastEventID uint64) (<-chan sse.Message, func()) {
+	ch, unsubscribe := c.hub.Subscribe(roomID, lastEventID)
+	c.mu.Lock()
+	c.subs++
+	c.mu.Unlock()
+	return ch, func() {
+		unsubscribe()
+		c.mu.Lock()
+		c.subs--
+		c.mu.Unlock()
+		close(c.gone)
+	}
+}
+
+func TestEvents_ClientDisconnect_Unsubscribes(t *testing.T) {
+	hub := sse.NewHub(8)
+	src := &countingSource{hub: hub, gone: make(chan struct{})}
+	ts, svc := newEventsTestServer(t, src, hub)
+	roomID, err := svc.CreateRoom(context.Background())
+	if err != nil {
+		t.Fatalf("create room: %v", err)
+	}
+
+	reqCtx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(reqCtx, "GET", ts.URL+"/rooms/"+string(roomID)+"/events", nil)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("get events: %v", err)
+	}
+	defer res.Body.Close()
+	cancel()
+
+	select {
+	case <-src.gone:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("handler did not unsubscribe after client disconnect")
+	}
+	src.mu.Lock()
+	defer src.mu.Unlock()
+	if src.subs != 0 {
+		t.Fatalf("expected no subscribers, got %d", src.subs)
+	}
+}
+
+func TestEvents_StreamClosed_ParticipantLeavesAfterGrace(t *testing.T) {
+	hub := sse.NewHub(8)
+	ts, svc := newEventsTestServer(t, hub, hub)
+	ctx := context.Background()
+	roomID, _ := svc.CreateRoom(ctx)
+	alice, _ := svc.Join(ctx, roomID, "Alice")
+	bob, _ := svc.Join(ctx, roomID, "Bob")
+
+	reqCtx, cancel := context.WithCancel(ctx)
+	req, _ := http.NewRequestWithContext(reqCtx, "GET", ts.URL+"/rooms/"+string(roomID)+"/events", nil)
+	req.AddCookie(&http.Cookie{Name: "pid", Value: signedPID(roomID, bob)})
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("get events: %v", err)
+	}
+	bufio.NewReader(res.Body).ReadString('\n') // stream is open
+
+	// While the stream is open Bob stays, however long it lasts.
+	if n, err := svc.LeaveDisconnected(ctx, 0); err != nil || n != 0 {
+		t.Fatalf("connected: removed %d, %v", n, err)
+	}
+	cancel()
+	res.Body.Close()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		n, err := svc.LeaveDisconnected(ctx, 0)
+		if err != nil {
+			t.Fatalf("leave disconnected: %v", err)
+		}
+		if n == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("bob was not removed after his stream closed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	room, _ := svc.Snapshot(ctx, roomID)
+	if room.HasParticipant(bob) || !room.HasParticipant(alice) {
+		t.Fatalf("only bob should have left: %+v", room.Participants)
+	}
+}
+
+func TestEvents_PasscodeRoom_StreamEndsWhenParticipantLeaves(t *testing.T) {
+	hub := sse.NewHub(8)
+	ts, svc := newEventsTestServer(t, hub, hub)
+	ctx := context.Background()
+	roomID, _ := svc.CreateRoom(ctx, app.WithPasscode("s3cret"))
+	alice, _ := svc.Join(ctx, roomID, "Alice", app.UsingPasscode("s3cret"))
+	bob, _ := svc.Join(ctx, roomID, "Bob", app.UsingPasscode("s3cret"))
+
+	req, _ := http.NewRequest("GET", ts.URL+"/rooms/"+string(roomID)+"/events", nil)
+	req.AddCookie(&http.Cookie{Name: "pid", Value: signedPID(roomID, bob)})
+	res, err := http.DefaultClient.Do(req)
+	if err != nil || res.StatusCode != http.StatusOK {
+		t.Fatalf("participant's stream: %v, %v", res, err)
+	}
+	defer res.Body.Close()
+	lines := bufio.NewScanner(res.Body)
+	readFrame(lines) // connected
+
+	if err := svc.Release(ctx, roomID, alice, bob); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	_ = svc.Cast(ctx, roomID, alice, "5")
+	for lines.Scan() {
+		if strings.HasPrefix(lines.Text(), "event: VoteCast") {
+			t.Fatalf("a released participant must not see later events")
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package httpadapter
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/jaminalder/estimations/internal/app"
+)
+
+// checkTimeout bounds each health check.
+const checkTimeout = 2 * time.Second
+
+// healthCheck is one named dependency probed by /healthz or /readyz.
+type healthCheck struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+type healthResponse struct {
+	Status string            `json:"status"` // "ok" or "unavailable"
+	Checks map[string]string `json:"checks"` // "ok" or the error, by check
+}
+
+// Healthz handles GET /healthz, the liveness probe. It fails only if the
+// event hub is wedged, which restarting the process fixes.
+func (h *Handler) Healthz(w http.ResponseWriter, r *http.Request) {
+	h.probe(w, r, h.liveness())
+}
+
+// Readyz handles GET /readyz, the readiness probe: the room repository must
+// answer too.
+func (h *Handler) Readyz(w http.ResponseWriter, r *http.Request) {
+	h.probe(w, r, append(h.liveness(), healthCheck{"rooms", h.svc.Ping}))
+}
+
+// liveness returns the checks of in-process dependencies: the event hub, if
+// it can be pinged (sse.Hub can).
+func (h *Handler) liveness() []healthCheck {
+	if p, ok := h.events.(app.Pinger); ok {
+		return []healthCheck{{"hub", p.Ping}}
+	}
+	return nil
+}
+
+// probe runs the checks and answers 200 if all pass, else 503.
+func (h *Handler) probe(w http.ResponseWriter, r *http.Request, checks []healthCheck) {
+	resp := healthResponse{Status: "ok", Checks: make(map[string]string, len(checks))}
+	for _, c := range checks {
+		ctx, cancel := context.WithTimeout(r.Context(), checkTimeout)
+		err := c.check(ctx)
+		cancel()
+		if err != nil {
+			resp.Status = "unavailable"
+			resp.Checks[c.name] = err.Error()
+			continue
+		}
+		resp.Checks[c.name] = "ok"
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	status := http.StatusOK
+	if resp.Status != "ok" {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, resp)
+}
@@ -0,0 +1,52 @@
+package httpadapter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jaminalder/estimations/internal/adapters/idgen"
+	"github.com/jaminalder/estimations/internal/adapters/memory"
+	"github.com/jaminalder/estimations/internal/adapters/sse"
+	"github.com/jaminalder/estimations/internal/app"
+)
+
+// downRepo is a room repository whose store is unreachable.
+type downRepo struct{ *memory.RoomRepo }
+
+func (downRepo) Ping(context.Context) error { return errors.New("database is down") }
+
+func TestProbes(t *testing.T) {
+	r, err := NewRenderer()
+	if err != nil {
+		t.Fatalf("renderer: %v", err)
+	}
+	probe := func(srv http.Handler, path string) (int, healthResponse) {
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		var body healthResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("%s: %v: %s", path, err, rec.Body.String())
+		}
+		return rec.Code, body
+	}
+
+	up := NewServer(&app.Service{Rooms: memory.NewRoomRepo(), Ids: idgen.NewRandom(10, 8)}, r, WithEvents(sse.NewHub(4)))
+	for _, path := range []string{"/healthz", "/readyz"} {
+		if code, body := probe(up, path); code != http.StatusOK || body.Status != "ok" || body.Checks["hub"] != "ok" {
+			t.Fatalf("%s: got %d %+v", path, code, body)
+		}
+	}
+
+	down := NewServer(&app.Service{Rooms: downRepo{memory.NewRoomRepo()}, Ids: idgen.NewRandom(10, 8)}, r, WithEvents(sse.NewHub(4)))
+	if code, _ := probe(down, "/healthz"); code != http.StatusOK {
+		t.Fatalf("liveness must not depend on the repository, got %d", code)
+	}
+	code, body := probe(down, "/readyz")
+	if code != http.StatusServiceUnavailable || body.Status != "unavailable" || body.Checks["rooms"] != "database is down" || body.Checks["hub"] != "ok" {
+		t.Fatalf("readyz: got %d %+v", code, body)
+	}
+}
@@ -1,12 +1,84 @@
 package httpadapter
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/jaminalder/estimations/internal/app"
+	"github.com/jaminalder/estimations/internal/domain"
 )
 
+// deckLabels are the display names of the built-in decks.
+var deckLabels = map[string]string{
+	domain.DeckFibonacci:         "Fibonacci",
+	domain.DeckModifiedFibonacci: "Modified Fibonacci",
+	domain.DeckTShirt:            "T-shirt sizes",
+	domain.DeckPowersOfTwo:       "Powers of two",
+}
+
+var errUnknownDeck = fmt.Errorf("%w: unknown preset", domain.ErrInvalidDeck)
+
+type deckOption struct {
+	Name  string
+	Label string
+	Cards string
+}
+
+func deckOptions() []deckOption {
+	presets := domain.PresetDecks()
+	out := make([]deckOption, 0, len(presets))
+	for _, d := range presets {
+		out = append(out, deckOption{Name: d.Name(), Label: deckLabels[d.Name()], Cards: strings.Join(d.Cards(), " ")})
+	}
+	return out
+}
+
+// landingData fills the create and join forms; after a failed join it keeps
+// the entered code and carries the reason.
+type landingData struct {
+	pageMeta
+	Decks      []deckOption
+	CustomDeck string
+	Code       string
+	Error      string
+}
+
 // Landing renders the landing page (index).
 func (h *Handler) Landing(w http.ResponseWriter, r *http.Request) {
-	_ = h.r.Render(w, "index", nil)
+	h.renderLanding(w, r, landingData{}, http.StatusOK)
+}
+
+func (h *Handler) renderLanding(w http.ResponseWriter, r *http.Request, data landingData, status int) {
+	data.Decks, data.CustomDeck = deckOptions(), domain.DeckCustom
+	_ = h.r.RenderStatus(w, r, status, "index", &data)
+}
+
+// JoinCode handles GET /join?code=, sending the browser to the lobby of the
+// room with that ID. Codes read out to people (see idgen.Codes) are also
+// accepted the way people type them.
+func (h *Handler) JoinCode(w http.ResponseWriter, r *http.Request) {
+	if h.svc == nil {
+		http.Error(w, "service unavailable", http.StatusInternalServerError)
+		return
+	}
+	code := strings.TrimSpace(r.URL.Query().Get("code"))
+	for _, id := range []string{code, app.NormalizeCode(code)} {
+		if id == "" {
+			continue
+		}
+		_, err := h.svc.Snapshot(r.Context(), domain.RoomID(id))
+		if err == nil {
+			http.Redirect(w, r, "/rooms/"+url.PathEscape(id)+"/lobby", http.StatusSeeOther)
+			return
+		} else if !errors.Is(err, app.ErrRoomNotFound) {
+			http.Error(w, "server error", http.StatusInternalServerError)
+			return
+		}
+	}
+	h.renderLanding(w, r, landingData{Code: code, Error: "There is no room with that code."}, http.StatusNotFound)
 }
 
 // CreateRoom handles POST /rooms and redirects to the lobby.
@@ -23,10 +95,38 @@ func (h *Handler) CreateRoom(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "service unavailable", http.StatusInternalServerError)
 		return
 	}
-	id, err := h.svc.CreateRoom(r.Context())
+	deck, err := deckFromForm(r.FormValue("deck"), r.FormValue("cards"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	id, err := h.svc.CreateRoom(r.Context(), app.WithDeck(deck), app.WithTitle(r.FormValue("title")), app.WithPasscode(r.FormValue("passcode")))
 	if err != nil {
-		http.Error(w, "failed to create room", http.StatusInternalServerError)
+		status, _, msg := describeError(err)
+		http.Error(w, msg, status)
 		return
 	}
+	h.setCreator(w, r, string(id))
 	http.Redirect(w, r, "/rooms/"+string(id)+"/lobby", http.StatusSeeOther)
 }
+
+// deckFromForm resolves the deck choice of the create form, where custom
+// cards are comma-separated.
+func deckFromForm(name, cards string) (domain.Deck, error) {
+	return deckByName(name, strings.Split(cards, ","))
+}
+
+// deckByName resolves a deck choice: empty means the default deck, a preset
+// name selects that preset, and "custom" builds a deck from cards.
+func deckByName(name string, cards []string) (domain.Deck, error) {
+	switch name = strings.TrimSpace(name); name {
+	case "":
+		return domain.DefaultDeck(), nil
+	case domain.DeckCustom:
+		return domain.NewCustomDeck(cards)
+	}
+	if d, ok := domain.PresetDeck(name); ok {
+		return d, nil
+	}
+	return domain.Deck{}, errUnknownDeck
+}
@@ -1,11 +1,13 @@
 package httpadapter
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
 	"github.com/go-chi/chi/v5"
 
+	"github.com/jaminalder/estimations/internal/app"
 	"github.com/jaminalder/estimations/internal/domain"
 )
 
@@ -16,27 +18,62 @@ func (h *Handler) Lobby(w http.ResponseWriter, r *http.Request) {
 		http.NotFound(w, r)
 		return
 	}
+	data := lobbyData{RoomID: roomID, Code: r.URL.Query().Get("code")}
 	if h.svc != nil {
-		room, ok, err := h.svc.Rooms.Get(r.Context(), domain.RoomID(roomID))
-		if err != nil {
-			http.Error(w, "server error", http.StatusInternalServerError)
-			return
-		} else if !ok {
+		room, err := h.svc.Snapshot(r.Context(), domain.RoomID(roomID))
+		if errors.Is(err, app.ErrRoomNotFound) {
 			http.NotFound(w, r)
 			return
+		} else if err != nil {
+			http.Error(w, "server error", http.StatusInternalServerError)
+			return
 		}
 		// If already joined (cookie pid present and matches a participant), redirect to room.
-		if pid := h.readPID(r); pid != "" {
-			for _, p := range room.Participants() {
-				if string(p.ID) == pid {
-					http.Redirect(w, r, "/rooms/"+roomID, http.StatusSeeOther)
-					return
-				}
-			}
+		if pid := h.readPID(r); pid != "" && room.HasParticipant(domain.ParticipantID(pid)) {
+			http.Redirect(w, r, "/rooms/"+roomID, http.StatusSeeOther)
+			return
 		}
+		data.Passcode, data.Locked = room.HasPasscode, room.Locked
+		h.creatorAccess(r, room, &data)
 	}
-	data := struct{ RoomID string }{RoomID: roomID}
-	_ = h.r.Render(w, "lobby", data)
+	h.renderLobby(w, r, data, http.StatusOK)
+}
+
+// lobbyData fills the join and rejoin forms; after a rejected attempt it
+// keeps what was entered and carries the reason.
+type lobbyData struct {
+	pageMeta
+	RoomID   string
+	Name     string
+	Observer bool
+	Code     string // recovery code, prefilled from a recovery link
+	Passcode bool   // whether joining needs the room's passcode
+	Locked   bool   // whether the room takes no newcomers
+	Error    string
+}
+
+// lobbyAccess fills in whether the room asks newcomers for a passcode or
+// turns them away.
+func (h *Handler) lobbyAccess(r *http.Request, data *lobbyData) {
+	if h.svc == nil {
+		return
+	}
+	if room, err := h.svc.Snapshot(r.Context(), domain.RoomID(data.RoomID)); err == nil {
+		data.Passcode, data.Locked = room.HasPasscode, room.Locked
+		h.creatorAccess(r, room, data)
+	}
+}
+
+// creatorAccess lets the room's creator in without passcode or lock, until
+// the creator's claim has been used.
+func (h *Handler) creatorAccess(r *http.Request, room app.RoomSnapshot, data *lobbyData) {
+	if !room.CreatorJoined && h.isCreator(r, data.RoomID) {
+		data.Passcode, data.Locked = false, false
+	}
+}
+
+func (h *Handler) renderLobby(w http.ResponseWriter, r *http.Request, data lobbyData, status int) {
+	_ = h.r.RenderStatus(w, r, status, "lobby", &data)
 }
 
 // Join handles POST join and redirects to the room page.
@@ -51,37 +88,93 @@ func (h *Handler) Join(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	name := strings.TrimSpace(r.FormValue("name"))
-	if name == "" {
-		http.Error(w, "bad request", http.StatusBadRequest)
-		return
-	}
+	observer := r.FormValue("role") == domain.Observer.String()
 	if h.svc == nil {
 		http.Error(w, "service unavailable", http.StatusInternalServerError)
 		return
 	}
 	// If already has a participant cookie for this room, just go to the room.
 	if pid := h.readPID(r); pid != "" {
-		if room, ok, _ := h.svc.Rooms.Get(r.Context(), domain.RoomID(roomID)); ok {
-			for _, p := range room.Participants() {
-				if string(p.ID) == pid {
-					http.Redirect(w, r, "/rooms/"+roomID, http.StatusSeeOther)
-					return
-				}
-			}
+		if room, err := h.svc.Snapshot(r.Context(), domain.RoomID(roomID)); err == nil && room.HasParticipant(domain.ParticipantID(pid)) {
+			http.Redirect(w, r, "/rooms/"+roomID, http.StatusSeeOther)
+			return
+		}
+	}
+	opts := []app.JoinOption{app.UsingPasscode(r.FormValue("passcode"))}
+	if observer {
+		opts = append(opts, app.AsObserver())
+	}
+	creator := h.isCreator(r, roomID)
+	if creator {
+		opts = append(opts, app.AsCreator())
+	}
+	pid, err := h.svc.Join(r.Context(), domain.RoomID(roomID), name, opts...)
+	if err != nil {
+		status, _, msg := describeError(err)
+		if status == http.StatusNotFound {
+			http.NotFound(w, r)
+			return
 		}
+		if errors.Is(err, domain.ErrCreatorJoined) {
+			clearCreator(w, roomID)
+		}
+		data := lobbyData{RoomID: roomID, Name: name, Observer: observer, Error: msg}
+		h.lobbyAccess(r, &data)
+		h.renderLobby(w, r, data, status)
+		return
+	}
+	if creator {
+		clearCreator(w, roomID)
+	}
+	h.setPID(w, r, roomID, pid)
+	http.Redirect(w, r, "/rooms/"+roomID, http.StatusSeeOther)
+}
+
+// Rejoin handles POST rejoin: the browser takes over the session of the
+// participant whose recovery code was entered and goes to the room.
+func (h *Handler) Rejoin(w http.ResponseWriter, r *http.Request) {
+	roomID := chi.URLParam(r, "roomID")
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	if h.svc == nil {
+		http.Error(w, "service unavailable", http.StatusInternalServerError)
+		return
 	}
-	pid, err := h.svc.Join(r.Context(), domain.RoomID(roomID), name)
+	code := strings.TrimSpace(r.FormValue("code"))
+	pid, err := h.svc.Rejoin(r.Context(), domain.RoomID(roomID), code)
 	if err != nil {
-		http.Error(w, "join failed", http.StatusBadRequest)
+		status, _, msg := describeError(err)
+		if status == http.StatusNotFound {
+			http.NotFound(w, r)
+			return
+		}
+		data := lobbyData{RoomID: roomID, Code: code, Error: msg}
+		h.lobbyAccess(r, &data)
+		h.renderLobby(w, r, data, status)
 		return
 	}
-	// Scope participant cookie to this room path so multiple rooms don't collide.
-	http.SetCookie(w, &http.Cookie{
-		Name:     "pid",
-		Value:    string(pid),
-		Path:     "/rooms/" + roomID,
-		HttpOnly: true,
-		SameSite: http.SameSiteLaxMode,
-	})
+	h.setPID(w, r, roomID, pid)
 	http.Redirect(w, r, "/rooms/"+roomID, http.StatusSeeOther)
 }
+
+// Leave handles POST leave: the participant leaves the room, the pid cookie
+// is cleared and the browser is sent back to the lobby. Leaving twice (or
+// without a cookie) is harmless.
+func (h *Handler) Leave(w http.ResponseWriter, r *http.Request) {
+	roomID := chi.URLParam(r, "roomID")
+	if h.svc == nil {
+		http.Error(w, "service unavailable", http.StatusInternalServerError)
+		return
+	}
+	if pid := h.readPID(r); pid != "" {
+		err := h.svc.Leave(r.Context(), domain.RoomID(roomID), domain.ParticipantID(pid))
+		if err != nil && !errors.Is(err, domain.ErrNotParticipant) {
+			h.commandFailed(w, r, roomID, err)
+			return
+		}
+	}
+	clearPID(w, roomID)
+	http.Redirect(w, r, "/rooms/"+roomID+"/lobby", http.StatusSeeOther)
+}
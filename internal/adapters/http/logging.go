@@ -1,22 +1,74 @@
 package httpadapter
 
 import (
-	"log"
+	"log/slog"
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/go-chi/chi/v5"
 	chimw "github.com/go-chi/chi/v5/middleware"
+
+	"github.com/jaminalder/estimations/internal/app"
+	"github.com/jaminalder/estimations/internal/domain"
 )
 
-// Logging prints METHOD PATH STATUS DURATION using the provided logger.
-func Logging(l *log.Logger) func(http.Handler) http.Handler {
+// Logging logs one line per request: method, path, route pattern, status and
+// duration, plus the room and participant when the request names them. It
+// expects chi's RequestID middleware to run first: the request ID is logged,
+// echoed in the X-Request-Id response header and attached to the request
+// context, so the app layer's command logs carry it too. Server errors are
+// logged at error level, passing health probes at debug level.
+func Logging(l *slog.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
+			reqID := slog.String("request_id", chimw.GetReqID(r.Context()))
+			w.Header().Set("X-Request-Id", reqID.Value.String())
 			ww := chimw.NewWrapResponseWriter(w, r.ProtoMajor)
-			next.ServeHTTP(ww, r)
-			dur := time.Since(start)
-			l.Printf("%s %s %d %s", r.Method, r.URL.Path, ww.Status(), dur)
+			next.ServeHTTP(ww, r.WithContext(app.WithLogAttrs(r.Context(), reqID)))
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			attrs := []slog.Attr{
+				reqID,
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", status),
+				slog.Duration("duration", time.Since(start)),
+			}
+			if rc := chi.RouteContext(r.Context()); rc != nil && rc.RoutePattern() != "" {
+				attrs = append(attrs, slog.String("route", rc.RoutePattern()))
+			}
+			if roomID := chi.URLParam(r, "roomID"); roomID != "" {
+				attrs = append(attrs, app.RoomAttr(domain.RoomID(roomID)))
+			}
+			if pid := requestParticipant(r); pid != "" {
+				attrs = append(attrs, app.ParticipantAttr(pid))
+			}
+			level := slog.LevelInfo
+			switch {
+			case status >= http.StatusInternalServerError:
+				level = slog.LevelError
+			case status < http.StatusBadRequest && (r.URL.Path == "/healthz" || r.URL.Path == "/readyz"):
+				level = slog.LevelDebug
+			}
+			l.LogAttrs(r.Context(), level, "request", attrs...)
 		})
 	}
 }
+
+// requestParticipant returns the participant a request claims to be, from
+// the pid cookie or a participant's bearer token (both "<pid>.<MAC>"),
+// unverified. The admin API's bearer token names no participant.
+func requestParticipant(r *http.Request) domain.ParticipantID {
+	if c, err := r.Cookie("pid"); err == nil && c.Value != "" {
+		return cookiePID(c.Value)
+	}
+	if token := bearerToken(r); token != "" && !strings.HasPrefix(r.URL.Path, "/api/v1/admin/") {
+		return cookiePID(token)
+	}
+	return ""
+}
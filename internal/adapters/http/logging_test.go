@@ -0,0 +1,90 @@
+package httpadapter
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/jaminalder/estimations/internal/adapters/idgen"
+	"github.com/jaminalder/estimations/internal/adapters/memory"
+	"github.com/jaminalder/estimations/internal/app"
+)
+
+func TestLogging_RequestAndCommandShareRequestID(t *testing.T) {
+	r, err := NewRenderer()
+	if err != nil {
+		t.Fatalf("renderer: %v", err)
+	}
+	var out strings.Builder
+	logger := slog.New(slog.NewTextHandler(&out, nil))
+	svc := &app.Service{Rooms: memory.NewRoomRepo(), Ids: idgen.NewRandom(10, 8), Logger: logger}
+	srv := browser{NewServer(svc, r, WithLogger(logger), WithSessionKeys(testSessionKey))}
+	ctx := context.Background()
+	roomID, _ := svc.CreateRoom(ctx)
+	pid, _ := svc.Join(ctx, roomID, "alice")
+	out.Reset()
+
+	req := httptest.NewRequest("POST", "/rooms/"+string(roomID)+"/cast", strings.NewReader(url.Values{"card": {"5"}}.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.AddCookie(&http.Cookie{Name: "pid", Value: signedPID(roomID, pid)})
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+
+	reqID := rec.Header().Get("X-Request-Id")
+	if reqID == "" {
+		t.Fatal("missing X-Request-Id header")
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("want a command and a request line, got %q", lines)
+	}
+	for i, want := range [][]string{
+		{"msg=command", "command=cast"},
+		{"msg=request", "method=POST", "route=/rooms/{roomID}/cast", "status=303"},
+	} {
+		want = append(want, "request_id="+reqID, "room_id="+string(roomID), app.ParticipantAttr(pid).String())
+		for _, w := range want {
+			if !strings.Contains(lines[i], w) {
+				t.Errorf("line %d lacks %s: %s", i, w, lines[i])
+			}
+		}
+	}
+	if strings.Contains(out.String(), string(pid)) {
+		t.Fatalf("logs leak the participant ID: %s", out.String())
+	}
+}
+
+func TestLogging_APIAndAdminTokens(t *testing.T) {
+	r, err := NewRenderer()
+	if err != nil {
+		t.Fatalf("renderer: %v", err)
+	}
+	var out strings.Builder
+	logger := slog.New(slog.NewTextHandler(&out, nil))
+	svc := &app.Service{Rooms: memory.NewRoomRepo(), Ids: idgen.NewRandom(10, 8), Logger: logger}
+	srv := NewServer(svc, r, WithLogger(logger), WithSessionKeys(testSessionKey), WithAdminToken("s3cret-admin"))
+	ctx := context.Background()
+	roomID, _ := svc.CreateRoom(ctx)
+	pid, _ := svc.Join(ctx, roomID, "alice")
+	out.Reset()
+
+	req := httptest.NewRequest("POST", "/api/v1/rooms/"+string(roomID)+"/reset", nil)
+	req.Header.Set("Authorization", "Bearer "+signedPID(roomID, pid))
+	srv.ServeHTTP(httptest.NewRecorder(), req)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], app.ParticipantAttr(pid).String()) || !strings.Contains(lines[1], app.ParticipantAttr(pid).String()) {
+		t.Fatalf("command and request lines should name the same participant: %q", lines)
+	}
+
+	out.Reset()
+	req = httptest.NewRequest("GET", "/api/v1/admin/rooms", nil)
+	req.Header.Set("Authorization", "Bearer s3cret-admin")
+	srv.ServeHTTP(httptest.NewRecorder(), req)
+	if strings.Contains(out.String(), "participant=") {
+		t.Fatalf("admin requests name no participant: %s", out.String())
+	}
+}
@@ -0,0 +1,36 @@
+package httpadapter
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	chimw "github.com/go-chi/chi/v5/middleware"
+
+	"github.com/jaminalder/estimations/internal/adapters/metrics"
+)
+
+// Metrics records request durations by method, route pattern (e.g.
+// "/rooms/{roomID}/cast", so room IDs never become labels) and status code.
+// Requests matching no route are recorded as "unmatched".
+func Metrics(reg *metrics.Registry) func(http.Handler) http.Handler {
+	requests := reg.Histogram("estimations_http_request_duration_seconds",
+		"Duration of HTTP requests by route pattern.", metrics.DefaultBuckets, "method", "route", "code")
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			ww := chimw.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r)
+			route := "unmatched"
+			if rc := chi.RouteContext(r.Context()); rc != nil && rc.RoutePattern() != "" {
+				route = rc.RoutePattern()
+			}
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			requests.Observe(time.Since(start).Seconds(), r.Method, route, strconv.Itoa(status))
+		})
+	}
+}
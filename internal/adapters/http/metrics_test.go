@@ -0,0 +1,45 @@
+package httpadapter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jaminalder/estimations/internal/adapters/idgen"
+	"github.com/jaminalder/estimations/internal/adapters/memory"
+	"github.com/jaminalder/estimations/internal/adapters/metrics"
+	"github.com/jaminalder/estimations/internal/app"
+)
+
+func TestMetrics_RequestsByRoutePattern(t *testing.T) {
+	r, err := NewRenderer()
+	if err != nil {
+		t.Fatalf("renderer: %v", err)
+	}
+	reg := metrics.NewRegistry()
+	svc := &app.Service{Rooms: memory.NewRoomRepo(), Ids: idgen.NewRandom(10, 8)}
+	srv := NewServer(svc, r, WithMetrics(reg))
+
+	for _, path := range []string{"/rooms/abc/lobby", "/rooms/def/lobby", "/no/such/page"} {
+		srv.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	rec := httptest.NewRecorder()
+	reg.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		`estimations_http_request_duration_seconds_count{method="GET",route="/rooms/{roomID}/lobby",code="404"} 2`,
+		`estimations_http_request_duration_seconds_count{method="GET",route="unmatched",code="404"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("missing %q in:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "abc") {
+		t.Errorf("raw paths must not become labels:\n%s", body)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") || rec.Code != http.StatusOK {
+		t.Errorf("unexpected response %d %q", rec.Code, ct)
+	}
+}
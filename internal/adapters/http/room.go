@@ -1,11 +1,17 @@
 package httpadapter
 
 import (
+	"errors"
+	"math"
 	"net/http"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 
+	"github.com/jaminalder/estimations/internal/app"
 	"github.com/jaminalder/estimations/internal/domain"
 )
 
@@ -16,47 +22,207 @@ func (h *Handler) Room(w http.ResponseWriter, r *http.Request) {
 		http.NotFound(w, r)
 		return
 	}
+	h.renderRoom(w, r, roomID, http.StatusOK, "")
+}
+
+// renderRoom renders the room page with the given status and an optional
+// error message shown above the room.
+func (h *Handler) renderRoom(w http.ResponseWriter, r *http.Request, roomID string, status int, errMsg string) {
 	if h.svc == nil {
 		http.Error(w, "service unavailable", http.StatusInternalServerError)
 		return
 	}
-	room, ok, err := h.svc.Rooms.Get(r.Context(), domain.RoomID(roomID))
-	if err != nil {
-		http.Error(w, "server error", http.StatusInternalServerError)
-		return
-	}
-	if !ok || room == nil {
+	room, err := h.svc.Snapshot(r.Context(), domain.RoomID(roomID))
+	if errors.Is(err, app.ErrRoomNotFound) {
 		http.NotFound(w, r)
 		return
+	} else if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
 	}
 
-	votes := room.Votes()
 	me := h.readPID(r)
+	if hiddenFrom(room, domain.ParticipantID(me)) {
+		http.Redirect(w, r, "/rooms/"+roomID+"/lobby", http.StatusSeeOther)
+		return
+	}
+	votes := room.Votes
 	type participantVM struct {
-		Name     string
-		HasVoted bool
-		Card     string
-		IsYou    bool
+		Name          string
+		HasVoted      bool
+		Card          string
+		IsYou         bool
+		IsFacilitator bool
 	}
-	pvs := make([]participantVM, 0, len(room.Participants()))
-	for _, p := range room.Participants() {
+	pvs := make([]participantVM, 0, len(room.Participants))
+	var observers []participantVM
+	viewerObserves := false
+	for _, p := range room.Participants {
+		if p.IsObserver() {
+			observers = append(observers, participantVM{
+				Name:          p.Name,
+				IsYou:         string(p.ID) == me,
+				IsFacilitator: p.ID == room.Facilitator,
+			})
+			viewerObserves = viewerObserves || string(p.ID) == me
+			continue
+		}
 		card, has := votes[p.ID]
-		pvs = append(pvs, participantVM{Name: p.Name, HasVoted: has, Card: card, IsYou: string(p.ID) == me})
+		pvs = append(pvs, participantVM{
+			Name:          p.Name,
+			HasVoted:      has,
+			Card:          card,
+			IsYou:         string(p.ID) == me,
+			IsFacilitator: p.ID == room.Facilitator,
+		})
+	}
+	type roundVM struct {
+		Number     int // 1-based for display
+		Label      string
+		Estimate   string
+		Votes      []domain.NamedVote
+		RevealedAt time.Time
+	}
+	// Most recent round first.
+	history := make([]roundVM, 0, len(room.History))
+	for i := len(room.History) - 1; i >= 0; i-- {
+		h := room.History[i]
+		history = append(history, roundVM{Number: h.Index + 1, Label: h.Label, Estimate: h.Estimate, Votes: h.Votes, RevealedAt: h.RevealedAt})
+	}
+	type storyVM struct {
+		Index     int
+		Title     string
+		Key       string
+		Link      string
+		Estimate  string
+		IsDone    bool
+		IsCurrent bool
+		CanUp     bool // can move one position earlier
+		CanDown   bool // can move one position later
+		Up, Down  int  // target positions for the move buttons
+	}
+	stories := make([]storyVM, 0, len(room.Stories))
+	for i, st := range room.Stories {
+		stories = append(stories, storyVM{
+			Index:     i,
+			Title:     st.Title,
+			Key:       st.Key,
+			Link:      st.Link,
+			Estimate:  st.Estimate,
+			IsDone:    i < room.Current,
+			IsCurrent: i == room.Current,
+			CanUp:     i > room.Current,
+			CanDown:   i >= room.Current && i < len(room.Stories)-1,
+			Up:        i - 1,
+			Down:      i + 1,
+		})
+	}
+	var stats *statsVM
+	suggested := ""
+	if room.Revealed {
+		stats = newStatsVM(room.Stats)
+		suggested = room.Stats.Nearest
+		if suggested == "" && len(room.Stats.Mode) > 0 {
+			suggested = room.Stats.Mode[0]
+		}
 	}
 	data := struct {
+		pageMeta
+		Error        string // why the last command was rejected
 		RoomID       string
+		Title        string
 		Participants []participantVM
+		Observers    []participantVM
+		IsObserver   bool // whether the viewer only watches
+		Joined       bool // whether the viewer is a participant
 		Total        int
 		Voted        int
 		Deck         []string
 		Revealed     bool
+		Label        string
+		History      []roundVM
+		Stats        *statsVM
+		Facilitator  bool // whether the viewer is the facilitator
+		Stories      []storyVM
+		HasStory     bool   // whether a story is being estimated
+		Suggested    string // estimate preselected for "next story"
+		RecoveryCode string // the viewer's, to rejoin from another browser
+		RecoveryLink string // lobby link carrying RecoveryCode
+		Locked       bool   // whether the room takes no newcomers
 	}{
+		Error:        errMsg,
 		RoomID:       roomID,
+		Title:        room.Title,
 		Participants: pvs,
-		Total:        len(room.Participants()),
+		Observers:    observers,
+		IsObserver:   viewerObserves,
+		Joined:       me != "" && room.HasParticipant(domain.ParticipantID(me)),
+		Total:        len(pvs),
 		Voted:        len(votes),
-		Deck:         room.Deck(),
-		Revealed:     room.IsRevealed(),
+		Deck:         room.Deck,
+		Revealed:     room.Revealed,
+		Label:        room.Label,
+		History:      history,
+		Stats:        stats,
+		Facilitator:  me != "" && domain.ParticipantID(me) == room.Facilitator,
+		Stories:      stories,
+		HasStory:     room.Current < len(room.Stories),
+		Suggested:    suggested,
+		Locked:       room.Locked,
 	}
-	_ = h.r.Render(w, "room", data)
+	if data.Joined {
+		data.RecoveryCode = app.RecoveryCode(domain.ParticipantID(me))
+		data.RecoveryLink = h.absURL("/rooms/" + roomID + "/lobby?code=" + data.RecoveryCode)
+	}
+	_ = h.r.RenderStatus(w, r, status, "room", &data)
+}
+
+// hiddenFrom reports whether the room's contents and events are kept from
+// viewer: a room with a passcode shows them to its participants only, so the
+// passcode guards more than joining.
+func hiddenFrom(room app.RoomSnapshot, viewer domain.ParticipantID) bool {
+	return room.HasPasscode && (viewer == "" || !room.HasParticipant(viewer))
+}
+
+// statsVM is the display form of domain.Stats for the revealed view.
+type statsVM struct {
+	HasNumeric bool
+	Mean       string
+	Median     string
+	Min        string
+	Max        string
+	StdDev     string
+	Mode       string
+	Nearest    string
+	Consensus  bool
+	Specials   []specialVM
+}
+
+type specialVM struct {
+	Card  string
+	Count int
+}
+
+func newStatsVM(st domain.Stats) *statsVM {
+	vm := &statsVM{
+		HasNumeric: st.Numeric > 0,
+		Mean:       formatNum(st.Mean),
+		Median:     formatNum(st.Median),
+		Min:        formatNum(st.Min),
+		Max:        formatNum(st.Max),
+		StdDev:     formatNum(st.StdDev),
+		Mode:       strings.Join(st.Mode, ", "),
+		Nearest:    st.Nearest,
+		Consensus:  st.Consensus,
+	}
+	for card, n := range st.Specials {
+		vm.Specials = append(vm.Specials, specialVM{Card: card, Count: n})
+	}
+	sort.Slice(vm.Specials, func(i, j int) bool { return vm.Specials[i].Card < vm.Specials[j].Card })
+	return vm
+}
+
+// formatNum renders a number with at most one decimal place.
+func formatNum(v float64) string {
+	return strconv.FormatFloat(math.Round(v*10)/10, 'f', -1, 64)
 }
@@ -2,22 +2,63 @@ package httpadapter
 
 import (
 	"io/fs"
-	"log"
+	"log/slog"
+	"net"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	chimw "github.com/go-chi/chi/v5/middleware"
 
+	"github.com/jaminalder/estimations/internal/adapters/metrics"
+	"github.com/jaminalder/estimations/internal/app"
 	static "github.com/jaminalder/estimations/web/static"
 )
 
 type (
-	serverOpts struct{ logger *log.Logger }
-	Option     func(*serverOpts)
+	serverOpts struct {
+		logger    *slog.Logger
+		events    EventSource
+		heartbeat time.Duration
+		metrics   *metrics.Registry
+		admin     string
+		sessions  sessionKeys
+		baseURL   string
+	}
+	Option func(*serverOpts)
 )
 
-// WithLogger configures a standard logger for request logging.
-func WithLogger(l *log.Logger) Option { return func(o *serverOpts) { o.logger = l } }
+// WithLogger enables request logging (see Logging) to the given logger.
+func WithLogger(l *slog.Logger) Option { return func(o *serverOpts) { o.logger = l } }
+
+// WithEvents enables the per-room SSE stream backed by the given source.
+func WithEvents(src EventSource) Option { return func(o *serverOpts) { o.events = src } }
+
+// WithMetrics records HTTP request metrics on the given registry. The
+// registry itself is not served here; expose it on an operator-only listener.
+func WithMetrics(reg *metrics.Registry) Option { return func(o *serverOpts) { o.metrics = reg } }
+
+// WithAdminToken mounts the admin API under /api/v1/admin, guarded by the
+// given bearer token. Without it (or with an empty token) there is none.
+func WithAdminToken(token string) Option { return func(o *serverOpts) { o.admin = token } }
+
+// WithSessionKeys sets the keys participant cookies are signed with (see
+// session.go): the first signs, all verify. Without keys a random key is
+// used, and participants lose their session when the server restarts.
+func WithSessionKeys(keys ...[]byte) Option {
+	return func(o *serverOpts) { o.sessions = sessionKeys(keys) }
+}
+
+// WithHeartbeat sets how often an idle event stream sends a keep-alive
+// comment (15s by default).
+func WithHeartbeat(d time.Duration) Option { return func(o *serverOpts) { o.heartbeat = d } }
+
+// WithBaseURL sets the server's public URL (e.g. https://poker.example.com),
+// from which links meant to be shared or followed elsewhere are made
+// absolute: the recovery link and the API's Location headers. Without it
+// they stay relative to the request's host.
+func WithBaseURL(u string) Option { return func(o *serverOpts) { o.baseURL = u } }
 
 // newRouter builds the chi router with routes and middleware.
 func newRouter(h *Handler, opts ...Option) http.Handler {
@@ -25,12 +66,25 @@ func newRouter(h *Handler, opts ...Option) http.Handler {
 	for _, o := range opts {
 		o(&cfg)
 	}
+	h.events = cfg.events
+	h.heartbeat = cfg.heartbeat
+	h.sessions = cfg.sessions
+	h.baseURL = strings.TrimSuffix(cfg.baseURL, "/")
+	if len(h.sessions) == 0 {
+		h.sessions = sessionKeys{newSessionKey()}
+	}
+	if h.heartbeat <= 0 {
+		h.heartbeat = defaultHeartbeat
+	}
 
 	r := chi.NewRouter()
 	// Basic recoverer; keep logs readable
-	r.Use(chimw.Recoverer)
+	r.Use(chimw.Recoverer, clientAddr)
+	if cfg.metrics != nil {
+		r.Use(Metrics(cfg.metrics))
+	}
 	if cfg.logger != nil {
-		r.Use(Logging(cfg.logger))
+		r.Use(chimw.RequestID, Logging(cfg.logger))
 	}
 
 	// Static assets
@@ -38,36 +92,115 @@ func newRouter(h *Handler, opts ...Option) http.Handler {
 		r.Handle("/static/*", http.StripPrefix("/static/", http.FileServer(http.FS(sub))))
 	}
 
-	// Landing
-	r.Get("/", h.Landing)
-	r.Get("/landing", h.Landing)
-	r.Post("/rooms", h.CreateRoom)
-
-	// Rooms
-	r.Route("/rooms/{roomID}", func(r chi.Router) {
-		r.Get("/lobby", h.Lobby)
-		r.Post("/join", h.Join)
-		r.Get("/", h.Room)
-		r.Post("/cast", h.Cast)
-		r.Post("/clear", h.Clear)
-		r.Post("/reveal", h.Reveal)
-		r.Post("/reset", h.Reset)
+	// Probes
+	r.Get("/healthz", h.Healthz)
+	r.Get("/readyz", h.Readyz)
+
+	// Browser pages and forms, protected against forged posts
+	r.Group(func(r chi.Router) {
+		r.Use(CSRF)
+
+		// Landing
+		r.Get("/", h.Landing)
+		r.Get("/landing", h.Landing)
+		r.Get("/join", h.JoinCode)
+		r.Post("/rooms", h.CreateRoom)
+
+		// Rooms
+		r.Route("/rooms/{roomID}", func(r chi.Router) {
+			r.Get("/lobby", h.Lobby)
+			r.Post("/join", h.Join)
+			r.Post("/rejoin", h.Rejoin)
+			r.Post("/leave", h.Leave)
+			r.Get("/", h.Room)
+			r.Get("/events", h.Events)
+			r.Get("/ws", h.Socket)
+			r.Post("/cast", h.Cast)
+			r.Post("/clear", h.Clear)
+			r.Post("/reveal", h.Reveal)
+			r.Post("/reset", h.Reset)
+			r.Post("/label", h.Label)
+			r.Post("/facilitator", h.Facilitator)
+			r.Post("/release", h.Release)
+			r.Post("/lock", h.Lock)
+			r.Post("/stories", h.AddStory)
+			r.Post("/stories/{index}/remove", h.RemoveStory)
+			r.Post("/stories/{index}/move", h.MoveStory)
+			r.Post("/next", h.NextStory)
+		})
+
+		// Fallbacks for legacy mockup routes
+		r.Get("/lobby", h.Lobby) // expects roomID in data; will 404 without one
+		r.Get("/room", func(w http.ResponseWriter, r *http.Request) {
+			// Render the room template with empty values for mock view
+			data := map[string]any{
+				"RoomID":       "",
+				"Title":        "",
+				"Participants": []any{},
+				"Observers":    []any{},
+				"IsObserver":   false,
+				"Joined":       true,
+				"Total":        0,
+				"Voted":        0,
+				"Deck":         []string{},
+				"Revealed":     false,
+				"Label":        "",
+				"History":      []any{},
+				"Stats":        nil,
+				"Facilitator":  true,
+				"RecoveryCode": "",
+				"RecoveryLink": "",
+				"Locked":       false,
+				"Stories":      []any{},
+				"HasStory":     false,
+				"Suggested":    "",
+			}
+			_ = h.r.Render(w, r, "room", data)
+		})
 	})
 
-	// Fallbacks for legacy mockup routes
-	r.Get("/lobby", h.Lobby) // expects roomID in data; will 404 without one
-	r.Get("/room", func(w http.ResponseWriter, r *http.Request) {
-		// Render the room template with empty values for mock view
-		data := map[string]any{
-			"RoomID":       "",
-			"Participants": []any{},
-			"Total":        0,
-			"Voted":        0,
-			"Deck":         []string{},
-			"Revealed":     false,
+	// JSON API
+	r.Route("/api/v1", func(r chi.Router) {
+		r.Post("/rooms", h.APICreateRoom)
+		r.Route("/rooms/{roomID}", func(r chi.Router) {
+			r.Get("/", h.APIRoom)
+			r.Post("/participants", h.APIJoin)
+			r.Delete("/participants/me", h.APILeave)
+			r.Put("/vote", h.APICast)
+			r.Delete("/vote", h.APIClear)
+			r.Post("/reveal", h.APIReveal)
+			r.Post("/reset", h.APIReset)
+			r.Post("/rejoin", h.APIRejoin)
+			r.Put("/label", h.APILabel)
+			r.Put("/facilitator", h.APIFacilitator)
+			r.Post("/release", h.APIRelease)
+			r.Put("/lock", h.APILock)
+			r.Post("/stories", h.APIAddStory)
+			r.Delete("/stories/{index}", h.APIRemoveStory)
+			r.Post("/stories/{index}/move", h.APIMoveStory)
+			r.Post("/next", h.APINextStory)
+		})
+		if cfg.admin != "" {
+			r.Route("/admin", func(r chi.Router) {
+				r.Use(requireToken(cfg.admin))
+				r.Get("/rooms", h.AdminRooms)
+				r.Delete("/rooms/{roomID}", h.AdminCloseRoom)
+			})
 		}
-		_ = h.r.Render(w, "room", data)
 	})
 
 	return r
 }
+
+// clientAddr names the client of every request to the app layer by its IP
+// address (app.WithClient), which limits on failed attempts are kept per.
+// Behind a reverse proxy, clients share the proxy's address.
+func clientAddr(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+		next.ServeHTTP(w, r.WithContext(app.WithClient(r.Context(), host)))
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/jaminalder/estimations/internal/app"
 	templates "github.com/jaminalder/estimations/web/templates"
@@ -46,22 +47,53 @@ func NewRenderer() (*Renderer, error) {
 	return &Renderer{pages: pages}, nil
 }
 
-func (r *Renderer) Render(w http.ResponseWriter, page string, data any) error {
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+// pageMeta is embedded in the data of every page; the Renderer fills it in
+// from the request. Pass a pointer to the data so it can.
+type pageMeta struct {
+	CSRFToken string // for the hidden field of every form ({{ template "csrf" $ }})
+}
+
+func (m *pageMeta) meta() *pageMeta { return m }
+
+// Render renders a page for the request r.
+func (r *Renderer) Render(w http.ResponseWriter, req *http.Request, page string, data any) error {
+	return r.RenderStatus(w, req, http.StatusOK, page, data)
+}
+
+// RenderStatus renders a page with the given status code, e.g. a form
+// re-rendered with an error message. Page data embedding pageMeta (or a
+// map[string]any) gets the request's CSRF token.
+func (r *Renderer) RenderStatus(w http.ResponseWriter, req *http.Request, status int, page string, data any) error {
 	tpl, ok := r.pages[page]
 	if !ok {
 		http.Error(w, "template not found", http.StatusNotFound)
 		return nil
 	}
+	switch d := data.(type) {
+	case interface{ meta() *pageMeta }:
+		d.meta().CSRFToken = csrfToken(req)
+	case map[string]any:
+		d["CSRFToken"] = csrfToken(req)
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
 	return tpl.ExecuteTemplate(w, page, data)
 }
 
 // Handler bundles dependencies for request handlers.
 type Handler struct {
-	svc *app.Service
-	r   *Renderer
+	svc       *app.Service
+	r         *Renderer
+	events    EventSource
+	heartbeat time.Duration
+	sessions  sessionKeys
+	baseURL   string // public URL without trailing slash; empty for relative links
 }
 
+// absURL returns the link to path (absolute, starting with "/"), under the
+// public base URL when one is set.
+func (h *Handler) absURL(path string) string { return h.baseURL + path }
+
 // NewServer wires routes using chi and returns an http.Handler.
 func NewServer(svc *app.Service, r *Renderer, opts ...Option) http.Handler {
 	h := &Handler{svc: svc, r: r}
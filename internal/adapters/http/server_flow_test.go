@@ -1,10 +1,12 @@
 package httpadapter
 
 import (
+	"context"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"regexp"
 	"strings"
 	"testing"
@@ -14,7 +16,30 @@ import (
 	"github.com/jaminalder/estimations/internal/app"
 )
 
-func newTestServer(t *testing.T, logOut io.Writer) http.Handler {
+// newTestServer returns the server as a same-site browser sees it (see
+// browser); forged requests need newTestHandler.
+func newTestServer(t *testing.T, logOut io.Writer, opts ...Option) http.Handler {
+	t.Helper()
+	return browser{newTestHandler(t, logOut, opts...)}
+}
+
+// browser submits forms like a browser on a page the server rendered: its
+// unsafe requests carry a matching CSRF cookie and token, unless they bring
+// their own token.
+type browser struct{ srv http.Handler }
+
+// testCSRFToken is the browser's CSRF token.
+const testCSRFToken = "dGVzdC1jc3JmLXRva2VuLXRoaXJ0eS10d28tYnl0ZSE"
+
+func (b browser) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Header.Get(csrfHeader) == "" {
+		r.AddCookie(&http.Cookie{Name: csrfCookie, Value: testCSRFToken})
+		r.Header.Set(csrfHeader, testCSRFToken)
+	}
+	b.srv.ServeHTTP(w, r)
+}
+
+func newTestHandler(t *testing.T, logOut io.Writer, opts ...Option) http.Handler {
 	t.Helper()
 	r, err := NewRenderer()
 	if err != nil {
@@ -23,7 +48,7 @@ func newTestServer(t *testing.T, logOut io.Writer) http.Handler {
 	repo := memory.NewRoomRepo()
 	ids := idgen.NewRandom(10, 8)
 	svc := &app.Service{Rooms: repo, Ids: ids}
-	return NewServer(svc, r, WithLogger(log.New(logOut, "", 0)))
+	return NewServer(svc, r, append([]Option{WithLogger(slog.New(slog.NewTextHandler(logOut, nil)))}, opts...)...)
 }
 
 func TestLanding_ShowsCreateForm(t *testing.T) {
@@ -43,6 +68,40 @@ func TestLanding_ShowsCreateForm(t *testing.T) {
 	}
 }
 
+func TestJoinCode_FindsRoomAsTyped(t *testing.T) {
+	r, err := NewRenderer()
+	if err != nil {
+		t.Fatalf("renderer: %v", err)
+	}
+	repo := memory.NewRoomRepo()
+	svc := &app.Service{Rooms: repo, Ids: idgen.NewCodes(repo, idgen.DefaultCodeLen, 8)}
+	srv := browser{NewServer(svc, r)}
+	get := func(url string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, httptest.NewRequest("GET", url, nil))
+		return rec
+	}
+
+	if body := get("/").Body.String(); !strings.Contains(body, `action="/join"`) || !strings.Contains(body, `name="code"`) {
+		t.Fatalf("landing should have a join-by-code form")
+	}
+	id, err := svc.CreateRoom(context.Background())
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	// Read out and typed in: lower case, spaced, with O for 0 and l for 1.
+	typed := strings.NewReplacer("0", "O", "1", "l").Replace(strings.ToLower(string(id[:3]) + " " + string(id[3:])))
+	rec := get("/join?code=" + url.QueryEscape(typed))
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/rooms/"+string(id)+"/lobby" {
+		t.Fatalf("join %q: got %d to %q, want 303 to the lobby of %s", typed, rec.Code, rec.Header().Get("Location"), id)
+	}
+
+	rec = get("/join?code=nope")
+	if rec.Code != http.StatusNotFound || !strings.Contains(rec.Body.String(), "no room with that code") || !strings.Contains(rec.Body.String(), `value="nope"`) {
+		t.Fatalf("unknown code: got %d, want 404 with the landing page and message", rec.Code)
+	}
+}
+
 func TestCreateRoom_RedirectsToLobby_AndLogs(t *testing.T) {
 	var logs strings.Builder
 	srv := newTestServer(t, &logs)
@@ -66,7 +125,7 @@ func TestCreateRoom_RedirectsToLobby_AndLogs(t *testing.T) {
 		t.Fatalf("unexpected redirect Location: %q", loc)
 	}
 	// Expect a log line with method, path, status
-	if !strings.Contains(logs.String(), "POST /rooms 303") {
+	if !strings.Contains(logs.String(), "method=POST path=/rooms status=303") {
 		t.Fatalf("logs should contain request line, got: %q", logs.String())
 	}
 
@@ -94,7 +153,7 @@ func TestLobby_UnknownRoom_404_AndLogs(t *testing.T) {
 	if rec.Code != http.StatusNotFound {
 		t.Fatalf("status: got %d want 404", rec.Code)
 	}
-	if !strings.Contains(logs.String(), "GET /rooms/unknown/lobby 404") {
+	if !strings.Contains(logs.String(), "method=GET path=/rooms/unknown/lobby status=404") {
 		t.Fatalf("logs should contain 404 entry, got: %q", logs.String())
 	}
 }
@@ -137,11 +196,8 @@ func TestLobby_Join_RedirectsToRoom_AndLogs(t *testing.T) {
 	if !regexp.MustCompile(`^/rooms/[A-Za-z0-9_-]{11,20}$`).MatchString(roomURL) {
 		t.Fatalf("unexpected room redirect: %q", roomURL)
 	}
-	if !strings.Contains(logs.String(), "POST "+joinURL+" 303") {
-		// Allow either absolute path or exact match; fallback check for method and 303
-		if !strings.Contains(logs.String(), "POST /rooms/") || !strings.Contains(logs.String(), " 303 ") {
-			t.Fatalf("logs should include join POST, got: %q", logs.String())
-		}
+	if !strings.Contains(logs.String(), "method=POST path="+joinURL+" status=303") {
+		t.Fatalf("logs should include join POST, got: %q", logs.String())
 	}
 
 	// Follow to room page
@@ -246,3 +302,786 @@ func TestRoom_CastClearFlow_WithPidCookie(t *testing.T) {
 		t.Fatalf("expected after clear 0/1, got: %q", rec7.Body.String())
 	}
 }
+
+func TestCreateRoom_WithDeckChoice(t *testing.T) {
+	var logs strings.Builder
+	srv := newTestServer(t, &logs)
+
+	// Landing offers the presets and a custom option
+	recL := httptest.NewRecorder()
+	srv.ServeHTTP(recL, httptest.NewRequest("GET", "/", nil))
+	for _, sub := range []string{`name="deck"`, `value="tshirt"`, `value="custom"`, `name="cards"`} {
+		if !strings.Contains(recL.Body.String(), sub) {
+			t.Fatalf("landing should contain %q", sub)
+		}
+	}
+
+	// Create with the t-shirt deck, join, and check the room renders its cards
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/rooms", strings.NewReader("title=Epics&deck=tshirt"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	srv.ServeHTTP(rec, req)
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("create status: %d", rec.Code)
+	}
+	lobby := rec.Header().Get("Location")
+	rec2 := httptest.NewRecorder()
+	req2 := httptest.NewRequest("POST", strings.Replace(lobby, "/lobby", "/join", 1), strings.NewReader("name=Bob"))
+	req2.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	srv.ServeHTTP(rec2, req2)
+	roomURL := rec2.Header().Get("Location")
+	cookie := rec2.Header().Get("Set-Cookie")
+
+	rec3 := httptest.NewRecorder()
+	req3 := httptest.NewRequest("GET", roomURL, nil)
+	req3.Header.Set("Cookie", cookie)
+	srv.ServeHTTP(rec3, req3)
+	if !strings.Contains(rec3.Body.String(), `value="XXL"`) || strings.Contains(rec3.Body.String(), `value="13"`) {
+		t.Fatalf("room should render the t-shirt deck, got: %q", rec3.Body.String())
+	}
+
+	// A card from another deck is rejected
+	rec4 := httptest.NewRecorder()
+	req4 := httptest.NewRequest("POST", roomURL+"/cast", strings.NewReader("card=13"))
+	req4.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req4.Header.Set("Cookie", cookie)
+	srv.ServeHTTP(rec4, req4)
+	if rec4.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("cast outside deck: got %d want 422", rec4.Code)
+	}
+}
+
+func TestCreateRoom_CustomDeck_Validation(t *testing.T) {
+	var logs strings.Builder
+	srv := newTestServer(t, &logs)
+
+	post := func(body string) int {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/rooms", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		srv.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	if code := post("deck=custom&cards=1,+2,+4,+?"); code != http.StatusSeeOther {
+		t.Fatalf("valid custom deck: got %d want 303", code)
+	}
+	if code := post("deck=custom&cards=1,2,2"); code != http.StatusBadRequest {
+		t.Fatalf("duplicate cards: got %d want 400", code)
+	}
+	if code := post("deck=bogus"); code != http.StatusBadRequest {
+		t.Fatalf("unknown preset: got %d want 400", code)
+	}
+}
+
+func TestRoom_LabelRevealReset_ShowsHistory(t *testing.T) {
+	var logs strings.Builder
+	srv := newTestServer(t, &logs)
+
+	post := func(url, body, cookie string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if cookie != "" {
+			req.Header.Set("Cookie", cookie)
+		}
+		srv.ServeHTTP(rec, req)
+		return rec
+	}
+
+	lobby := post("/rooms", "title=Sprint", "").Header().Get("Location")
+	join := post(strings.Replace(lobby, "/lobby", "/join", 1), "name=Bob", "")
+	roomURL := join.Header().Get("Location")
+	cookie := join.Header().Get("Set-Cookie")
+
+	if rec := post(roomURL+"/label", "label=Login+page", cookie); rec.Code != http.StatusSeeOther {
+		t.Fatalf("label status: %d", rec.Code)
+	}
+	post(roomURL+"/cast", "card=5", cookie)
+	post(roomURL+"/reveal", "", cookie)
+	if rec := post(roomURL+"/reset", "", cookie); rec.Code != http.StatusSeeOther {
+		t.Fatalf("reset status: %d", rec.Code)
+	}
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", roomURL, nil)
+	req.Header.Set("Cookie", cookie)
+	srv.ServeHTTP(rec, req)
+	body := rec.Body.String()
+	if !strings.Contains(body, "Previous Rounds") || !strings.Contains(body, "Login page") || !strings.Contains(body, "Bob: <strong>5</strong>") {
+		t.Fatalf("room should list the archived round, got: %q", body)
+	}
+
+	// Label without a participant cookie is rejected
+	if rec := post(roomURL+"/label", "label=x", ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("label without cookie: got %d want 401", rec.Code)
+	}
+}
+
+func TestRoom_Revealed_ShowsStats(t *testing.T) {
+	var logs strings.Builder
+	srv := newTestServer(t, &logs)
+
+	post := func(url, body, cookie string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if cookie != "" {
+			req.Header.Set("Cookie", cookie)
+		}
+		srv.ServeHTTP(rec, req)
+		return rec
+	}
+
+	lobby := post("/rooms", "", "").Header().Get("Location")
+	joinURL := strings.Replace(lobby, "/lobby", "/join", 1)
+	alice := post(joinURL, "name=Alice", "")
+	bob := post(joinURL, "name=Bob", "")
+	roomURL := alice.Header().Get("Location")
+	post(roomURL+"/cast", "card=3", alice.Header().Get("Set-Cookie"))
+	post(roomURL+"/cast", "card=8", bob.Header().Get("Set-Cookie"))
+
+	get := func() string {
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, httptest.NewRequest("GET", roomURL, nil))
+		return rec.Body.String()
+	}
+	if strings.Contains(get(), `id="stats"`) {
+		t.Fatalf("stats must stay hidden while voting")
+	}
+
+	post(roomURL+"/reveal", "", alice.Header().Get("Set-Cookie"))
+	body := get()
+	for _, sub := range []string{"Votes Revealed", `id="stats"`, "Average", "5.5", "Suggested"} {
+		if !strings.Contains(body, sub) {
+			t.Fatalf("revealed view should contain %q, got: %q", sub, body)
+		}
+	}
+}
+
+func TestRoom_FacilitatorPermissions(t *testing.T) {
+	var logs strings.Builder
+	srv := newTestServer(t, &logs)
+
+	post := func(url, body, cookie string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if cookie != "" {
+			req.Header.Set("Cookie", cookie)
+		}
+		srv.ServeHTTP(rec, req)
+		return rec
+	}
+	get := func(url, cookie string) string {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", url, nil)
+		req.Header.Set("Cookie", cookie)
+		srv.ServeHTTP(rec, req)
+		return rec.Body.String()
+	}
+
+	lobby := post("/rooms", "", "").Header().Get("Location")
+	joinURL := strings.Replace(lobby, "/lobby", "/join", 1)
+	aliceRec := post(joinURL, "name=Alice", "")
+	roomURL := aliceRec.Header().Get("Location")
+	alice := aliceRec.Header().Get("Set-Cookie")
+	bob := post(joinURL, "name=Bob", "").Header().Get("Set-Cookie")
+	post(roomURL+"/cast", "card=5", bob)
+
+	// Only the facilitator (first joiner) sees reveal/reset
+	if !strings.Contains(get(roomURL, alice), "Reveal Cards") {
+		t.Fatalf("facilitator should see reveal button")
+	}
+	if strings.Contains(get(roomURL, bob), "Reveal Cards") {
+		t.Fatalf("non-facilitator must not see reveal button")
+	}
+
+	// Non-facilitator commands are forbidden; anonymous ones unauthorized
+	if rec := post(roomURL+"/reveal", "", bob); rec.Code != http.StatusForbidden {
+		t.Fatalf("reveal by non-facilitator: got %d want 403", rec.Code)
+	}
+	if rec := post(roomURL+"/reset", "", bob); rec.Code != http.StatusForbidden {
+		t.Fatalf("reset by non-facilitator: got %d want 403", rec.Code)
+	}
+	if rec := post(roomURL+"/reveal", "", ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("reveal without cookie: got %d want 401", rec.Code)
+	}
+	// Naming someone tells a non-facilitator nothing about who is in the room.
+	for _, name := range []string{"Alice", "Nobody"} {
+		for _, action := range []string{"/facilitator", "/release"} {
+			rec := post(roomURL+action, "name="+name, bob)
+			if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "Only the facilitator") {
+				t.Fatalf("%s %s by non-facilitator: got %d", action, name, rec.Code)
+			}
+		}
+	}
+
+	// Hand over to Bob, who can now reveal
+	if rec := post(roomURL+"/facilitator", "name=Bob", alice); rec.Code != http.StatusSeeOther {
+		t.Fatalf("transfer: got %d want 303", rec.Code)
+	}
+	if rec := post(roomURL+"/reveal", "", alice); rec.Code != http.StatusForbidden {
+		t.Fatalf("former facilitator reveal: got %d want 403", rec.Code)
+	}
+	if rec := post(roomURL+"/reveal", "", bob); rec.Code != http.StatusSeeOther {
+		t.Fatalf("new facilitator reveal: got %d want 303", rec.Code)
+	}
+}
+
+func TestRoom_ObserverJoin_ListedSeparately(t *testing.T) {
+	var logs strings.Builder
+	srv := newTestServer(t, &logs)
+
+	post := func(url, body, cookie string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if cookie != "" {
+			req.Header.Set("Cookie", cookie)
+		}
+		srv.ServeHTTP(rec, req)
+		return rec
+	}
+	get := func(url, cookie string) string {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", url, nil)
+		req.Header.Set("Cookie", cookie)
+		srv.ServeHTTP(rec, req)
+		return rec.Body.String()
+	}
+
+	lobby := post("/rooms", "", "").Header().Get("Location")
+	if !strings.Contains(get(lobby, ""), `value="observer"`) {
+		t.Fatalf("lobby should offer the observer role")
+	}
+	joinURL := strings.Replace(lobby, "/lobby", "/join", 1)
+	aliceRec := post(joinURL, "name=Alice&role=voter", "")
+	roomURL := aliceRec.Header().Get("Location")
+	olivia := post(joinURL, "name=Olivia&role=observer", "").Header().Get("Set-Cookie")
+
+	body := get(roomURL, olivia)
+	if !strings.Contains(body, "0 of 1 players have voted") {
+		t.Fatalf("observers must not count as players, got: %q", body)
+	}
+	if !strings.Contains(body, "Watching:") || !strings.Contains(body, "Olivia") {
+		t.Fatalf("observer should be listed separately, got: %q", body)
+	}
+	if strings.Contains(body, "Select Your Estimate") {
+		t.Fatalf("observer should not see the deck")
+	}
+	if rec := post(roomURL+"/cast", "card=5", olivia); rec.Code != http.StatusForbidden {
+		t.Fatalf("observer cast: got %d want 403", rec.Code)
+	}
+}
+
+func TestRoom_StoryBacklog_NextStory(t *testing.T) {
+	var logs strings.Builder
+	srv := newTestServer(t, &logs)
+
+	post := func(url, body, cookie string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if cookie != "" {
+			req.Header.Set("Cookie", cookie)
+		}
+		srv.ServeHTTP(rec, req)
+		return rec
+	}
+	get := func(url, cookie string) string {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", url, nil)
+		req.Header.Set("Cookie", cookie)
+		srv.ServeHTTP(rec, req)
+		return rec.Body.String()
+	}
+
+	lobby := post("/rooms", "title=Sprint+12", "").Header().Get("Location")
+	joinURL := strings.Replace(lobby, "/lobby", "/join", 1)
+	aliceRec := post(joinURL, "name=Alice", "")
+	roomURL := aliceRec.Header().Get("Location")
+	alice := aliceRec.Header().Get("Set-Cookie")
+	bob := post(joinURL, "name=Bob", "").Header().Get("Set-Cookie")
+
+	if body := get(roomURL, alice); !strings.Contains(body, "Sprint 12") {
+		t.Fatalf("room should show the session title, got: %q", body)
+	}
+
+	for _, form := range []string{"title=Login&key=PROJ-1&link=https%3A%2F%2Fexample.com%2FPROJ-1", "title=Logout", "title=Signup"} {
+		if rec := post(roomURL+"/stories", form, alice); rec.Code != http.StatusSeeOther {
+			t.Fatalf("add story: got %d want 303", rec.Code)
+		}
+	}
+	if rec := post(roomURL+"/stories", "title=Nope", bob); rec.Code != http.StatusForbidden {
+		t.Fatalf("add story by non-facilitator: got %d want 403", rec.Code)
+	}
+	if rec := post(roomURL+"/stories", "title=Bad&link=ftp%3A%2F%2Fx", alice); rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("add story with bad link: got %d want 422", rec.Code)
+	}
+	if rec := post(roomURL+"/stories/2/move", "to=1", alice); rec.Code != http.StatusSeeOther {
+		t.Fatalf("move story: got %d want 303", rec.Code)
+	}
+	if rec := post(roomURL+"/stories/2/remove", "", alice); rec.Code != http.StatusSeeOther {
+		t.Fatalf("remove story: got %d want 303", rec.Code)
+	}
+
+	body := get(roomURL, alice)
+	if !strings.Contains(body, `href="https://example.com/PROJ-1"`) || !strings.Contains(body, "Signup") || strings.Contains(body, "Logout") {
+		t.Fatalf("backlog should list Login and Signup only, got: %q", body)
+	}
+	if !strings.Contains(body, "PROJ-1 Login") || !strings.Contains(body, "Next Story") {
+		t.Fatalf("current story should label the round and offer next story, got: %q", body)
+	}
+
+	post(roomURL+"/cast", "card=5", alice)
+	post(roomURL+"/cast", "card=5", bob)
+	post(roomURL+"/reveal", "", alice)
+	if body := get(roomURL, alice); !strings.Contains(body, `<option value="5" selected>`) {
+		t.Fatalf("next story should preselect the suggested estimate, got: %q", body)
+	}
+	if rec := post(roomURL+"/next", "estimate=5", bob); rec.Code != http.StatusForbidden {
+		t.Fatalf("next by non-facilitator: got %d want 403", rec.Code)
+	}
+	if rec := post(roomURL+"/next", "estimate=5", alice); rec.Code != http.StatusSeeOther {
+		t.Fatalf("next story: got %d want 303", rec.Code)
+	}
+
+	body = get(roomURL, bob)
+	if !strings.Contains(body, "Voting in Progress") || !strings.Contains(body, `<p class="subtitle is-5 mb-2">Signup</p>`) {
+		t.Fatalf("round should move on to Signup, got: %q", body)
+	}
+	if !strings.Contains(body, "<td>5</td>") {
+		t.Fatalf("history should record the agreed estimate, got: %q", body)
+	}
+}
+
+func TestErrors_ReRenderWithMessage(t *testing.T) {
+	var logs strings.Builder
+	srv := newTestServer(t, &logs)
+
+	post := func(url, body, cookie string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if cookie != "" {
+			req.Header.Set("Cookie", cookie)
+		}
+		srv.ServeHTTP(rec, req)
+		return rec
+	}
+
+	lobby := post("/rooms", "", "").Header().Get("Location")
+	joinURL := strings.Replace(lobby, "/lobby", "/join", 1)
+	aliceRec := post(joinURL, "name=Alice", "")
+	roomURL := aliceRec.Header().Get("Location")
+	alice := aliceRec.Header().Get("Set-Cookie")
+
+	// A taken name re-renders the lobby with the reason and the entered name.
+	rec := post(joinURL, "name=alice&role=observer", "")
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("duplicate name: got %d want 409", rec.Code)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "That name is already taken in this room.") || !strings.Contains(body, `value="alice"`) {
+		t.Fatalf("lobby should show the error and keep the name, got: %q", body)
+	}
+	if !strings.Contains(body, `value="observer" checked`) {
+		t.Fatalf("lobby should keep the chosen role, got: %q", body)
+	}
+	if rec := post(joinURL, "name=+", ""); rec.Code != http.StatusUnprocessableEntity || !strings.Contains(rec.Body.String(), "Please enter a name.") {
+		t.Fatalf("empty name: got %d want 422", rec.Code)
+	}
+	if rec := post("/rooms/nope/join", "name=Bob", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("join unknown room: got %d want 404", rec.Code)
+	}
+
+	// A rejected command re-renders the room with the reason.
+	rec = post(roomURL+"/reveal", "", alice)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("reveal without votes: got %d want 409", rec.Code)
+	}
+	if body := rec.Body.String(); !strings.Contains(body, "Nobody has voted yet.") || !strings.Contains(body, "Reveal Cards") {
+		t.Fatalf("room should show the error, got: %q", body)
+	}
+	rec = post(roomURL+"/label", "label="+strings.Repeat("x", 121), alice)
+	if rec.Code != http.StatusUnprocessableEntity || !strings.Contains(rec.Body.String(), "Invalid label: longer than 120 characters.") {
+		t.Fatalf("long label: got %d: %q", rec.Code, rec.Body)
+	}
+	if rec := post(roomURL+"/facilitator", "name=Nobody", alice); rec.Code != http.StatusForbidden {
+		t.Fatalf("transfer to unknown name: got %d want 403", rec.Code)
+	}
+}
+
+func TestRoom_Leave_ClearsCookie_FreesName(t *testing.T) {
+	var logs strings.Builder
+	srv := newTestServer(t, &logs)
+
+	post := func(url, body, cookie string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if cookie != "" {
+			req.Header.Set("Cookie", cookie)
+		}
+		srv.ServeHTTP(rec, req)
+		return rec
+	}
+	get := func(url, cookie string) string {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", url, nil)
+		req.Header.Set("Cookie", cookie)
+		srv.ServeHTTP(rec, req)
+		return rec.Body.String()
+	}
+
+	lobby := post("/rooms", "", "").Header().Get("Location")
+	joinURL := strings.Replace(lobby, "/lobby", "/join", 1)
+	aliceRec := post(joinURL, "name=Alice", "")
+	roomURL := aliceRec.Header().Get("Location")
+	alice := aliceRec.Header().Get("Set-Cookie")
+	bob := post(joinURL, "name=Bob", "").Header().Get("Set-Cookie")
+
+	if !strings.Contains(get(roomURL, bob), "Leave Room") {
+		t.Fatalf("participants should see the leave button")
+	}
+	rec := post(roomURL+"/leave", "", bob)
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != lobby {
+		t.Fatalf("leave: got %d to %q, want 303 to the lobby", rec.Code, rec.Header().Get("Location"))
+	}
+	if c := rec.Header().Get("Set-Cookie"); !strings.HasPrefix(c, "pid=;") || !strings.Contains(c, "Max-Age=0") {
+		t.Fatalf("leave should clear the pid cookie, got %q", c)
+	}
+	if strings.Contains(get(roomURL, alice), "Bob") {
+		t.Fatalf("bob should no longer be listed")
+	}
+	// Leaving again is harmless, and the name is free to join with.
+	if rec := post(roomURL+"/leave", "", bob); rec.Code != http.StatusSeeOther {
+		t.Fatalf("second leave: got %d want 303", rec.Code)
+	}
+	if rec := post(joinURL, "name=Bob", ""); rec.Code != http.StatusSeeOther {
+		t.Fatalf("rejoin with freed name: got %d want 303", rec.Code)
+	}
+}
+
+func TestRoom_Rejoin_TooManyWrongCodes(t *testing.T) {
+	srv := newTestServer(t, io.Discard)
+	lobby := postForm(srv, "/rooms", "", "").Header().Get("Location")
+	rejoinURL := strings.Replace(lobby, "/lobby", "/rejoin", 1)
+	postForm(srv, strings.Replace(lobby, "/lobby", "/join", 1), "name=Alice", "")
+
+	var rec *httptest.ResponseRecorder
+	for range 11 {
+		rec = postForm(srv, rejoinURL, "code=0000-0000", "")
+	}
+	if rec.Code != http.StatusTooManyRequests || !strings.Contains(rec.Body.String(), "Too many wrong codes") {
+		t.Fatalf("rejoin after many wrong codes: got %d want 429 with message", rec.Code)
+	}
+}
+
+func TestRoom_RejoinWithRecoveryCode_AndRelease(t *testing.T) {
+	srv := newTestServer(t, io.Discard)
+	get := func(url, cookie string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", url, nil)
+		req.Header.Set("Cookie", cookie)
+		srv.ServeHTTP(rec, req)
+		return rec
+	}
+
+	lobby := postForm(srv, "/rooms", "", "").Header().Get("Location")
+	joinURL := strings.Replace(lobby, "/lobby", "/join", 1)
+	aliceRec := postForm(srv, joinURL, "name=Alice", "")
+	roomURL := aliceRec.Header().Get("Location")
+	alice := aliceRec.Header().Get("Set-Cookie")
+	bob := postForm(srv, joinURL, "name=Bob", "").Header().Get("Set-Cookie")
+	postForm(srv, roomURL+"/cast", "card=5", bob)
+
+	// Bob's page shows his code; another browser can take over with it.
+	page := get(roomURL, bob).Body.String()
+	m := regexp.MustCompile(`<code>([0-9A-Z]{4}-[0-9A-Z]{4})</code>`).FindStringSubmatch(page)
+	if m == nil {
+		t.Fatalf("room page should show the recovery code:\n%s", page)
+	}
+	if body := get(lobby+"?code="+m[1], "").Body.String(); !strings.Contains(body, `value="`+m[1]+`"`) {
+		t.Fatalf("recovery link should prefill the code")
+	}
+	rec := postForm(srv, roomURL+"/rejoin", "code="+strings.ToLower(m[1]), "")
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != roomURL {
+		t.Fatalf("rejoin: got %d to %q, want 303 to the room", rec.Code, rec.Header().Get("Location"))
+	}
+	phone := rec.Header().Get("Set-Cookie")
+	if !strings.Contains(get(roomURL, phone).Body.String(), "Leave Room") {
+		t.Fatalf("rejoined browser should be a participant")
+	}
+	if rec := postForm(srv, roomURL+"/clear", "", phone); rec.Code != http.StatusSeeOther {
+		t.Fatalf("rejoined browser clearing bob's vote: got %d want 303", rec.Code)
+	}
+
+	rec = postForm(srv, roomURL+"/rejoin", "code=AAAA-AAAA", "")
+	if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "recovery code does not belong") {
+		t.Fatalf("wrong code: got %d want 403 with message", rec.Code)
+	}
+
+	// Only the facilitator can release a name; it frees it for joining.
+	if rec := postForm(srv, roomURL+"/release", "name=Alice", bob); rec.Code != http.StatusForbidden {
+		t.Fatalf("release by non-facilitator: got %d want 403", rec.Code)
+	}
+	if rec := postForm(srv, roomURL+"/release", "name=bob", alice); rec.Code != http.StatusSeeOther {
+		t.Fatalf("release: got %d want 303", rec.Code)
+	}
+	if rec := get(roomURL, bob); strings.Contains(rec.Body.String(), "Leave Room") {
+		t.Fatalf("released participant should no longer be in the room")
+	}
+	if rec := postForm(srv, joinURL, "name=Bob", ""); rec.Code != http.StatusSeeOther {
+		t.Fatalf("join with released name: got %d want 303", rec.Code)
+	}
+}
+
+func TestBaseURL_MakesSharedLinksAbsolute(t *testing.T) {
+	srv := newTestServer(t, io.Discard, WithBaseURL("https://poker.example.com/"))
+	lobby := postForm(srv, "/rooms", "", "").Header().Get("Location")
+	joined := postForm(srv, strings.Replace(lobby, "/lobby", "/join", 1), "name=Alice", "")
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", joined.Header().Get("Location"), nil)
+	req.Header.Set("Cookie", joined.Header().Get("Set-Cookie"))
+	srv.ServeHTTP(rec, req)
+	if !strings.Contains(rec.Body.String(), `href="https://poker.example.com`+lobby+`?code=`) {
+		t.Fatalf("the recovery link should be absolute:\n%s", rec.Body)
+	}
+
+	created := apiClient{t: t, srv: srv}.do("POST", "/api/v1/rooms", "", `{}`)
+	if loc := created.Header().Get("Location"); !strings.HasPrefix(loc, "https://poker.example.com/api/v1/rooms/") {
+		t.Fatalf("API Location should be absolute, got %q", loc)
+	}
+}
+
+func TestCreateRoom_CreatorBecomesFacilitator(t *testing.T) {
+	srv := newTestServer(t, io.Discard)
+	created := postForm(srv, "/rooms", "passcode=s3cret", "")
+	lobby := created.Header().Get("Location")
+	creator := created.Header().Get("Set-Cookie")
+	if !strings.HasPrefix(creator, "creator=") {
+		t.Fatalf("create should set the creator cookie, got %q", creator)
+	}
+	creator = strings.SplitN(creator, ";", 2)[0]
+	joinURL := strings.Replace(lobby, "/lobby", "/join", 1)
+
+	// Someone with the link gets in first and locks the room.
+	mallory := postForm(srv, joinURL, "name=Mallory&passcode=s3cret", "").Header().Get("Set-Cookie")
+	roomURL := strings.TrimSuffix(lobby, "/lobby")
+	if rec := postForm(srv, roomURL+"/lock", "locked=true", mallory); rec.Code != http.StatusSeeOther {
+		t.Fatalf("lock: got %d want 303", rec.Code)
+	}
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", lobby, nil)
+	req.Header.Set("Cookie", creator)
+	srv.ServeHTTP(rec, req)
+	if body := rec.Body.String(); !strings.Contains(body, "Enter Room") || strings.Contains(body, `name="passcode"`) {
+		t.Fatalf("lobby should let the creator in without passcode")
+	}
+	rec = postForm(srv, joinURL, "name=Alice", creator)
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("creator join: got %d want 303", rec.Code)
+	}
+	var alice string
+	for _, c := range rec.Result().Cookies() {
+		switch {
+		case c.Name == "pid":
+			alice = c.Name + "=" + c.Value
+		case c.Name == "creator" && c.MaxAge >= 0:
+			t.Fatalf("the creator cookie should be cleared once used")
+		}
+	}
+	if rec := postForm(srv, roomURL+"/lock", "locked=false", alice); rec.Code != http.StatusSeeOther {
+		t.Fatalf("unlock by creator: got %d want 303", rec.Code)
+	}
+	if rec := postForm(srv, roomURL+"/lock", "locked=true", mallory); rec.Code != http.StatusForbidden {
+		t.Fatalf("lock by first joiner after the creator came: got %d want 403", rec.Code)
+	}
+
+	// A copy of the creator cookie is spent: no second facilitator claim.
+	rec = postForm(srv, joinURL, "name=Eve", creator)
+	if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), `name="passcode"`) {
+		t.Fatalf("reused creator cookie: got %d, want 403 and the passcode form", rec.Code)
+	}
+}
+
+func TestRoom_PasscodeAndLock(t *testing.T) {
+	srv := newTestServer(t, io.Discard)
+	get := func(url, cookie string) string {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", url, nil)
+		req.Header.Set("Cookie", cookie)
+		srv.ServeHTTP(rec, req)
+		return rec.Body.String()
+	}
+
+	lobby := postForm(srv, "/rooms", "passcode=s3cret", "").Header().Get("Location")
+	joinURL := strings.Replace(lobby, "/lobby", "/join", 1)
+	if !strings.Contains(get(lobby, ""), `name="passcode"`) {
+		t.Fatalf("lobby of a room with a passcode should ask for it")
+	}
+	rec := postForm(srv, joinURL, "name=Mallory&passcode=guess", "")
+	if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "passcode is not right") {
+		t.Fatalf("wrong passcode: got %d want 403 with message", rec.Code)
+	}
+	aliceRec := postForm(srv, joinURL, "name=Alice&passcode=s3cret", "")
+	if aliceRec.Code != http.StatusSeeOther {
+		t.Fatalf("join with passcode: got %d want 303", aliceRec.Code)
+	}
+	roomURL := aliceRec.Header().Get("Location")
+	alice := aliceRec.Header().Get("Set-Cookie")
+	bob := postForm(srv, joinURL, "name=Bob&passcode=s3cret", "").Header().Get("Set-Cookie")
+
+	// Outsiders are sent to the lobby instead of seeing the room.
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest("GET", roomURL, nil))
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != lobby {
+		t.Fatalf("room page for outsider: got %d to %q want 303 to the lobby", rec.Code, rec.Header().Get("Location"))
+	}
+
+	if rec := postForm(srv, roomURL+"/lock", "locked=true", bob); rec.Code != http.StatusForbidden {
+		t.Fatalf("lock by non-facilitator: got %d want 403", rec.Code)
+	}
+	if rec := postForm(srv, roomURL+"/lock", "locked=true", alice); rec.Code != http.StatusSeeOther {
+		t.Fatalf("lock: got %d want 303", rec.Code)
+	}
+	if body := get(roomURL, alice); !strings.Contains(body, "Room locked") || !strings.Contains(body, "Unlock Room") {
+		t.Fatalf("room page should show the lock and the unlock button")
+	}
+	if body := get(lobby, ""); !strings.Contains(body, "This room is locked") || strings.Contains(body, "Enter Room") {
+		t.Fatalf("lobby of a locked room should turn newcomers away")
+	}
+	if rec := postForm(srv, joinURL, "name=Carol&passcode=s3cret", ""); rec.Code != http.StatusConflict {
+		t.Fatalf("join locked room: got %d want 409", rec.Code)
+	}
+	// Participants keep acting and can come back from another browser.
+	if rec := postForm(srv, roomURL+"/cast", "card=5", bob); rec.Code != http.StatusSeeOther {
+		t.Fatalf("vote in locked room: got %d want 303", rec.Code)
+	}
+	code := regexp.MustCompile(`<code>([0-9A-Z-]{9})</code>`).FindStringSubmatch(get(roomURL, bob))
+	if code == nil {
+		t.Fatalf("room page should show bob's recovery code")
+	}
+	if rec := postForm(srv, roomURL+"/rejoin", "code="+code[1], ""); rec.Code != http.StatusSeeOther {
+		t.Fatalf("rejoin locked room: got %d want 303", rec.Code)
+	}
+
+	if rec := postForm(srv, roomURL+"/lock", "locked=false", alice); rec.Code != http.StatusSeeOther {
+		t.Fatalf("unlock: got %d want 303", rec.Code)
+	}
+	if rec := postForm(srv, joinURL, "name=Carol&passcode=s3cret", ""); rec.Code != http.StatusSeeOther {
+		t.Fatalf("join unlocked room: got %d want 303", rec.Code)
+	}
+}
+
+func TestCSRF_FormsCarryToken(t *testing.T) {
+	raw := newTestHandler(t, io.Discard)
+
+	// A fresh browser gets a token cookie, and the page's form carries it.
+	rec := httptest.NewRecorder()
+	raw.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	var cookie *http.Cookie
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == csrfCookie {
+			cookie = c
+		}
+	}
+	if cookie == nil || !cookie.HttpOnly || cookie.SameSite != http.SameSiteLaxMode {
+		t.Fatalf("expected an HttpOnly, SameSite=Lax csrf cookie, got %+v", cookie)
+	}
+	field := regexp.MustCompile(`name="csrf_token" value="([^"]+)"`).FindStringSubmatch(rec.Body.String())
+	if field == nil || field[1] != cookie.Value {
+		t.Fatalf("form token %q does not match cookie %q", field, cookie.Value)
+	}
+
+	// Submitting the form as rendered works.
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/rooms", strings.NewReader(url.Values{csrfField: {field[1]}}.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.AddCookie(cookie)
+	raw.ServeHTTP(rec, req)
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("create with token: got %d want 303", rec.Code)
+	}
+
+	// Every form on the room page carries the token.
+	srv := newTestServer(t, io.Discard)
+	lobby := postForm(srv, "/rooms", "", "").Header().Get("Location")
+	join := postForm(srv, strings.Replace(lobby, "/lobby", "/join", 1), "name=Alice", "")
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", join.Header().Get("Location"), nil)
+	req.Header.Set("Cookie", join.Header().Get("Set-Cookie"))
+	srv.ServeHTTP(rec, req)
+	body := rec.Body.String()
+	forms, tokens := strings.Count(body, "<form"), strings.Count(body, `name="csrf_token" value="`)
+	if forms == 0 || tokens != forms || strings.Contains(body, `name="csrf_token" value=""`) {
+		t.Fatalf("%d forms but %d tokens:\n%s", forms, tokens, body)
+	}
+}
+
+func TestCSRF_ForgedPostsRejected(t *testing.T) {
+	srv := newTestServer(t, io.Discard)
+	raw := srv.(browser).srv
+	lobby := postForm(srv, "/rooms", "", "").Header().Get("Location")
+	join := postForm(srv, strings.Replace(lobby, "/lobby", "/join", 1), "name=Alice", "")
+	roomURL := join.Header().Get("Location")
+	pid := join.Header().Get("Set-Cookie")
+	postForm(srv, roomURL+"/cast", "card=5", pid)
+
+	// A page on another site can make the browser post with its cookies, but
+	// cannot read the token to put in the form.
+	forge := func(path, body, token string) int {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", path, strings.NewReader(body+"&csrf_token="+url.QueryEscape(token)))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Cookie", pid)
+		req.AddCookie(&http.Cookie{Name: csrfCookie, Value: testCSRFToken})
+		raw.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	for _, tc := range []struct{ path, body, token string }{
+		{roomURL + "/reveal", "", ""},
+		{roomURL + "/reset", "", "guess"},
+		{roomURL + "/cast", "card=8", newCSRFToken()},
+		{roomURL + "/leave", "", ""},
+		{"/rooms", "title=Spam", ""},
+		{strings.Replace(lobby, "/lobby", "/join", 1), "name=Mallory", ""},
+	} {
+		if code := forge(tc.path, tc.body, tc.token); code != http.StatusForbidden {
+			t.Errorf("forged post to %s: got %d want 403", tc.path, code)
+		}
+	}
+
+	// A token without its cookie is no better.
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", roomURL+"/reveal", strings.NewReader("csrf_token="+testCSRFToken))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Cookie", pid)
+	raw.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("token without cookie: got %d want 403", rec.Code)
+	}
+
+	// Nothing happened to the room.
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", roomURL, nil)
+	req.Header.Set("Cookie", pid)
+	srv.ServeHTTP(rec, req)
+	if body := rec.Body.String(); !strings.Contains(body, "1 of 1 players have voted") || !strings.Contains(body, "Reveal Cards") || strings.Contains(body, "Mallory") {
+		t.Fatalf("forged posts changed the room:\n%s", body)
+	}
+}
+
+// postForm posts a form body through srv with an optional cookie header.
+func postForm(srv http.Handler, path, body, cookie string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if cookie != "" {
+		req.Header.Set("Cookie", cookie)
+	}
+	srv.ServeHTTP(rec, req)
+	return rec
+}
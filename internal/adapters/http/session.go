@@ -0,0 +1,187 @@
+package httpadapter
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/jaminalder/estimations/internal/domain"
+)
+
+// A browser is a room's participant while it holds the room's pid cookie,
+// scoped to the room's path. The cookie is "<participant ID>.<MAC>", where
+// the MAC is an HMAC-SHA256 over the room and participant IDs under a server
+// session key: cookies cannot be forged from a participant ID, nor moved to
+// another room. Keys rotate by listing the new key first (it signs) and
+// keeping old ones (they still verify) until their cookies are gone. API
+// clients get the same value as their bearer token, so a participant ID on
+// its own (say, from the event log) authenticates nowhere.
+//
+// Whoever creates a room gets a creator claim, a MAC over the room ID alone,
+// in the creator cookie or as the API's creator token. Joining with it makes
+// them facilitator (app.AsCreator), so a visitor who got the link first
+// cannot keep the role.
+
+// sessionKeys are the keys session cookies are signed with, newest first.
+type sessionKeys [][]byte
+
+// newSessionKey returns a random key, for servers without configured keys;
+// their cookies do not survive a restart.
+func newSessionKey() []byte {
+	key := make([]byte, 32)
+	_, _ = rand.Read(key) // never fails
+	return key
+}
+
+// sign returns the cookie value naming pid as a participant of the room.
+func (k sessionKeys) sign(roomID domain.RoomID, pid domain.ParticipantID) string {
+	return string(pid) + "." + base64.RawURLEncoding.EncodeToString(sessionMAC(k[0], roomID, pid))
+}
+
+// verify returns the participant a cookie value names in the room, and false
+// if no key signed it for that room.
+func (k sessionKeys) verify(roomID domain.RoomID, value string) (domain.ParticipantID, bool) {
+	i := strings.LastIndexByte(value, '.')
+	if i <= 0 {
+		return "", false
+	}
+	pid := domain.ParticipantID(value[:i])
+	mac, err := base64.RawURLEncoding.DecodeString(value[i+1:])
+	if err != nil {
+		return "", false
+	}
+	for _, key := range k {
+		if hmac.Equal(mac, sessionMAC(key, roomID, pid)) {
+			return pid, true
+		}
+	}
+	return "", false
+}
+
+// signCreator returns the creator claim for the room.
+func (k sessionKeys) signCreator(roomID domain.RoomID) string {
+	return base64.RawURLEncoding.EncodeToString(creatorMAC(k[0], roomID))
+}
+
+// verifyCreator reports whether value is a creator claim for the room.
+func (k sessionKeys) verifyCreator(roomID domain.RoomID, value string) bool {
+	mac, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || value == "" {
+		return false
+	}
+	for _, key := range k {
+		if hmac.Equal(mac, creatorMAC(key, roomID)) {
+			return true
+		}
+	}
+	return false
+}
+
+// creatorMAC signs a message starting with a zero byte, which no session
+// MAC's does (room IDs are never empty), so neither passes for the other.
+func creatorMAC(key []byte, roomID domain.RoomID) []byte {
+	m := hmac.New(sha256.New, key)
+	m.Write([]byte("\x00creator\x00"))
+	m.Write([]byte(roomID))
+	return m.Sum(nil)
+}
+
+func sessionMAC(key []byte, roomID domain.RoomID, pid domain.ParticipantID) []byte {
+	m := hmac.New(sha256.New, key)
+	m.Write([]byte(roomID))
+	m.Write([]byte{0})
+	m.Write([]byte(pid))
+	return m.Sum(nil)
+}
+
+// readPID returns the participant the request's pid cookie names in the
+// request's room, or "" if there is no validly signed cookie.
+func (h *Handler) readPID(r *http.Request) string {
+	c, err := r.Cookie("pid")
+	if err != nil {
+		return ""
+	}
+	pid, ok := h.sessions.verify(domain.RoomID(chi.URLParam(r, "roomID")), strings.TrimSpace(c.Value))
+	if !ok {
+		return ""
+	}
+	return string(pid)
+}
+
+// bearerPID returns the participant the request's bearer token names in the
+// room, or "" if there is no validly signed token.
+func (h *Handler) bearerPID(r *http.Request, roomID domain.RoomID) domain.ParticipantID {
+	pid, ok := h.sessions.verify(roomID, bearerToken(r))
+	if !ok {
+		return ""
+	}
+	return pid
+}
+
+// setPID makes the browser the participant pid of the room.
+func (h *Handler) setPID(w http.ResponseWriter, r *http.Request, roomID string, pid domain.ParticipantID) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     "pid",
+		Value:    h.sessions.sign(domain.RoomID(roomID), pid),
+		Path:     "/rooms/" + roomID,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// clearPID removes the browser's pid cookie for the room.
+func clearPID(w http.ResponseWriter, roomID string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     "pid",
+		Value:    "",
+		Path:     "/rooms/" + roomID,
+		MaxAge:   -1,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// isCreator reports whether the browser holds the room's creator cookie.
+func (h *Handler) isCreator(r *http.Request, roomID string) bool {
+	c, err := r.Cookie("creator")
+	return err == nil && h.sessions.verifyCreator(domain.RoomID(roomID), strings.TrimSpace(c.Value))
+}
+
+// setCreator gives the browser the creator claim for the room it created.
+func (h *Handler) setCreator(w http.ResponseWriter, r *http.Request, roomID string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     "creator",
+		Value:    h.sessions.signCreator(domain.RoomID(roomID)),
+		Path:     "/rooms/" + roomID,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// clearCreator removes the creator cookie once it has been used.
+func clearCreator(w http.ResponseWriter, roomID string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     "creator",
+		Value:    "",
+		Path:     "/rooms/" + roomID,
+		MaxAge:   -1,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// cookiePID returns the participant ID in a pid cookie value, unverified.
+func cookiePID(value string) domain.ParticipantID {
+	value = strings.TrimSpace(value)
+	if i := strings.LastIndexByte(value, '.'); i >= 0 {
+		value = value[:i]
+	}
+	return domain.ParticipantID(value)
+}
@@ -0,0 +1,79 @@
+package httpadapter
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jaminalder/estimations/internal/adapters/idgen"
+	"github.com/jaminalder/estimations/internal/adapters/memory"
+	"github.com/jaminalder/estimations/internal/app"
+	"github.com/jaminalder/estimations/internal/domain"
+)
+
+// testSessionKey signs the pid cookies of test servers (see signedPID).
+var testSessionKey = []byte("test session key of 32 bytes....")
+
+// signedPID returns the pid cookie value of a test server's participant.
+func signedPID(roomID domain.RoomID, pid domain.ParticipantID) string {
+	return sessionKeys{testSessionKey}.sign(roomID, pid)
+}
+
+func TestSessionKeys_SignVerifyRotate(t *testing.T) {
+	oldKey, newKey := []byte("old key"), []byte("new key")
+	old := sessionKeys{oldKey}
+	rotated := sessionKeys{newKey, oldKey}
+	value := old.sign("room", "alice")
+
+	if pid, ok := rotated.verify("room", value); !ok || pid != "alice" {
+		t.Fatalf("cookie signed with the old key: got %q, %v", pid, ok)
+	}
+	if rotated.sign("room", "alice") == value {
+		t.Fatal("the first key must sign")
+	}
+	for name, bad := range map[string]string{
+		"unsigned ID":       "alice",
+		"other participant": strings.Replace(value, "alice", "bob", 1),
+		"bad MAC":           value + "x",
+		"no ID":             "." + strings.SplitN(value, ".", 2)[1],
+	} {
+		if pid, ok := rotated.verify("room", bad); ok {
+			t.Errorf("%s: %q verified as %q", name, bad, pid)
+		}
+	}
+	if _, ok := rotated.verify("other-room", value); ok {
+		t.Error("cookie moved to another room verified")
+	}
+	if _, ok := (sessionKeys{newKey}).verify("room", value); ok {
+		t.Error("cookie of a retired key verified")
+	}
+}
+
+func TestSession_OnlySignedCookiesAct(t *testing.T) {
+	r, err := NewRenderer()
+	if err != nil {
+		t.Fatalf("renderer: %v", err)
+	}
+	svc := &app.Service{Rooms: memory.NewRoomRepo(), Ids: idgen.NewRandom(10, 8)}
+	srv := browser{NewServer(svc, r, WithSessionKeys(testSessionKey))}
+	ctx := context.Background()
+	roomID, _ := svc.CreateRoom(ctx)
+	otherRoom, _ := svc.CreateRoom(ctx)
+	pid, _ := svc.Join(ctx, roomID, "Alice")
+	castURL := "/rooms/" + string(roomID) + "/cast"
+
+	// Someone who learned a participant ID cannot act as that participant.
+	for _, forged := range []string{
+		string(pid),
+		sessionKeys{[]byte("guessed key")}.sign(roomID, pid),
+		signedPID(otherRoom, pid),
+	} {
+		if rec := postForm(srv, castURL, "card=5", "pid="+forged); rec.Code != http.StatusUnauthorized {
+			t.Errorf("cast with forged cookie %q: got %d want 401", forged, rec.Code)
+		}
+	}
+	if rec := postForm(srv, castURL, "card=5", "pid="+signedPID(roomID, pid)); rec.Code != http.StatusSeeOther {
+		t.Fatalf("cast with signed cookie: got %d want 303", rec.Code)
+	}
+}
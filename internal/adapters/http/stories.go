@@ -0,0 +1,110 @@
+package httpadapter
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/jaminalder/estimations/internal/domain"
+)
+
+// AddStory appends a story (title, optional key and link) to the backlog.
+func (h *Handler) AddStory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	roomID := chi.URLParam(r, "roomID")
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	pid := h.readPID(r)
+	if pid == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	story := domain.Story{Title: r.FormValue("title"), Key: r.FormValue("key"), Link: r.FormValue("link")}
+	if err := h.svc.AddStory(r.Context(), domain.RoomID(roomID), domain.ParticipantID(pid), story); err != nil {
+		h.commandFailed(w, r, roomID, err)
+		return
+	}
+	http.Redirect(w, r, "/rooms/"+roomID, http.StatusSeeOther)
+}
+
+// RemoveStory deletes the pending story at {index}.
+func (h *Handler) RemoveStory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	roomID := chi.URLParam(r, "roomID")
+	index, err := strconv.Atoi(chi.URLParam(r, "index"))
+	if err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	pid := h.readPID(r)
+	if pid == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if err := h.svc.RemoveStory(r.Context(), domain.RoomID(roomID), domain.ParticipantID(pid), index); err != nil {
+		h.commandFailed(w, r, roomID, err)
+		return
+	}
+	http.Redirect(w, r, "/rooms/"+roomID, http.StatusSeeOther)
+}
+
+// MoveStory moves the pending story at {index} to the position in form field "to".
+func (h *Handler) MoveStory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	roomID := chi.URLParam(r, "roomID")
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	from, err1 := strconv.Atoi(chi.URLParam(r, "index"))
+	to, err2 := strconv.Atoi(r.FormValue("to"))
+	if err1 != nil || err2 != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	pid := h.readPID(r)
+	if pid == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if err := h.svc.MoveStory(r.Context(), domain.RoomID(roomID), domain.ParticipantID(pid), from, to); err != nil {
+		h.commandFailed(w, r, roomID, err)
+		return
+	}
+	http.Redirect(w, r, "/rooms/"+roomID, http.StatusSeeOther)
+}
+
+// NextStory records the agreed estimate and moves on to the next story.
+func (h *Handler) NextStory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	roomID := chi.URLParam(r, "roomID")
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	pid := h.readPID(r)
+	if pid == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if err := h.svc.NextStory(r.Context(), domain.RoomID(roomID), domain.ParticipantID(pid), r.FormValue("estimate")); err != nil {
+		h.commandFailed(w, r, roomID, err)
+		return
+	}
+	http.Redirect(w, r, "/rooms/"+roomID, http.StatusSeeOther)
+}
@@ -0,0 +1,226 @@
+package httpadapter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/go-chi/chi/v5"
+
+	"github.com/jaminalder/estimations/internal/adapters/sse"
+	"github.com/jaminalder/estimations/internal/app"
+	"github.com/jaminalder/estimations/internal/domain"
+)
+
+// The WebSocket at /rooms/{roomID}/ws carries the same events as the SSE
+// stream and accepts the participant's commands, for networks that mangle
+// long-lived SSE responses. The participant is identified like on the HTML
+// pages (pid cookie) or like on the API (bearer token).
+//
+// Server frames are JSON objects:
+//
+//	{"type":"event","id":3,"event":"VoteCast","data":{...}}
+//	{"type":"reply","ref":"c1","ok":true}
+//	{"type":"reply","ref":"c1","ok":false,"error":{"code":"voting_closed","message":"..."}}
+//
+// Client frames name a command ("cast", "clear", "reveal", "reset" or
+// "leave") and an optional ref echoed by the reply:
+//
+//	{"type":"cast","ref":"c1","card":"5"}
+//
+// A last_event_id query parameter resumes the event stream like SSE's
+// Last-Event-ID header. A connection that falls too far behind the room's
+// events is closed with StatusTryAgainLater; clients reconnect with the last
+// ID they saw.
+
+// WebSocket limits.
+const (
+	wsWriteTimeout = 10 * time.Second
+	wsPongTimeout  = 10 * time.Second
+	wsMaxMessage   = 4096 // bytes per client frame
+	wsReplyQueue   = 8    // replies waiting to be written before reads stall
+)
+
+type wsCommand struct {
+	Type string `json:"type"`
+	Ref  string `json:"ref,omitempty"`
+	Card string `json:"card,omitempty"`
+}
+
+type wsFrame struct {
+	Type  string          `json:"type"`
+	ID    uint64          `json:"id,omitempty"`
+	Event string          `json:"event,omitempty"`
+	Data  json.RawMessage `json:"data,omitempty"`
+	Ref   string          `json:"ref,omitempty"`
+	OK    *bool           `json:"ok,omitempty"`
+	Error *apiErrorBody   `json:"error,omitempty"`
+}
+
+// Socket handles GET /rooms/{roomID}/ws until either side closes the
+// connection. Like the SSE stream, a participant's open socket marks them
+// present, and rooms with a passcode take participants only.
+func (h *Handler) Socket(w http.ResponseWriter, r *http.Request) {
+	roomID := domain.RoomID(chi.URLParam(r, "roomID"))
+	if h.svc == nil || h.events == nil {
+		http.Error(w, "service unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	room, err := h.svc.Snapshot(r.Context(), roomID)
+	if errors.Is(err, app.ErrRoomNotFound) {
+		http.NotFound(w, r)
+		return
+	} else if err != nil {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	pid := domain.ParticipantID(h.readPID(r))
+	if pid == "" {
+		pid = h.bearerPID(r, roomID)
+	}
+	if !room.HasParticipant(pid) {
+		pid = ""
+	}
+	if hiddenFrom(room, pid) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	var lastID uint64
+	if v := r.URL.Query().Get("last_event_id"); v != "" {
+		if lastID, err = strconv.ParseUint(v, 10, 64); err != nil {
+			http.Error(w, "bad last_event_id", http.StatusBadRequest)
+			return
+		}
+	}
+
+	conn, err := websocket.Accept(w, r, nil) // same-origin only
+	if err != nil {
+		return // Accept has answered the request
+	}
+	defer conn.CloseNow()
+	conn.SetReadLimit(wsMaxMessage)
+
+	ch, unsubscribe := h.events.Subscribe(roomID, lastID)
+	defer unsubscribe()
+	if pid != "" {
+		defer h.svc.Connect(roomID, pid)()
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	replies := make(chan wsFrame, wsReplyQueue)
+	go func() {
+		defer cancel()
+		h.readCommands(ctx, conn, roomID, pid, replies)
+	}()
+
+	// All writes happen here so events and replies never interleave.
+	ping := time.NewTicker(h.heartbeat)
+	defer ping.Stop()
+	for {
+		var frame wsFrame
+		select {
+		case <-ctx.Done():
+			return
+		case msg, open := <-ch:
+			if !open {
+				// Closed by the hub: the room expired or we fell behind.
+				conn.Close(websocket.StatusTryAgainLater, "event stream closed")
+				return
+			}
+			if room.HasPasscode && !h.keepsAccess(ctx, roomID, pid, msg) {
+				conn.Close(websocket.StatusPolicyViolation, "no longer a participant")
+				return
+			}
+			frame = wsEventFrame(msg)
+		case frame = <-replies:
+		case <-ping.C:
+			pctx, cancelPing := context.WithTimeout(ctx, wsPongTimeout)
+			err := conn.Ping(pctx)
+			cancelPing()
+			if err != nil {
+				return
+			}
+			continue
+		}
+		if err := wsWrite(ctx, conn, frame); err != nil {
+			return
+		}
+	}
+}
+
+// readCommands runs client commands in order until the connection fails,
+// queueing a reply for each. A full reply queue stalls reading, so a client
+// cannot outrun its own replies.
+func (h *Handler) readCommands(ctx context.Context, conn *websocket.Conn, roomID domain.RoomID, pid domain.ParticipantID, replies chan<- wsFrame) {
+	for {
+		_, data, err := conn.Read(ctx)
+		if err != nil {
+			return
+		}
+		var cmd wsCommand
+		var reply wsFrame
+		if err := json.Unmarshal(data, &cmd); err != nil {
+			reply = wsErrorFrame("", "invalid_json", err.Error())
+		} else {
+			reply = h.runCommand(ctx, roomID, pid, cmd)
+		}
+		select {
+		case replies <- reply:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// runCommand dispatches one client command to the service.
+func (h *Handler) runCommand(ctx context.Context, roomID domain.RoomID, pid domain.ParticipantID, cmd wsCommand) wsFrame {
+	var run func() error
+	switch cmd.Type {
+	case "cast":
+		run = func() error { return h.svc.Cast(ctx, roomID, pid, strings.TrimSpace(cmd.Card)) }
+	case "clear":
+		run = func() error { return h.svc.Clear(ctx, roomID, pid) }
+	case "reveal":
+		run = func() error { return h.svc.Reveal(ctx, roomID, pid) }
+	case "reset":
+		run = func() error { return h.svc.Reset(ctx, roomID, pid) }
+	case "leave":
+		run = func() error { return h.svc.Leave(ctx, roomID, pid) }
+	default:
+		return wsErrorFrame(cmd.Ref, "unknown_command", "unknown command "+strconv.Quote(cmd.Type))
+	}
+	if pid == "" {
+		return wsErrorFrame(cmd.Ref, "not_participant", "join the room before sending commands")
+	}
+	if err := run(); err != nil {
+		_, code, msg := describeError(err)
+		return wsErrorFrame(cmd.Ref, code, msg)
+	}
+	ok := true
+	return wsFrame{Type: "reply", Ref: cmd.Ref, OK: &ok}
+}
+
+func wsEventFrame(msg sse.Message) wsFrame {
+	return wsFrame{Type: "event", ID: msg.ID, Event: msg.Event, Data: msg.Data}
+}
+
+func wsErrorFrame(ref, code, msg string) wsFrame {
+	ok := false
+	return wsFrame{Type: "reply", Ref: ref, OK: &ok, Error: &apiErrorBody{Code: code, Message: msg}}
+}
+
+func wsWrite(ctx context.Context, conn *websocket.Conn, frame wsFrame) error {
+	data, err := json.Marshal(frame)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, wsWriteTimeout)
+	defer cancel()
+	return conn.Write(ctx, websocket.MessageText, data)
+}
@@ -0,0 +1,144 @@
+package httpadapter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+
+	"github.com/jaminalder/estimations/internal/adapters/sse"
+	"github.com/jaminalder/estimations/internal/domain"
+)
+
+// dialRoom opens a room's WebSocket with the given pid cookie value, if any.
+func dialRoom(t *testing.T, ctx context.Context, url, pidCookie string) *websocket.Conn {
+	t.Helper()
+	opts := &websocket.DialOptions{HTTPHeader: http.Header{}}
+	if pidCookie != "" {
+		opts.HTTPHeader.Set("Cookie", "pid="+pidCookie)
+	}
+	conn, _, err := websocket.Dial(ctx, url, opts)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.CloseNow() })
+	return conn
+}
+
+func wsSend(t *testing.T, ctx context.Context, conn *websocket.Conn, msg string) {
+	t.Helper()
+	if err := conn.Write(ctx, websocket.MessageText, []byte(msg)); err != nil {
+		t.Fatalf("write %s: %v", msg, err)
+	}
+}
+
+func wsNext(t *testing.T, ctx context.Context, conn *websocket.Conn) wsFrame {
+	t.Helper()
+	_, data, err := conn.Read(ctx)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	var f wsFrame
+	if err := json.Unmarshal(data, &f); err != nil {
+		t.Fatalf("decode %s: %v", data, err)
+	}
+	return f
+}
+
+func TestSocket_CommandsAndEvents(t *testing.T) {
+	hub := sse.NewHub(8)
+	ts, svc := newEventsTestServer(t, hub, hub)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	roomID, _ := svc.CreateRoom(ctx)
+	alice, _ := svc.Join(ctx, roomID, "Alice")
+	bob, _ := svc.Join(ctx, roomID, "Bob")
+	url := "ws" + strings.TrimPrefix(ts.URL, "http") + "/rooms/" + string(roomID) + "/ws"
+
+	// Bob resumes after the two joins, so he sees only what follows.
+	conn := dialRoom(t, ctx, url+"?last_event_id=2", signedPID(roomID, bob))
+	wsSend(t, ctx, conn, `{"type":"cast","ref":"c1","card":"5"}`)
+	var reply, event wsFrame
+	for range 2 {
+		switch f := wsNext(t, ctx, conn); f.Type {
+		case "reply":
+			reply = f
+		case "event":
+			event = f
+		}
+	}
+	if reply.Ref != "c1" || reply.OK == nil || !*reply.OK {
+		t.Fatalf("cast reply: %+v", reply)
+	}
+	if event.ID != 3 || event.Event != "VoteCast" {
+		t.Fatalf("cast event: %+v", event)
+	}
+
+	// Commands fail like on the API.
+	wsSend(t, ctx, conn, `{"type":"reveal","ref":"c2"}`)
+	if f := wsNext(t, ctx, conn); f.Ref != "c2" || *f.OK || f.Error == nil || f.Error.Code != "not_facilitator" {
+		t.Fatalf("reveal by bob: %+v", f)
+	}
+	wsSend(t, ctx, conn, `{"type":"shout"}`)
+	if f := wsNext(t, ctx, conn); f.Error == nil || f.Error.Code != "unknown_command" {
+		t.Fatalf("unknown command: %+v", f)
+	}
+
+	// Events from other clients are pushed too.
+	if err := svc.Reveal(ctx, roomID, alice); err != nil {
+		t.Fatalf("reveal: %v", err)
+	}
+	if f := wsNext(t, ctx, conn); f.Type != "event" || f.ID != 4 || f.Event != "VotesRevealed" {
+		t.Fatalf("revealed event: %+v", f)
+	}
+}
+
+func TestSocket_Anonymous_CannotCommand(t *testing.T) {
+	hub := sse.NewHub(8)
+	ts, svc := newEventsTestServer(t, hub, hub)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	roomID, _ := svc.CreateRoom(ctx)
+	url := "ws" + strings.TrimPrefix(ts.URL, "http") + "/rooms/" + string(roomID) + "/ws"
+
+	conn := dialRoom(t, ctx, url, "forged")
+	wsSend(t, ctx, conn, `{"type":"cast","card":"5"}`)
+	if f := wsNext(t, ctx, conn); f.Error == nil || f.Error.Code != "not_participant" {
+		t.Fatalf("anonymous cast: %+v", f)
+	}
+}
+
+func TestSocket_UnknownRoom_404(t *testing.T) {
+	hub := sse.NewHub(8)
+	ts, _ := newEventsTestServer(t, hub, hub)
+	_, res, err := websocket.Dial(context.Background(), "ws"+strings.TrimPrefix(ts.URL, "http")+"/rooms/nope/ws", nil)
+	if err == nil || res == nil || res.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404, got %v %v", res, err)
+	}
+}
+
+// droppedSource behaves like a hub that has already cut off a lagging
+// subscriber.
+type droppedSource struct{}
+
+func (droppedSource) Subscribe(domain.RoomID, uint64) (<-chan sse.Message, func()) {
+	ch := make(chan sse.Message)
+	close(ch)
+	return ch, func() {}
+}
+
+func TestSocket_DroppedByHub_AsksClientToReconnect(t *testing.T) {
+	ts, svc := newEventsTestServer(t, droppedSource{}, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	roomID, _ := svc.CreateRoom(ctx)
+
+	conn := dialRoom(t, ctx, "ws"+strings.TrimPrefix(ts.URL, "http")+"/rooms/"+string(roomID)+"/ws", "")
+	if _, _, err := conn.Read(ctx); websocket.CloseStatus(err) != websocket.StatusTryAgainLater {
+		t.Fatalf("expected try-again-later close, got %v", err)
+	}
+}
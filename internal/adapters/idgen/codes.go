@@ -0,0 +1,72 @@
+package idgen
+
+import (
+	"context"
+	"crypto/rand"
+
+	"github.com/jaminalder/estimations/internal/app"
+	"github.com/jaminalder/estimations/internal/domain"
+)
+
+// DefaultCodeLen is the recommended room code length: 30 bits, about a
+// billion codes.
+const DefaultCodeLen = 6
+
+// codeAttempts is how many taken codes Codes draws before it makes codes
+// one character longer.
+const codeAttempts = 10
+
+// Codes generates room IDs that can be read out over a call and typed in,
+// such as "7KQM2X": characters of app.CrockfordAlphabet, checked against the
+// room repository so a new room never gets the code of a live one. Short
+// codes can be guessed where Random IDs cannot; a guessed code only gets as
+// far as the lobby of a room with a passcode, whose contents are shown to
+// its participants only. Participant IDs are credentials and stay long and
+// random, as with Random.
+type Codes struct {
+	rooms     app.RoomRepo
+	length    int
+	partBytes int
+}
+
+// NewCodes creates a Codes generator for the rooms in rooms, with room codes
+// of length characters (see DefaultCodeLen) and participant IDs of
+// participantBytes random bytes.
+func NewCodes(rooms app.RoomRepo, length, participantBytes int) *Codes {
+	return &Codes{rooms: rooms, length: length, partBytes: participantBytes}
+}
+
+var _ app.IdGen = (*Codes)(nil)
+
+// NewRoomID returns a code no room in the repository has. Should the codes
+// of the current length run short, it moves on to longer ones. A failing
+// repository counts as a free code: creating the room then fails instead.
+// A code taken by a concurrent create after this check is caught by the
+// repository (app.ErrRoomExists), and Service.CreateRoom draws another.
+func (c *Codes) NewRoomID() domain.RoomID {
+	n := max(c.length, 4)
+	for attempt := 1; ; attempt++ {
+		id := domain.RoomID(randCode(n))
+		_, taken, err := c.rooms.Get(context.Background(), id)
+		if err != nil || !taken {
+			return id
+		}
+		if attempt%codeAttempts == 0 {
+			n++
+		}
+	}
+}
+
+func (c *Codes) NewParticipantID() domain.ParticipantID {
+	return domain.ParticipantID(randString(c.partBytes))
+}
+
+func randCode(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	for i := range b {
+		// 32 divides 256, so every character is equally likely.
+		b[i] = app.CrockfordAlphabet[b[i]%32]
+	}
+	return string(b)
+}
@@ -0,0 +1,61 @@
+package idgen
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/jaminalder/estimations/internal/adapters/memory"
+	"github.com/jaminalder/estimations/internal/app"
+	"github.com/jaminalder/estimations/internal/domain"
+)
+
+func TestCodes_Format(t *testing.T) {
+	g := NewCodes(memory.NewRoomRepo(), DefaultCodeLen, 8)
+	re := regexp.MustCompile(`^[0-9A-HJKMNP-TV-Z]{6}$`)
+	for i := 0; i < 200; i++ {
+		id := string(g.NewRoomID())
+		if !re.MatchString(id) {
+			t.Fatalf("room code %q is not 6 unambiguous characters", id)
+		}
+		if app.NormalizeCode(id) != id {
+			t.Fatalf("room code %q should already be in canonical form", id)
+		}
+	}
+	if id := string(g.NewParticipantID()); len(id) < 10 {
+		t.Fatalf("participant ids must stay long and random, got %q", id)
+	}
+}
+
+// takenRepo reports the first taken lookups as existing rooms.
+type takenRepo struct {
+	app.RoomRepo
+	taken, gets int
+	err         error
+}
+
+func (r *takenRepo) Get(ctx context.Context, id domain.RoomID) (*domain.Room, bool, error) {
+	r.gets++
+	if r.err != nil {
+		return nil, false, r.err
+	}
+	return nil, r.gets <= r.taken, nil
+}
+
+func TestCodes_SkipsTakenAndGrowsWhenCrowded(t *testing.T) {
+	repo := &takenRepo{taken: 3}
+	if id := NewCodes(repo, 6, 8).NewRoomID(); len(id) != 6 || repo.gets != 4 {
+		t.Fatalf("want a 6-character code after 3 taken ones, got %q after %d lookups", id, repo.gets)
+	}
+
+	repo = &takenRepo{taken: 2*codeAttempts + 1}
+	if id := NewCodes(repo, 6, 8).NewRoomID(); len(id) != 8 {
+		t.Fatalf("want codes to grow by one character every %d taken ones, got %q", codeAttempts, id)
+	}
+
+	repo = &takenRepo{err: errors.New("database is down")}
+	if id := NewCodes(repo, 6, 8).NewRoomID(); len(id) != 6 || repo.gets != 1 {
+		t.Fatalf("a failing repository should not stall, got %q after %d lookups", id, repo.gets)
+	}
+}
@@ -0,0 +1,81 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/jaminalder/estimations/internal/app"
+	"github.com/jaminalder/estimations/internal/domain"
+)
+
+// EventStore is an in-memory implementation of app.EventStore.
+type EventStore struct {
+	mu      sync.RWMutex
+	streams map[domain.RoomID][]app.EventRecord
+	snaps   map[domain.RoomID]app.StateSnapshot
+}
+
+func NewEventStore() *EventStore {
+	return &EventStore{
+		streams: make(map[domain.RoomID][]app.EventRecord),
+		snaps:   make(map[domain.RoomID]app.StateSnapshot),
+	}
+}
+
+var _ app.EventStore = (*EventStore)(nil)
+
+func (s *EventStore) Append(ctx context.Context, id domain.RoomID, records []app.EventRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	last := uint64(len(s.streams[id]))
+	for i, rec := range records {
+		if rec.Seq != last+uint64(i)+1 {
+			return fmt.Errorf("%w: room %s: got seq %d after %d", app.ErrStreamConflict, id, rec.Seq, last+uint64(i))
+		}
+	}
+	s.streams[id] = append(s.streams[id], records...)
+	return nil
+}
+
+func (s *EventStore) Load(ctx context.Context, id domain.RoomID, after uint64) ([]app.EventRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	stream := s.streams[id]
+	if after >= uint64(len(stream)) {
+		return nil, nil
+	}
+	return append([]app.EventRecord(nil), stream[after:]...), nil
+}
+
+func (s *EventStore) SaveSnapshot(ctx context.Context, id domain.RoomID, snap app.StateSnapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.snaps[id] = snap
+	return nil
+}
+
+func (s *EventStore) LoadSnapshot(ctx context.Context, id domain.RoomID) (app.StateSnapshot, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	snap, ok := s.snaps[id]
+	return snap, ok, nil
+}
+
+func (s *EventStore) Delete(ctx context.Context, id domain.RoomID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.streams, id)
+	delete(s.snaps, id)
+	return nil
+}
+
+func (s *EventStore) List(ctx context.Context) ([]domain.RoomID, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ids := make([]domain.RoomID, 0, len(s.streams))
+	for id := range s.streams {
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,12 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/jaminalder/estimations/internal/adapters/repotest"
+	"github.com/jaminalder/estimations/internal/app"
+)
+
+func TestEventStore_Contract(t *testing.T) {
+	repotest.EventStore(t, func(t *testing.T) app.EventStore { return NewEventStore() })
+}
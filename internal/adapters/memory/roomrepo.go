@@ -26,7 +26,7 @@ func (r *RoomRepo) Create(ctx context.Context, room *domain.Room) error {
 	defer r.mu.Unlock()
 	id := room.ID()
 	if _, exists := r.rooms[id]; exists {
-		return fmt.Errorf("room exists: %s", id)
+		return fmt.Errorf("create room %s: %w", id, app.ErrRoomExists)
 	}
 	r.rooms[id] = room
 	return nil
@@ -39,9 +39,33 @@ func (r *RoomRepo) Get(ctx context.Context, id domain.RoomID) (*domain.Room, boo
 	return rm, ok, nil
 }
 
+// Save stores the room; since Get hands out the stored pointer this only
+// matters if the caller replaced it. Saving a room that was never created
+// (or was deleted) is an error.
+func (r *RoomRepo) Save(ctx context.Context, room *domain.Room) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	id := room.ID()
+	if _, exists := r.rooms[id]; !exists {
+		return fmt.Errorf("save room %s: %w", id, app.ErrRoomNotFound)
+	}
+	r.rooms[id] = room
+	return nil
+}
+
 func (r *RoomRepo) Delete(ctx context.Context, id domain.RoomID) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	delete(r.rooms, id)
 	return nil
 }
+
+func (r *RoomRepo) List(ctx context.Context) ([]domain.RoomID, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ids := make([]domain.RoomID, 0, len(r.rooms))
+	for id := range r.rooms {
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,86 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/jaminalder/estimations/internal/adapters/sse"
+	"github.com/jaminalder/estimations/internal/app"
+	"github.com/jaminalder/estimations/internal/domain"
+)
+
+// Commands records use-case latencies and errors; install it as
+// app.Service.Metrics.
+type Commands struct {
+	duration *Histogram
+	errors   *Counter
+}
+
+var _ app.Metrics = (*Commands)(nil)
+
+// RegisterCommands registers the use-case metrics on reg.
+func RegisterCommands(reg *Registry) *Commands {
+	return &Commands{
+		duration: reg.Histogram("estimations_command_duration_seconds", "Duration of room commands.", DefaultBuckets, "command"),
+		errors:   reg.Counter("estimations_command_errors_total", "Room commands that failed, by error type.", "command", "error"),
+	}
+}
+
+// CommandDone implements app.Metrics.
+func (c *Commands) CommandDone(command string, took time.Duration, err error) {
+	c.duration.Observe(took.Seconds(), command)
+	if err != nil {
+		ec, _ := app.CodeOf(err) // "internal" for unexpected errors
+		c.errors.Inc(command, ec.Code)
+	}
+}
+
+// scrapeTimeout bounds the room listing done on each scrape.
+const scrapeTimeout = 5 * time.Second
+
+// RegisterRooms registers gauges of the service's rooms and participants,
+// computed on every scrape.
+func RegisterRooms(reg *Registry, svc *app.Service) {
+	list := func() []app.RoomSummary {
+		ctx, cancel := context.WithTimeout(context.Background(), scrapeTimeout)
+		defer cancel()
+		rooms, err := svc.ListRooms(ctx)
+		if err != nil {
+			slog.Error("metrics: list rooms", "error", err)
+		}
+		return rooms
+	}
+	reg.GaugeFunc("estimations_rooms", "Rooms currently open.", nil, func() []Sample {
+		return []Sample{{Value: float64(len(list()))}}
+	})
+	reg.GaugeFunc("estimations_participants", "Participants across all rooms, by role.", []string{"role"}, func() []Sample {
+		var voters, observers int
+		for _, r := range list() {
+			voters += r.Voters
+			observers += r.Observers
+		}
+		return []Sample{
+			{Labels: []string{domain.Voter.String()}, Value: float64(voters)},
+			{Labels: []string{domain.Observer.String()}, Value: float64(observers)},
+		}
+	})
+}
+
+// RegisterHub registers the hub's subscriber gauge and event counters.
+func RegisterHub(reg *Registry, hub *sse.Hub) {
+	reg.GaugeFunc("estimations_sse_subscribers", "Live-update subscribers (SSE and WebSocket) per room.", []string{"room"}, func() []Sample {
+		subs := hub.Stats().Subscribers
+		out := make([]Sample, 0, len(subs))
+		for id, n := range subs {
+			out = append(out, Sample{Labels: []string{string(id)}, Value: float64(n)})
+		}
+		return out
+	})
+	reg.CounterFunc("estimations_events_broadcast_total", "Room events broadcast by the hub.", func() float64 {
+		return float64(hub.Stats().Broadcast)
+	})
+	reg.CounterFunc("estimations_events_dropped_total", "Event deliveries dropped by disconnecting subscribers that fell behind.", func() float64 {
+		return float64(hub.Stats().Dropped)
+	})
+}
@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jaminalder/estimations/internal/adapters/idgen"
+	"github.com/jaminalder/estimations/internal/adapters/memory"
+	"github.com/jaminalder/estimations/internal/adapters/sse"
+	"github.com/jaminalder/estimations/internal/app"
+)
+
+func TestAppMetrics(t *testing.T) {
+	ctx := context.Background()
+	reg := NewRegistry()
+	hub := sse.NewHub(4)
+	svc := &app.Service{Rooms: memory.NewRoomRepo(), Ids: idgen.NewRandom(10, 8), Bus: hub}
+	svc.Metrics = RegisterCommands(reg)
+	RegisterRooms(reg, svc)
+	RegisterHub(reg, hub)
+
+	roomID, _ := svc.CreateRoom(ctx)
+	alice, _ := svc.Join(ctx, roomID, "Alice")
+	_, _ = svc.Join(ctx, roomID, "Olga", app.AsObserver())
+	_, unsubscribe := hub.Subscribe(roomID, 0)
+	defer unsubscribe()
+	_ = svc.Cast(ctx, roomID, alice, "XXL")
+	_ = svc.Reveal(ctx, "nope", alice)
+
+	var b strings.Builder
+	if err := reg.WriteText(&b); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	for _, want := range []string{
+		"estimations_rooms 1\n",
+		`estimations_participants{role="voter"} 1` + "\n",
+		`estimations_participants{role="observer"} 1` + "\n",
+		fmt.Sprintf(`estimations_sse_subscribers{room=%q} 1`, roomID) + "\n",
+		"estimations_events_broadcast_total 2\n",
+		"estimations_events_dropped_total 0\n",
+		`estimations_command_duration_seconds_count{command="join"} 2` + "\n",
+		`estimations_command_errors_total{command="cast",error="invalid_card"} 1` + "\n",
+		`estimations_command_errors_total{command="reveal",error="room_not_found"} 1` + "\n",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("missing %q in:\n%s", want, b.String())
+		}
+	}
+}
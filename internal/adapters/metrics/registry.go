@@ -0,0 +1,304 @@
+// Package metrics keeps in-process counters, gauges and histograms and
+// exposes them in the Prometheus text exposition format, so any Prometheus
+// compatible scraper can collect them without an external client library.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are histogram upper bounds, in seconds, suited to request
+// and command latencies.
+var DefaultBuckets = []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Registry holds metrics and writes them in registration order. It is safe
+// for concurrent use and serves the exposition format over HTTP.
+type Registry struct {
+	mu      sync.Mutex
+	metrics []metric
+	names   map[string]bool
+}
+
+// metric is a registered family able to write its samples.
+type metric interface {
+	write(w io.Writer) error
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry { return &Registry{names: make(map[string]bool)} }
+
+func (r *Registry) register(name string, m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.names[name] {
+		panic("metrics: duplicate metric " + name)
+	}
+	r.names[name] = true
+	r.metrics = append(r.metrics, m)
+}
+
+// WriteText writes every metric in the text exposition format.
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	metrics := slices.Clone(r.metrics)
+	r.mu.Unlock()
+	for _, m := range metrics {
+		if err := m.write(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ServeHTTP serves the metrics for scraping.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = r.WriteText(w)
+}
+
+// Counter is a family of monotonically increasing values, one per
+// combination of label values.
+type Counter struct{ family }
+
+// Counter registers a counter with the given label names.
+func (r *Registry) Counter(name, help string, labels ...string) *Counter {
+	c := &Counter{family{name: name, help: help, kind: "counter", labels: labels}}
+	r.register(name, c)
+	return c
+}
+
+// Inc adds one to the series with the given label values.
+func (c *Counter) Inc(values ...string) { c.Add(1, values...) }
+
+// Add adds v, which must not be negative, to the series.
+func (c *Counter) Add(v float64, values ...string) {
+	c.update(values, func(s *series) { s.value += v })
+}
+
+// Gauge is a family of values that go up and down.
+type Gauge struct{ family }
+
+// Gauge registers a gauge with the given label names.
+func (r *Registry) Gauge(name, help string, labels ...string) *Gauge {
+	g := &Gauge{family{name: name, help: help, kind: "gauge", labels: labels}}
+	r.register(name, g)
+	return g
+}
+
+// Set sets the series with the given label values.
+func (g *Gauge) Set(v float64, values ...string) {
+	g.update(values, func(s *series) { s.value = v })
+}
+
+// Add adds v (possibly negative) to the series.
+func (g *Gauge) Add(v float64, values ...string) {
+	g.update(values, func(s *series) { s.value += v })
+}
+
+// Histogram is a family of distributions over fixed buckets.
+type Histogram struct {
+	family
+	buckets []float64
+}
+
+// Histogram registers a histogram with the given bucket upper bounds (in
+// increasing order) and label names.
+func (r *Registry) Histogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	h := &Histogram{family: family{name: name, help: help, kind: "histogram", labels: labels}, buckets: buckets}
+	r.register(name, h)
+	return h
+}
+
+// Observe adds v to the distribution of the series with the given label values.
+func (h *Histogram) Observe(v float64, values ...string) {
+	h.update(values, func(s *series) {
+		if s.counts == nil {
+			s.counts = make([]uint64, len(h.buckets))
+		}
+		for i, le := range h.buckets {
+			if v <= le {
+				s.counts[i]++
+			}
+		}
+		s.count++
+		s.value += v
+	})
+}
+
+// Sample is one series reported by a function metric.
+type Sample struct {
+	Labels []string // label values, in the order of the label names
+	Value  float64
+}
+
+// GaugeFunc registers a gauge whose series are computed by fn on every scrape.
+func (r *Registry) GaugeFunc(name, help string, labels []string, fn func() []Sample) {
+	r.register(name, &funcMetric{family{name: name, help: help, kind: "gauge", labels: labels}, fn})
+}
+
+// CounterFunc registers an unlabeled counter read from fn on every scrape.
+func (r *Registry) CounterFunc(name, help string, fn func() float64) {
+	r.register(name, &funcMetric{family{name: name, help: help, kind: "counter"}, func() []Sample {
+		return []Sample{{Value: fn()}}
+	}})
+}
+
+// family holds the series of one metric, keyed by their label values.
+type family struct {
+	name, help, kind string
+	labels           []string
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+type series struct {
+	values []string
+	value  float64  // counter or gauge value; sum for histograms
+	counts []uint64 // cumulative bucket counts, histograms only
+	count  uint64   // observations, histograms only
+}
+
+func (f *family) update(values []string, fn func(*series)) {
+	if len(values) != len(f.labels) {
+		panic(fmt.Sprintf("metrics: %s takes %d label values, got %d", f.name, len(f.labels), len(values)))
+	}
+	key := strings.Join(values, "\xff")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.series == nil {
+		f.series = make(map[string]*series)
+	}
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{values: slices.Clone(values)}
+		f.series[key] = s
+	}
+	fn(s)
+}
+
+// snapshot returns copies of the series sorted by label values.
+func (f *family) snapshot() []series {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := make([]series, 0, len(f.series))
+	for _, s := range f.series {
+		c := *s
+		c.counts = slices.Clone(s.counts)
+		out = append(out, c)
+	}
+	slices.SortFunc(out, func(a, b series) int { return slices.Compare(a.values, b.values) })
+	return out
+}
+
+func (f *family) header(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", f.name, escapeHelp(f.help), f.name, f.kind)
+	return err
+}
+
+func (f *family) write(w io.Writer) error {
+	if err := f.header(w); err != nil {
+		return err
+	}
+	for _, s := range f.snapshot() {
+		if err := writeSample(w, f.name, f.labels, s.values, s.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *Histogram) write(w io.Writer) error {
+	if err := h.header(w); err != nil {
+		return err
+	}
+	labels := append(slices.Clone(h.labels), "le")
+	for _, s := range h.snapshot() {
+		for i, le := range h.buckets {
+			var n uint64
+			if s.counts != nil {
+				n = s.counts[i]
+			}
+			if err := writeSample(w, h.name+"_bucket", labels, append(slices.Clone(s.values), formatFloat(le)), float64(n)); err != nil {
+				return err
+			}
+		}
+		if err := writeSample(w, h.name+"_bucket", labels, append(slices.Clone(s.values), "+Inf"), float64(s.count)); err != nil {
+			return err
+		}
+		if err := writeSample(w, h.name+"_sum", h.labels, s.values, s.value); err != nil {
+			return err
+		}
+		if err := writeSample(w, h.name+"_count", h.labels, s.values, float64(s.count)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type funcMetric struct {
+	family
+	fn func() []Sample
+}
+
+func (m *funcMetric) write(w io.Writer) error {
+	if err := m.header(w); err != nil {
+		return err
+	}
+	samples := m.fn()
+	slices.SortFunc(samples, func(a, b Sample) int { return slices.Compare(a.Labels, b.Labels) })
+	for _, s := range samples {
+		if err := writeSample(w, m.name, m.labels, s.Labels, s.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeSample(w io.Writer, name string, labels, values []string, v float64) error {
+	var b strings.Builder
+	b.WriteString(name)
+	if len(labels) > 0 {
+		b.WriteByte('{')
+		for i, l := range labels {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(l)
+			b.WriteString(`="`)
+			b.WriteString(escapeLabel(values[i]))
+			b.WriteByte('"')
+		}
+		b.WriteByte('}')
+	}
+	b.WriteByte(' ')
+	b.WriteString(formatFloat(v))
+	b.WriteByte('\n')
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string  { return helpEscaper.Replace(s) }
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
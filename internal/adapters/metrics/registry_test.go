@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegistry_WritesTextFormat(t *testing.T) {
+	reg := NewRegistry()
+	c := reg.Counter("jobs_total", "Jobs done.", "queue")
+	c.Inc("b")
+	c.Add(2, "a")
+	c.Inc("b")
+	g := reg.Gauge("temperature", "Line one\nline two.")
+	g.Set(21.5)
+	g.Add(-1)
+	h := reg.Histogram("latency_seconds", "Latency.", []float64{0.1, 1}, "op")
+	h.Observe(0.05, "read")
+	h.Observe(0.5, "read")
+	h.Observe(3, "read")
+	reg.GaugeFunc("queue_depth", "Depth.", []string{"queue"}, func() []Sample {
+		return []Sample{{Labels: []string{`say "hi"`}, Value: 3}, {Labels: []string{"a"}, Value: 1}}
+	})
+
+	var b strings.Builder
+	if err := reg.WriteText(&b); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	want := `# HELP jobs_total Jobs done.
+# TYPE jobs_total counter
+jobs_total{queue="a"} 2
+jobs_total{queue="b"} 2
+# HELP temperature Line one\nline two.
+# TYPE temperature gauge
+temperature 20.5
+# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{op="read",le="0.1"} 1
+latency_seconds_bucket{op="read",le="1"} 2
+latency_seconds_bucket{op="read",le="+Inf"} 3
+latency_seconds_sum{op="read"} 3.55
+latency_seconds_count{op="read"} 3
+# HELP queue_depth Depth.
+# TYPE queue_depth gauge
+queue_depth{queue="a"} 1
+queue_depth{queue="say \"hi\""} 3
+`
+	if b.String() != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", b.String(), want)
+	}
+}
+
+func TestRegistry_DuplicateNamePanics(t *testing.T) {
+	reg := NewRegistry()
+	reg.Counter("x_total", "X.")
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic for a duplicate metric")
+		}
+	}()
+	reg.Gauge("x_total", "X again.")
+}
@@ -0,0 +1,156 @@
+package repotest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/jaminalder/estimations/internal/app"
+	"github.com/jaminalder/estimations/internal/domain"
+)
+
+// EventStore runs the EventStore contract suite. newStore must return an
+// empty store for every call.
+func EventStore(t *testing.T, newStore func(t *testing.T) app.EventStore) {
+	t.Run("AppendLoad", func(t *testing.T) { appendLoad(t, newStore(t)) })
+	t.Run("AppendConflict", func(t *testing.T) { appendConflict(t, newStore(t)) })
+	t.Run("Snapshot", func(t *testing.T) { snapshot(t, newStore(t)) })
+	t.Run("DeleteList", func(t *testing.T) { deleteList(t, newStore(t)) })
+}
+
+// Records builds n records continuing a stream after seq, one second apart.
+func Records(after uint64, n int) []app.EventRecord {
+	at := time.Date(2025, 9, 5, 9, 0, 0, 0, time.UTC)
+	out := make([]app.EventRecord, n)
+	for i := range out {
+		seq := after + uint64(i) + 1
+		data, _ := json.Marshal(app.VoteCast{RoomID: "r1", ParticipantID: "p1", Card: "5"})
+		out[i] = app.EventRecord{Seq: seq, At: at.Add(time.Duration(seq) * time.Second), Type: "VoteCast", Data: data}
+	}
+	return out
+}
+
+func appendLoad(t *testing.T, store app.EventStore) {
+	ctx := context.Background()
+	id := domain.RoomID("r1")
+	if got, err := store.Load(ctx, id, 0); err != nil || len(got) != 0 {
+		t.Fatalf("load missing stream: got %v, %v", got, err)
+	}
+	want := append(Records(0, 2), Records(2, 1)...)
+	if err := store.Append(ctx, id, want[:2]); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if err := store.Append(ctx, id, want[2:]); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	got, err := store.Load(ctx, id, 0)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("load: got %d records want %d", len(got), len(want))
+	}
+	for i := range want {
+		g, w := got[i], want[i]
+		if g.Seq != w.Seq || !g.At.Equal(w.At) || g.Type != w.Type || string(g.Data) != string(w.Data) {
+			t.Fatalf("record %d: got %+v want %+v", i, g, w)
+		}
+	}
+	if tail, err := store.Load(ctx, id, 2); err != nil || len(tail) != 1 || tail[0].Seq != 3 {
+		t.Fatalf("load after 2: got %v, %v", tail, err)
+	}
+	if tail, err := store.Load(ctx, id, 3); err != nil || len(tail) != 0 {
+		t.Fatalf("load after last: got %v, %v", tail, err)
+	}
+}
+
+func appendConflict(t *testing.T, store app.EventStore) {
+	ctx := context.Background()
+	id := domain.RoomID("r1")
+	if err := store.Append(ctx, id, Records(1, 1)); !errors.Is(err, app.ErrStreamConflict) {
+		t.Fatalf("new stream must start at 1: got %v", err)
+	}
+	if err := store.Append(ctx, id, Records(0, 1)); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if err := store.Append(ctx, id, Records(0, 1)); !errors.Is(err, app.ErrStreamConflict) {
+		t.Fatalf("duplicate seq: got %v", err)
+	}
+	gap := append(Records(1, 1), Records(3, 1)...)
+	if err := store.Append(ctx, id, gap); !errors.Is(err, app.ErrStreamConflict) {
+		t.Fatalf("gap in batch: got %v", err)
+	}
+	if got, _ := store.Load(ctx, id, 0); len(got) != 1 {
+		t.Fatalf("a rejected batch must not be stored, got %d records", len(got))
+	}
+}
+
+func snapshot(t *testing.T, store app.EventStore) {
+	ctx := context.Background()
+	id := domain.RoomID("r1")
+	if _, ok, err := store.LoadSnapshot(ctx, id); err != nil || ok {
+		t.Fatalf("missing snapshot: got %v, %v", ok, err)
+	}
+	room := domain.NewRoom(id)
+	_ = room.Join("p1", "Alice")
+	_ = room.JoinAs("p2", "Olga", domain.Observer)
+	_ = room.CastVote("p1", "8")
+	_ = room.Reveal("p1", time.Date(2025, 9, 5, 9, 0, 0, 0, time.UTC))
+	first := app.StateSnapshot{Seq: 4, At: time.Date(2025, 9, 5, 9, 1, 0, 0, time.UTC), State: room.State()}
+	if err := store.SaveSnapshot(ctx, id, first); err != nil {
+		t.Fatalf("save snapshot: %v", err)
+	}
+	_ = room.Reset("p1", time.Date(2025, 9, 5, 9, 2, 0, 0, time.UTC))
+	second := app.StateSnapshot{Seq: 5, At: time.Date(2025, 9, 5, 9, 2, 0, 0, time.UTC), State: room.State()}
+	if err := store.SaveSnapshot(ctx, id, second); err != nil {
+		t.Fatalf("replace snapshot: %v", err)
+	}
+	got, ok, err := store.LoadSnapshot(ctx, id)
+	if err != nil || !ok {
+		t.Fatalf("load snapshot: %v, %v", ok, err)
+	}
+	if got.Seq != second.Seq || !got.At.Equal(second.At) || !reflect.DeepEqual(normalize(got.State), normalize(second.State)) {
+		t.Fatalf("snapshot mismatch:\n got %+v\nwant %+v", got, second)
+	}
+}
+
+func deleteList(t *testing.T, store app.EventStore) {
+	ctx := context.Background()
+	if ids, err := store.List(ctx); err != nil || len(ids) != 0 {
+		t.Fatalf("empty store: got %v, %v", ids, err)
+	}
+	for _, id := range []domain.RoomID{"b", "a", "c"} {
+		if err := store.Append(ctx, id, Records(0, 2)); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+	_ = store.SaveSnapshot(ctx, "c", app.StateSnapshot{Seq: 2, State: domain.NewRoom("c").State()})
+	if err := store.Delete(ctx, "c"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err := store.Delete(ctx, "c"); err != nil {
+		t.Fatalf("deleting a missing stream should be a no-op: %v", err)
+	}
+	ids, err := store.List(ctx)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	if len(ids) != 2 || ids[0] != "a" || ids[1] != "b" {
+		t.Fatalf("list: got %v want [a b]", ids)
+	}
+	if got, _ := store.Load(ctx, "c", 0); len(got) != 0 {
+		t.Fatalf("deleted stream should be empty, got %d records", len(got))
+	}
+	if _, ok, _ := store.LoadSnapshot(ctx, "c"); ok {
+		t.Fatalf("deleted stream's snapshot should be gone")
+	}
+	// A deleted stream can start over.
+	if err := store.Append(ctx, "c", Records(0, 1)); err != nil {
+		t.Fatalf("append after delete: %v", err)
+	}
+}
@@ -0,0 +1,214 @@
+// Package repotest holds the behavioural contracts every app.RoomRepo and
+// app.EventStore implementation must satisfy; adapter tests run them against
+// their stores.
+package repotest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/jaminalder/estimations/internal/app"
+	"github.com/jaminalder/estimations/internal/domain"
+)
+
+// RoomRepo runs the RoomRepo contract suite. newRepo must return an empty
+// repository for every call.
+func RoomRepo(t *testing.T, newRepo func(t *testing.T) app.RoomRepo) {
+	t.Run("CreateGetDelete", func(t *testing.T) { createGetDelete(t, newRepo(t)) })
+	t.Run("CreateDuplicate", func(t *testing.T) { createDuplicate(t, newRepo(t)) })
+	t.Run("GetMissing", func(t *testing.T) { getMissing(t, newRepo(t)) })
+	t.Run("SaveRoundTrip", func(t *testing.T) { saveRoundTrip(t, newRepo(t)) })
+	t.Run("SaveMissing", func(t *testing.T) { saveMissing(t, newRepo(t)) })
+	t.Run("List", func(t *testing.T) { list(t, newRepo(t)) })
+}
+
+func createGetDelete(t *testing.T, repo app.RoomRepo) {
+	ctx := context.Background()
+	id := domain.RoomID("r1")
+	room := domain.NewRoom(id)
+
+	// Create
+	if err := repo.Create(ctx, room); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	// Get
+	got, ok, err := repo.Get(ctx, id)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if !ok || got == nil {
+		t.Fatalf("expected room to exist")
+	}
+	if got.ID() != id {
+		t.Fatalf("id mismatch: %s", got.ID())
+	}
+
+	// Mutate and save
+	if err := got.Join(domain.ParticipantID("p1"), "Alice"); err != nil {
+		t.Fatalf("mutate: %v", err)
+	}
+	if err := repo.Save(ctx, got); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	again, ok, _ := repo.Get(ctx, id)
+	if !ok || len(again.Participants()) != 1 {
+		t.Fatalf("expected saved mutation to persist in repo")
+	}
+
+	// Delete
+	if err := repo.Delete(ctx, id); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, ok, _ := repo.Get(ctx, id); ok {
+		t.Fatalf("expected room to be gone after delete")
+	}
+	if err := repo.Delete(ctx, id); err != nil {
+		t.Fatalf("deleting a missing room should be a no-op: %v", err)
+	}
+}
+
+func createDuplicate(t *testing.T, repo app.RoomRepo) {
+	ctx := context.Background()
+	id := domain.RoomID("dup")
+	if err := repo.Create(ctx, domain.NewRoom(id)); err != nil {
+		t.Fatalf("first create: %v", err)
+	}
+	if err := repo.Create(ctx, domain.NewRoom(id)); !errors.Is(err, app.ErrRoomExists) {
+		t.Fatalf("duplicate create: got %v, want ErrRoomExists", err)
+	}
+}
+
+func getMissing(t *testing.T, repo app.RoomRepo) {
+	got, ok, err := repo.Get(context.Background(), domain.RoomID("nope"))
+	if err != nil || ok || got != nil {
+		t.Fatalf("get missing: got %v, %v, %v", got, ok, err)
+	}
+}
+
+func saveRoundTrip(t *testing.T, repo app.RoomRepo) {
+	ctx := context.Background()
+	deck, _ := domain.PresetDeck(domain.DeckModifiedFibonacci)
+	room := domain.NewRoomWithDeck(domain.RoomID("full"), deck)
+	if err := room.SetTitle("Sprint 12"); err != nil {
+		t.Fatalf("title: %v", err)
+	}
+	room.SetPasscode("pbkdf2-sha256$1$c2FsdA$a2V5")
+	if err := repo.Create(ctx, room); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	host, bob, olga := domain.ParticipantID("p1"), domain.ParticipantID("p2"), domain.ParticipantID("p3")
+	at := time.Date(2025, 9, 3, 10, 0, 0, 0, time.UTC)
+	steps := []error{
+		room.JoinAsCreator(host, "Alice", domain.Voter),
+		room.Join(bob, "Bob"),
+		room.JoinAs(olga, "Olga", domain.Observer),
+		room.AddStory(host, domain.Story{Title: "Login", Key: "PROJ-1", Link: "https://example.com/PROJ-1"}),
+		room.AddStory(host, domain.Story{Title: "Logout"}),
+		room.CastVote(host, "½"),
+		room.CastVote(bob, "Pass"),
+		room.Reveal(host, at),
+		room.NextStory(host, "½", at.Add(time.Minute)),
+		room.TransferFacilitator(host, bob),
+		room.SetLabel(bob, "Logout (split)"),
+		room.CastVote(bob, "8"),
+		room.Reveal(bob, at.Add(2*time.Minute)),
+		room.SetLocked(bob, true),
+	}
+	for i, err := range steps {
+		if err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+	}
+	if err := repo.Save(ctx, room); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	assertStored(t, repo, room)
+
+	// Later saves must drop what the room no longer holds.
+	steps = []error{
+		room.Reset(bob, at.Add(3*time.Minute)),
+		room.CastVote(bob, "3"),
+		room.Leave(olga),
+		room.AddStory(bob, domain.Story{Title: "Signup"}),
+		room.RemoveStory(bob, 2),
+	}
+	for i, err := range steps {
+		if err != nil {
+			t.Fatalf("later step %d: %v", i, err)
+		}
+		if err := repo.Save(ctx, room); err != nil {
+			t.Fatalf("save after later step %d: %v", i, err)
+		}
+		assertStored(t, repo, room)
+	}
+}
+
+func assertStored(t *testing.T, repo app.RoomRepo, room *domain.Room) {
+	t.Helper()
+	got, ok, err := repo.Get(context.Background(), room.ID())
+	if err != nil || !ok {
+		t.Fatalf("get: %v, %v", ok, err)
+	}
+	if want, have := room.State(), got.State(); !reflect.DeepEqual(normalize(want), normalize(have)) {
+		t.Fatalf("state mismatch after save:\n got %+v\nwant %+v", have, want)
+	}
+}
+
+func saveMissing(t *testing.T, repo app.RoomRepo) {
+	if err := repo.Save(context.Background(), domain.NewRoom(domain.RoomID("ghost"))); !errors.Is(err, app.ErrRoomNotFound) {
+		t.Fatalf("saving an unknown room: got %v, want ErrRoomNotFound", err)
+	}
+}
+
+func list(t *testing.T, repo app.RoomRepo) {
+	ctx := context.Background()
+	if ids, err := repo.List(ctx); err != nil || len(ids) != 0 {
+		t.Fatalf("empty repo: got %v, %v", ids, err)
+	}
+	for _, id := range []domain.RoomID{"b", "a", "c"} {
+		if err := repo.Create(ctx, domain.NewRoom(id)); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+	_ = repo.Delete(ctx, domain.RoomID("c"))
+	ids, err := repo.List(ctx)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	if len(ids) != 2 || ids[0] != "a" || ids[1] != "b" {
+		t.Fatalf("list: got %v want [a b]", ids)
+	}
+}
+
+// normalize makes states comparable across storage round trips: times are
+// compared in UTC and empty collections as nil.
+func normalize(s domain.RoomState) domain.RoomState {
+	s.RevealedAt = s.RevealedAt.UTC()
+	if len(s.Votes) == 0 {
+		s.Votes = nil
+	}
+	if len(s.History) == 0 {
+		s.History = nil
+	}
+	for i := range s.History {
+		s.History[i].RevealedAt = s.History[i].RevealedAt.UTC()
+		s.History[i].ResetAt = s.History[i].ResetAt.UTC()
+		if len(s.History[i].Votes) == 0 {
+			s.History[i].Votes = nil
+		}
+	}
+	if len(s.Stories) == 0 {
+		s.Stories = nil
+	}
+	if len(s.Participants) == 0 {
+		s.Participants = nil
+	}
+	return s
+}
@@ -0,0 +1,102 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// migrations are applied in order; a database records the number applied in
+// schema_migrations. Never edit a released migration — append a new one.
+var migrations = []string{
+	// 1: rooms with participants, votes, round state, story backlog and
+	// round history.
+	`CREATE TABLE rooms (
+		id            TEXT PRIMARY KEY,
+		title         TEXT NOT NULL DEFAULT '',
+		deck_name     TEXT NOT NULL,
+		deck_cards    TEXT NOT NULL, -- JSON array
+		facilitator   TEXT NOT NULL DEFAULT '',
+		revealed      INTEGER NOT NULL DEFAULT 0,
+		round         INTEGER NOT NULL DEFAULT 0,
+		label         TEXT NOT NULL DEFAULT '',
+		revealed_at   TEXT NOT NULL DEFAULT '', -- RFC 3339, '' when unset
+		current_story INTEGER NOT NULL DEFAULT 0
+	);
+	CREATE TABLE participants (
+		room_id TEXT NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+		seq     INTEGER NOT NULL, -- join order
+		id      TEXT NOT NULL,
+		name    TEXT NOT NULL,
+		kind    TEXT NOT NULL,
+		PRIMARY KEY (room_id, id)
+	);
+	CREATE TABLE votes (
+		room_id        TEXT NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+		participant_id TEXT NOT NULL,
+		card           TEXT NOT NULL,
+		PRIMARY KEY (room_id, participant_id)
+	);
+	CREATE TABLE stories (
+		room_id  TEXT NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+		pos      INTEGER NOT NULL,
+		title    TEXT NOT NULL,
+		key      TEXT NOT NULL DEFAULT '',
+		link     TEXT NOT NULL DEFAULT '',
+		estimate TEXT NOT NULL DEFAULT '',
+		PRIMARY KEY (room_id, pos)
+	);
+	CREATE TABLE rounds (
+		room_id     TEXT NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+		pos         INTEGER NOT NULL, -- oldest first
+		idx         INTEGER NOT NULL,
+		label       TEXT NOT NULL DEFAULT '',
+		estimate    TEXT NOT NULL DEFAULT '',
+		votes       TEXT NOT NULL, -- JSON array of {Name, Card}
+		revealed_at TEXT NOT NULL DEFAULT '',
+		reset_at    TEXT NOT NULL DEFAULT '',
+		PRIMARY KEY (room_id, pos)
+	);`,
+	// 2: room passcode (hash) and lock.
+	`ALTER TABLE rooms ADD COLUMN passcode TEXT NOT NULL DEFAULT '';
+	ALTER TABLE rooms ADD COLUMN locked INTEGER NOT NULL DEFAULT 0;`,
+	// 3: whether the creator's one-time claim was used.
+	`ALTER TABLE rooms ADD COLUMN creator_joined INTEGER NOT NULL DEFAULT 0;`,
+}
+
+// migrate brings the schema up to date, applying each pending migration in
+// its own transaction.
+func migrate(ctx context.Context, db *sql.DB) error {
+	return migrateTo(ctx, db, len(migrations))
+}
+
+func migrateTo(ctx context.Context, db *sql.DB, target int) error {
+	if _, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (version INTEGER PRIMARY KEY)`); err != nil {
+		return fmt.Errorf("migrate: %w", err)
+	}
+	var current int
+	if err := db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current); err != nil {
+		return fmt.Errorf("migrate: read version: %w", err)
+	}
+	if current > len(migrations) {
+		return fmt.Errorf("migrate: database schema v%d is newer than this build (v%d)", current, len(migrations))
+	}
+	for v := current + 1; v <= target; v++ {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("migrate: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, migrations[v-1]); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("migrate to v%d: %w", v, err)
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version) VALUES (?)`, v); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("migrate to v%d: %w", v, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("migrate to v%d: %w", v, err)
+		}
+	}
+	return nil
+}
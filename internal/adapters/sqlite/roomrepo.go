@@ -0,0 +1,351 @@
+// Package sqlite provides a persistent app.RoomRepo backed by SQLite, using
+// the pure-Go modernc.org/sqlite driver so the server builds without cgo.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	_ "modernc.org/sqlite" // registers the "sqlite" driver
+
+	"github.com/jaminalder/estimations/internal/app"
+	"github.com/jaminalder/estimations/internal/domain"
+)
+
+// RoomRepo stores rooms in a SQLite database. Get returns a freshly loaded
+// room on every call; changes are written back with Save.
+type RoomRepo struct {
+	db *sql.DB
+}
+
+var _ app.RoomRepo = (*RoomRepo)(nil)
+
+// Open opens (creating if needed) the database file at path and applies any
+// pending schema migrations.
+func Open(ctx context.Context, path string) (*RoomRepo, error) {
+	db, err := sql.Open("sqlite", dsn(path))
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+	// A single connection serializes writers and keeps the pragmas in force.
+	db.SetMaxOpenConns(1)
+	if err := migrate(ctx, db); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return &RoomRepo{db: db}, nil
+}
+
+func dsn(path string) string {
+	q := url.Values{}
+	q.Add("_pragma", "foreign_keys(1)")
+	q.Add("_pragma", "busy_timeout(5000)")
+	q.Add("_pragma", "journal_mode(WAL)")
+	return "file:" + path + "?" + q.Encode()
+}
+
+// Close closes the underlying database.
+func (r *RoomRepo) Close() error { return r.db.Close() }
+
+// Ping checks that the database answers; it implements app.Pinger.
+func (r *RoomRepo) Ping(ctx context.Context) error { return r.db.PingContext(ctx) }
+
+func (r *RoomRepo) Create(ctx context.Context, room *domain.Room) error {
+	s := room.State()
+	return r.tx(ctx, func(tx *sql.Tx) error {
+		cards, err := json.Marshal(s.DeckCards)
+		if err != nil {
+			return err
+		}
+		res, err := tx.ExecContext(ctx, `INSERT INTO rooms
+			(id, title, deck_name, deck_cards, facilitator, revealed, round, label, revealed_at, current_story, passcode, locked, creator_joined)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (id) DO NOTHING`,
+			s.ID, s.Title, s.DeckName, string(cards), s.Facilitator, s.Revealed, s.Round, s.Label, formatTime(s.RevealedAt), s.CurrentStory, s.Passcode, s.Locked, s.CreatorJoined)
+		if err != nil {
+			return fmt.Errorf("create room %s: %w", s.ID, err)
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return fmt.Errorf("create room %s: %w", s.ID, err)
+		} else if n == 0 {
+			return fmt.Errorf("create room %s: %w", s.ID, app.ErrRoomExists)
+		}
+		return writeChildren(ctx, tx, s)
+	})
+}
+
+func (r *RoomRepo) Get(ctx context.Context, id domain.RoomID) (*domain.Room, bool, error) {
+	var (
+		s          = domain.RoomState{ID: id, Votes: make(map[domain.ParticipantID]string)}
+		cards      string
+		revealedAt string
+	)
+	err := r.db.QueryRowContext(ctx, `SELECT title, deck_name, deck_cards, facilitator, revealed, round, label, revealed_at, current_story, passcode, locked, creator_joined
+		FROM rooms WHERE id = ?`, id).
+		Scan(&s.Title, &s.DeckName, &cards, &s.Facilitator, &s.Revealed, &s.Round, &s.Label, &revealedAt, &s.CurrentStory, &s.Passcode, &s.Locked, &s.CreatorJoined)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, fmt.Errorf("get room %s: %w", id, err)
+	}
+	if err := json.Unmarshal([]byte(cards), &s.DeckCards); err != nil {
+		return nil, false, fmt.Errorf("get room %s: deck: %w", id, err)
+	}
+	if s.RevealedAt, err = parseTime(revealedAt); err != nil {
+		return nil, false, fmt.Errorf("get room %s: %w", id, err)
+	}
+	if err := r.readChildren(ctx, &s); err != nil {
+		return nil, false, fmt.Errorf("get room %s: %w", id, err)
+	}
+	room, err := domain.RestoreRoom(s)
+	if err != nil {
+		return nil, false, err
+	}
+	return room, true, nil
+}
+
+// Save writes the room's current state. The room must have been created.
+// Only rows that changed are written and round history is appended, so a
+// command costs a handful of writes however long the session has run.
+func (r *RoomRepo) Save(ctx context.Context, room *domain.Room) error {
+	s := room.State()
+	return r.tx(ctx, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, `UPDATE rooms SET
+			title = ?, facilitator = ?, revealed = ?, round = ?, label = ?, revealed_at = ?, current_story = ?, locked = ?, creator_joined = ?
+			WHERE id = ?`,
+			s.Title, s.Facilitator, s.Revealed, s.Round, s.Label, formatTime(s.RevealedAt), s.CurrentStory, s.Locked, s.CreatorJoined, s.ID)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return fmt.Errorf("save room %s: %w", s.ID, app.ErrRoomNotFound)
+		}
+		return writeChildren(ctx, tx, s)
+	})
+}
+
+func (r *RoomRepo) Delete(ctx context.Context, id domain.RoomID) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM rooms WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("delete room %s: %w", id, err)
+	}
+	return nil
+}
+
+func (r *RoomRepo) List(ctx context.Context) ([]domain.RoomID, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id FROM rooms`)
+	if err != nil {
+		return nil, fmt.Errorf("list rooms: %w", err)
+	}
+	var ids []domain.RoomID
+	err = scanAll(rows, func() error {
+		var id domain.RoomID
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+		ids = append(ids, id)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list rooms: %w", err)
+	}
+	return ids, nil
+}
+
+func (r *RoomRepo) tx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// writeChildren brings the per-room rows in line with s: changed rows are
+// upserted, removed ones deleted and only new history rounds inserted.
+func writeChildren(ctx context.Context, tx *sql.Tx, s domain.RoomState) error {
+	pids := make([]domain.ParticipantID, 0, len(s.Participants))
+	for i, p := range s.Participants {
+		pids = append(pids, p.ID)
+		if _, err := tx.ExecContext(ctx, `INSERT INTO participants (room_id, seq, id, name, kind) VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT (room_id, id) DO UPDATE SET seq = excluded.seq, name = excluded.name, kind = excluded.kind
+			WHERE (seq, name, kind) IS NOT (excluded.seq, excluded.name, excluded.kind)`,
+			s.ID, i, p.ID, p.Name, p.Kind.String()); err != nil {
+			return err
+		}
+	}
+	if err := deleteExcept(ctx, tx, `participants`, `id`, s.ID, pids); err != nil {
+		return err
+	}
+
+	voters := make([]domain.ParticipantID, 0, len(s.Votes))
+	for pid, card := range s.Votes {
+		voters = append(voters, pid)
+		if _, err := tx.ExecContext(ctx, `INSERT INTO votes (room_id, participant_id, card) VALUES (?, ?, ?)
+			ON CONFLICT (room_id, participant_id) DO UPDATE SET card = excluded.card
+			WHERE card IS NOT excluded.card`,
+			s.ID, pid, card); err != nil {
+			return err
+		}
+	}
+	if err := deleteExcept(ctx, tx, `votes`, `participant_id`, s.ID, voters); err != nil {
+		return err
+	}
+
+	for i, st := range s.Stories {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO stories (room_id, pos, title, key, link, estimate) VALUES (?, ?, ?, ?, ?, ?)
+			ON CONFLICT (room_id, pos) DO UPDATE SET title = excluded.title, key = excluded.key, link = excluded.link, estimate = excluded.estimate
+			WHERE (title, key, link, estimate) IS NOT (excluded.title, excluded.key, excluded.link, excluded.estimate)`,
+			s.ID, i, st.Title, st.Key, st.Link, st.Estimate); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM stories WHERE room_id = ? AND pos >= ?`, s.ID, len(s.Stories)); err != nil {
+		return err
+	}
+
+	// History only ever grows, so rounds already stored are left alone.
+	var stored int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM rounds WHERE room_id = ?`, s.ID).Scan(&stored); err != nil {
+		return err
+	}
+	for i := stored; i < len(s.History); i++ {
+		h := s.History[i]
+		votes, err := json.Marshal(h.Votes)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT INTO rounds (room_id, pos, idx, label, estimate, votes, revealed_at, reset_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			s.ID, i, h.Index, h.Label, h.Estimate, string(votes), formatTime(h.RevealedAt), formatTime(h.ResetAt)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteExcept removes the room's rows in table whose column value is not
+// one of keep.
+func deleteExcept(ctx context.Context, tx *sql.Tx, table, column string, id domain.RoomID, keep []domain.ParticipantID) error {
+	ids, err := json.Marshal(keep)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `DELETE FROM `+table+` WHERE room_id = ? AND `+column+` NOT IN (SELECT value FROM json_each(?))`, id, string(ids))
+	return err
+}
+
+func (r *RoomRepo) readChildren(ctx context.Context, s *domain.RoomState) error {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, name, kind FROM participants WHERE room_id = ? ORDER BY seq`, s.ID)
+	if err != nil {
+		return err
+	}
+	err = scanAll(rows, func() error {
+		var p domain.Participant
+		var kind string
+		if err := rows.Scan(&p.ID, &p.Name, &kind); err != nil {
+			return err
+		}
+		if kind == domain.Observer.String() {
+			p.Kind = domain.Observer
+		}
+		s.Participants = append(s.Participants, p)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	rows, err = r.db.QueryContext(ctx, `SELECT participant_id, card FROM votes WHERE room_id = ?`, s.ID)
+	if err != nil {
+		return err
+	}
+	err = scanAll(rows, func() error {
+		var pid domain.ParticipantID
+		var card string
+		if err := rows.Scan(&pid, &card); err != nil {
+			return err
+		}
+		s.Votes[pid] = card
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	rows, err = r.db.QueryContext(ctx, `SELECT title, key, link, estimate FROM stories WHERE room_id = ? ORDER BY pos`, s.ID)
+	if err != nil {
+		return err
+	}
+	err = scanAll(rows, func() error {
+		var st domain.Story
+		if err := rows.Scan(&st.Title, &st.Key, &st.Link, &st.Estimate); err != nil {
+			return err
+		}
+		s.Stories = append(s.Stories, st)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	rows, err = r.db.QueryContext(ctx, `SELECT idx, label, estimate, votes, revealed_at, reset_at FROM rounds WHERE room_id = ? ORDER BY pos`, s.ID)
+	if err != nil {
+		return err
+	}
+	return scanAll(rows, func() error {
+		var (
+			h                          domain.RoundResult
+			votes, revealedAt, resetAt string
+		)
+		if err := rows.Scan(&h.Index, &h.Label, &h.Estimate, &votes, &revealedAt, &resetAt); err != nil {
+			return err
+		}
+		if err := json.Unmarshal([]byte(votes), &h.Votes); err != nil {
+			return err
+		}
+		var err error
+		if h.RevealedAt, err = parseTime(revealedAt); err != nil {
+			return err
+		}
+		if h.ResetAt, err = parseTime(resetAt); err != nil {
+			return err
+		}
+		s.History = append(s.History, h)
+		return nil
+	})
+}
+
+// scanAll calls fn for every row and closes rows.
+func scanAll(rows *sql.Rows, fn func() error) error {
+	defer rows.Close()
+	for rows.Next() {
+		if err := fn(); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// Times are stored as RFC 3339 text in UTC; the zero time is stored as ”.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339Nano, s)
+}
@@ -0,0 +1,125 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/jaminalder/estimations/internal/adapters/repotest"
+	"github.com/jaminalder/estimations/internal/app"
+	"github.com/jaminalder/estimations/internal/domain"
+)
+
+func openTemp(t *testing.T, path string) *RoomRepo {
+	t.Helper()
+	repo, err := Open(context.Background(), path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() { _ = repo.Close() })
+	return repo
+}
+
+func TestRoomRepo_Contract(t *testing.T) {
+	repotest.RoomRepo(t, func(t *testing.T) app.RoomRepo {
+		return openTemp(t, filepath.Join(t.TempDir(), "rooms.db"))
+	})
+}
+
+func TestRoomRepo_SurvivesReopen(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "rooms.db")
+
+	repo, err := Open(ctx, path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	room := domain.NewRoom(domain.RoomID("r1"))
+	_ = repo.Create(ctx, room)
+	_ = room.Join(domain.ParticipantID("p1"), "Alice")
+	_ = room.CastVote(domain.ParticipantID("p1"), "5")
+	if err := repo.Save(ctx, room); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if err := repo.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	got, ok, err := openTemp(t, path).Get(ctx, room.ID())
+	if err != nil || !ok {
+		t.Fatalf("get after reopen: %v, %v", ok, err)
+	}
+	if got.Votes()[domain.ParticipantID("p1")] != "5" || !got.IsFacilitator(domain.ParticipantID("p1")) {
+		t.Fatalf("room state lost across reopen: %+v", got.State())
+	}
+}
+
+func TestMigrate_UpgradesExistingDatabase(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "rooms.db")
+
+	// A database created by a build that only knew the first migration.
+	db, err := sql.Open("sqlite", dsn(path))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := migrateTo(ctx, db, 1); err != nil {
+		t.Fatalf("migrate v1: %v", err)
+	}
+	if _, err := db.ExecContext(ctx, `INSERT INTO rooms (id, deck_name, deck_cards, round) VALUES ('old', 'tshirt', '["S","M","L"]', 3)`); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	_ = db.Close()
+
+	repo := openTemp(t, path)
+	var version int
+	if err := repo.db.QueryRowContext(ctx, `SELECT MAX(version) FROM schema_migrations`).Scan(&version); err != nil || version != len(migrations) {
+		t.Fatalf("expected schema v%d, got v%d (%v)", len(migrations), version, err)
+	}
+	got, ok, err := repo.Get(ctx, domain.RoomID("old"))
+	if err != nil || !ok {
+		t.Fatalf("get migrated room: %v, %v", ok, err)
+	}
+	if got.RoundIndex() != 3 || got.Title() != "" || len(got.Stories()) != 0 || got.DeckName() != domain.DeckTShirt {
+		t.Fatalf("unexpected migrated room: %+v", got.State())
+	}
+
+	// Re-running migrations is a no-op.
+	if err := migrate(ctx, repo.db); err != nil {
+		t.Fatalf("re-migrate: %v", err)
+	}
+}
+
+func TestService_OverSQLite_PersistsCommands(t *testing.T) {
+	ctx := context.Background()
+	repo := openTemp(t, filepath.Join(t.TempDir(), "rooms.db"))
+	svc := &app.Service{Rooms: repo, Ids: fixedIDs{}}
+
+	roomID, err := svc.CreateRoom(ctx, app.WithTitle("Sprint"))
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	pid, err := svc.Join(ctx, roomID, "Alice")
+	if err != nil {
+		t.Fatalf("join: %v", err)
+	}
+	if err := svc.Cast(ctx, roomID, pid, "8"); err != nil {
+		t.Fatalf("cast: %v", err)
+	}
+	if err := svc.Reveal(ctx, roomID, pid); err != nil {
+		t.Fatalf("reveal: %v", err)
+	}
+	snap, err := svc.Snapshot(ctx, roomID)
+	if err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	if snap.Title != "Sprint" || !snap.Revealed || snap.Votes[pid] != "8" || snap.Facilitator != pid {
+		t.Fatalf("commands not persisted: %+v", snap)
+	}
+}
+
+type fixedIDs struct{}
+
+func (fixedIDs) NewRoomID() domain.RoomID               { return "room" }
+func (fixedIDs) NewParticipantID() domain.ParticipantID { return "p1" }
@@ -4,56 +4,117 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"sync"
+	"time"
 
 	"github.com/jaminalder/estimations/internal/app"
 	"github.com/jaminalder/estimations/internal/domain"
 )
 
-// Hub is a simple in-memory SSE broadcaster implementing app.Broadcaster.
-// It manages room-scoped subscribers that receive marshaled event payloads.
+// EventResync is the name of the message sent instead of a replay when a
+// reconnecting subscriber is too far behind (or ahead) of the room's history:
+// the client must reload the room's full state.
+const EventResync = "Resync"
+
+// DefaultHistory is the number of recent messages kept per room for replay.
+const DefaultHistory = 64
+
+// Message is a single event delivered to subscribers: the room-scoped event
+// ID, the event name (the Go type name of the broadcast value, e.g.
+// "VoteCast") and its JSON payload. IDs start at 1 and increase by one with
+// every broadcast to the room.
+type Message struct {
+	ID    uint64
+	Event string
+	Data  []byte
+}
+
+// Hub is an in-memory SSE broadcaster implementing app.Broadcaster.
+// It manages room-scoped subscribers that receive marshaled event payloads
+// and keeps a bounded history per room so reconnecting clients can catch up.
 type Hub struct {
-	mu      sync.RWMutex
-	rooms   map[domain.RoomID]map[chan []byte]struct{}
+	mu      sync.Mutex
+	rooms   map[domain.RoomID]*room
 	bufSize int
+	history int
 	marshal func(v any) ([]byte, error)
+
+	broadcast uint64 // messages broadcast, across rooms
+	dropped   uint64 // deliveries dropped by disconnecting slow subscribers
+}
+
+// room holds a room's subscribers, its last event ID and its most recent
+// messages, oldest first.
+type room struct {
+	subs   map[chan Message]struct{}
+	last   uint64
+	recent []Message
 }
 
+// HubOption configures a Hub.
+type HubOption func(*Hub)
+
+// WithHistory sets how many recent messages are kept per room for replay
+// (DefaultHistory by default). Zero disables replay: every reconnect resyncs.
+func WithHistory(n int) HubOption { return func(h *Hub) { h.history = max(n, 0) } }
+
 // NewHub creates a hub with the given per-subscriber channel buffer size.
-func NewHub(bufSize int) *Hub {
-	return &Hub{
-		rooms:   make(map[domain.RoomID]map[chan []byte]struct{}),
+func NewHub(bufSize int, opts ...HubOption) *Hub {
+	h := &Hub{
+		rooms:   make(map[domain.RoomID]*room),
 		bufSize: bufSize,
+		history: DefaultHistory,
 		marshal: json.Marshal,
 	}
+	for _, o := range opts {
+		o(h)
+	}
+	return h
 }
 
 var _ app.Broadcaster = (*Hub)(nil)
 
 // Subscribe registers a new subscriber for a room and returns a receive-only
-// channel and an unsubscribe function. The channel will receive JSON-encoded
-// event payloads.
-func (h *Hub) Subscribe(roomID domain.RoomID) (<-chan []byte, func()) {
-	ch := make(chan []byte, h.bufSize)
+// channel and an unsubscribe function. The channel will receive named,
+// JSON-encoded event payloads.
+//
+// A non-zero lastEventID is the ID of the last message the client saw: the
+// messages after it are queued on the channel first. If they are no longer
+// in the room's history (or the ID is unknown), a single EventResync message
+// carrying the latest ID is queued instead.
+//
+// The channel is closed on unsubscribe, when the room expires, and when the
+// subscriber falls a full buffer behind; the client should then reconnect.
+func (h *Hub) Subscribe(roomID domain.RoomID, lastEventID uint64) (<-chan Message, func()) {
+	ch := make(chan Message, h.bufSize)
 	h.mu.Lock()
-	subs, ok := h.rooms[roomID]
-	if !ok {
-		subs = make(map[chan []byte]struct{})
-		h.rooms[roomID] = subs
+	rm := h.room(roomID)
+	if lastEventID != 0 {
+		missed, ok := rm.since(lastEventID)
+		if !ok || len(missed) > h.bufSize {
+			missed = []Message{{ID: rm.last, Event: EventResync, Data: []byte("{}")}}
+		}
+		for _, msg := range missed {
+			ch <- msg
+		}
 	}
-	subs[ch] = struct{}{}
+	rm.subs[ch] = struct{}{}
 	h.mu.Unlock()
 
-	// Unsubscribe closes the channel and removes it from the set.
+	// Unsubscribe closes the channel and removes it from the set, unless the
+	// hub already did. A room that never had a broadcast is forgotten with
+	// its last subscriber: it has no history to keep, and it may be one that
+	// expired while the subscriber was connecting.
 	unsubscribe := func() {
 		h.mu.Lock()
-		if subs, ok := h.rooms[roomID]; ok {
-			if _, present := subs[ch]; present {
-				delete(subs, ch)
+		if rm, ok := h.rooms[roomID]; ok {
+			if _, present := rm.subs[ch]; present {
+				delete(rm.subs, ch)
 				close(ch)
-				if len(subs) == 0 {
-					delete(h.rooms, roomID)
-				}
+			}
+			if len(rm.subs) == 0 && rm.last == 0 {
+				delete(h.rooms, roomID)
 			}
 		}
 		h.mu.Unlock()
@@ -61,35 +122,135 @@ func (h *Hub) Subscribe(roomID domain.RoomID) (<-chan []byte, func()) {
 	return ch, unsubscribe
 }
 
-// Broadcast marshals the event to JSON and fan-outs to all subscribers
-// registered to the given roomID. It is best-effort: if a subscriber's
-// channel buffer is full, the message is dropped for that subscriber to avoid
-// blocking other recipients.
+// Broadcast marshals the event to JSON, assigns it the room's next event ID,
+// records it in the room's history and fans it out to all subscribers of the
+// room. Broadcasting never blocks: a subscriber whose buffer is full is
+// disconnected instead, so it reconnects and catches up from the history.
+// Broadcasting app.RoomExpired disconnects all subscribers after delivery
+// and forgets the room.
 func (h *Hub) Broadcast(ctx context.Context, roomID domain.RoomID, event any) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	// Marshal outside the lock
 	payload, err := h.marshal(event)
 	if err != nil {
 		return fmt.Errorf("marshal event: %w", err)
 	}
 
-	h.mu.RLock()
-	subs := h.rooms[roomID]
-	// Copy keys to avoid holding lock while sending
-	var chans []chan []byte
-	for ch := range subs {
-		chans = append(chans, ch)
+	// Sending under the lock keeps every subscriber's messages in ID order;
+	// sends never block, so the lock is held only briefly.
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	rm := h.room(roomID)
+	rm.last++
+	h.broadcast++
+	msg := Message{ID: rm.last, Event: eventName(event), Data: payload}
+	rm.remember(msg, h.history)
+	for ch := range rm.subs {
+		select {
+		case ch <- msg:
+		default:
+			delete(rm.subs, ch)
+			close(ch)
+			h.dropped++
+		}
+	}
+	if _, expired := event.(app.RoomExpired); expired {
+		for ch := range rm.subs {
+			close(ch)
+		}
+		delete(h.rooms, roomID)
+	}
+	return nil
+}
+
+// Stats is a point-in-time view of a hub's activity.
+type Stats struct {
+	Broadcast   uint64                // messages broadcast since the hub was created
+	Dropped     uint64                // deliveries dropped by disconnecting slow subscribers
+	Subscribers map[domain.RoomID]int // current subscribers of rooms that have any
+}
+
+// Stats returns the hub's counters and current subscribers.
+func (h *Hub) Stats() Stats {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	st := Stats{Broadcast: h.broadcast, Dropped: h.dropped, Subscribers: make(map[domain.RoomID]int)}
+	for id, rm := range h.rooms {
+		if len(rm.subs) > 0 {
+			st.Subscribers[id] = len(rm.subs)
+		}
 	}
-	h.mu.RUnlock()
+	return st
+}
 
-	for _, ch := range chans {
+// Ping reports whether the hub is responsive, for health checks: it fails if
+// the hub's lock cannot be taken before ctx is done. Broadcasts hold the lock
+// only briefly, so a lock held that long means the hub is wedged.
+func (h *Hub) Ping(ctx context.Context) error {
+	t := time.NewTicker(time.Millisecond)
+	defer t.Stop()
+	for !h.mu.TryLock() {
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
-		case ch <- payload:
-			// sent
-		default:
-			// drop if subscriber is slow
+			return fmt.Errorf("hub unresponsive: %w", ctx.Err())
+		case <-t.C:
 		}
 	}
+	h.mu.Unlock()
 	return nil
 }
+
+// room returns the state of the given room, creating it if needed. Callers
+// hold h.mu.
+func (h *Hub) room(roomID domain.RoomID) *room {
+	rm, ok := h.rooms[roomID]
+	if !ok {
+		rm = &room{subs: make(map[chan Message]struct{})}
+		h.rooms[roomID] = rm
+	}
+	return rm
+}
+
+// remember appends msg to the history, dropping the oldest message beyond
+// limit.
+func (rm *room) remember(msg Message, limit int) {
+	if limit == 0 {
+		return
+	}
+	if len(rm.recent) >= limit {
+		rm.recent = append(rm.recent[:0], rm.recent[len(rm.recent)-limit+1:]...)
+	}
+	rm.recent = append(rm.recent, msg)
+}
+
+// since returns the messages after the given ID, and false if some of them
+// are no longer in the history or the ID was never issued.
+func (rm *room) since(id uint64) ([]Message, bool) {
+	if id > rm.last {
+		return nil, false
+	}
+	if id == rm.last {
+		return nil, true
+	}
+	if len(rm.recent) == 0 || rm.recent[0].ID > id+1 {
+		return nil, false
+	}
+	return append([]Message(nil), rm.recent[id+1-rm.recent[0].ID:]...), true
+}
+
+// eventName derives the SSE event name from the dynamic type of the event.
+func eventName(event any) string {
+	t := reflect.TypeOf(event)
+	if t == nil {
+		return "message"
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Name() == "" {
+		return "message"
+	}
+	return t.Name()
+}
@@ -2,8 +2,12 @@ package sse
 
 import (
 	"context"
+	"errors"
+	"slices"
 	"testing"
+	"time"
 
+	"github.com/jaminalder/estimations/internal/app"
 	"github.com/jaminalder/estimations/internal/domain"
 )
 
@@ -11,8 +15,8 @@ func TestHub_SubscribeBroadcastUnsubscribe(t *testing.T) {
 	h := NewHub(4)
 	room := domain.RoomID("r1")
 
-	c1, u1 := h.Subscribe(room)
-	c2, u2 := h.Subscribe(room)
+	c1, u1 := h.Subscribe(room, 0)
+	c2, u2 := h.Subscribe(room, 0)
 
 	t.Cleanup(func() { u1(); u2() })
 
@@ -24,7 +28,7 @@ func TestHub_SubscribeBroadcastUnsubscribe(t *testing.T) {
 	// Both should receive something
 	got1 := <-c1
 	got2 := <-c2
-	if len(got1) == 0 || len(got2) == 0 {
+	if len(got1.Data) == 0 || len(got2.Data) == 0 {
 		t.Fatalf("expected payloads on both subscribers")
 	}
 
@@ -35,3 +39,133 @@ func TestHub_SubscribeBroadcastUnsubscribe(t *testing.T) {
 	}
 	<-c1 // c1 still receives
 }
+
+func TestHub_MessageNamedAfterEventType(t *testing.T) {
+	h := NewHub(4)
+	room := domain.RoomID("r1")
+	ch, unsubscribe := h.Subscribe(room, 0)
+	defer unsubscribe()
+
+	type VoteCast struct{ Card string }
+	if err := h.Broadcast(context.Background(), room, VoteCast{Card: "5"}); err != nil {
+		t.Fatalf("broadcast: %v", err)
+	}
+	if msg := <-ch; msg.Event != "VoteCast" {
+		t.Fatalf("event name: got %q want VoteCast", msg.Event)
+	}
+	if err := h.Broadcast(context.Background(), room, &VoteCast{Card: "8"}); err != nil {
+		t.Fatalf("broadcast: %v", err)
+	}
+	if msg := <-ch; msg.Event != "VoteCast" {
+		t.Fatalf("pointer event name: got %q want VoteCast", msg.Event)
+	}
+}
+
+func TestHub_IDsAndReplay(t *testing.T) {
+	h := NewHub(8, WithHistory(3))
+	room := domain.RoomID("r1")
+	ctx := context.Background()
+	for i := range 5 {
+		if err := h.Broadcast(ctx, room, struct{ N int }{N: i}); err != nil {
+			t.Fatalf("broadcast: %v", err)
+		}
+	}
+
+	ids := func(lastID uint64) []uint64 {
+		ch, unsubscribe := h.Subscribe(room, lastID)
+		unsubscribe()
+		var out []uint64
+		for msg := range ch {
+			if msg.Event == EventResync {
+				return []uint64{0, msg.ID}
+			}
+			out = append(out, msg.ID)
+		}
+		return out
+	}
+	for _, tc := range []struct {
+		last uint64
+		want []uint64
+	}{
+		{0, nil}, // fresh subscriber: no replay
+		{5, nil}, // up to date
+		{3, []uint64{4, 5}},
+		{2, []uint64{3, 4, 5}},
+		{1, []uint64{0, 5}}, // event 2 is gone: resync
+		{9, []uint64{0, 5}}, // never issued: resync
+	} {
+		if got := ids(tc.last); !slices.Equal(got, tc.want) {
+			t.Fatalf("last %d: got %v want %v", tc.last, got, tc.want)
+		}
+	}
+}
+
+func TestHub_SlowSubscriberDisconnected(t *testing.T) {
+	h := NewHub(1)
+	room := domain.RoomID("r1")
+	slow, u1 := h.Subscribe(room, 0)
+	fast, u2 := h.Subscribe(room, 0)
+	defer u1()
+	defer u2()
+
+	for i := range 2 {
+		if err := h.Broadcast(context.Background(), room, struct{ N int }{N: i}); err != nil {
+			t.Fatalf("broadcast: %v", err)
+		}
+		if i == 0 {
+			<-fast
+		}
+	}
+	if msg := <-slow; msg.ID != 1 {
+		t.Fatalf("slow subscriber: got id %d want 1", msg.ID)
+	}
+	if _, open := <-slow; open {
+		t.Fatalf("slow subscriber should be disconnected instead of missing events")
+	}
+	if msg := <-fast; msg.ID != 2 {
+		t.Fatalf("fast subscriber: got id %d want 2", msg.ID)
+	}
+}
+
+func TestHub_RoomExpiredClosesStreams(t *testing.T) {
+	h := NewHub(4)
+	room := domain.RoomID("r1")
+	ch, unsubscribe := h.Subscribe(room, 0)
+	defer unsubscribe()
+
+	if err := h.Broadcast(context.Background(), room, app.RoomExpired{RoomID: room}); err != nil {
+		t.Fatalf("broadcast: %v", err)
+	}
+	if msg := <-ch; msg.Event != "RoomExpired" {
+		t.Fatalf("expected RoomExpired, got %q", msg.Event)
+	}
+	if _, open := <-ch; open {
+		t.Fatalf("stream should close once the room expired")
+	}
+	// The room's history is gone: a reconnect must resync.
+	again, u := h.Subscribe(room, 1)
+	if msg := <-again; msg.Event != EventResync {
+		t.Fatalf("expected resync after expiry, got %q", msg.Event)
+	}
+	// Nothing is kept for the expired room once that subscriber leaves.
+	u()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.rooms) != 0 {
+		t.Fatalf("hub should forget the expired room, has %d rooms", len(h.rooms))
+	}
+}
+
+func TestHub_Ping(t *testing.T) {
+	h := NewHub(4)
+	if err := h.Ping(context.Background()); err != nil {
+		t.Fatalf("ping: %v", err)
+	}
+	h.mu.Lock() // a wedged hub
+	defer h.mu.Unlock()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := h.Ping(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("ping wedged hub: got %v", err)
+	}
+}
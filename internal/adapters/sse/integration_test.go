@@ -26,18 +26,22 @@ func TestIntegration_JoinBroadcastsToSSE(t *testing.T) {
 		t.Fatalf("create room: %v", err)
 	}
 
-	ch, unsubscribe := hub.Subscribe(roomID)
+	ch, unsubscribe := hub.Subscribe(roomID, 0)
 	defer unsubscribe()
 
 	if _, err := svc.Join(ctx, roomID, "Alice"); err != nil {
 		t.Fatalf("join: %v", err)
 	}
-	payload := <-ch
+	msg := <-ch
+	if msg.Event != "ParticipantJoined" {
+		t.Fatalf("unexpected event name: %q", msg.Event)
+	}
+	payload := msg.Data
 	if len(payload) == 0 {
 		t.Fatalf("expected payload bytes from hub")
 	}
-	// quick check payload mentions the participant id and name
-	if string(payload) == "" || !containsAll(string(payload), []string{"\"ParticipantID\":\"p1\"", "\"Name\":\"Alice\""}) {
+	// The payload names the participant by public handle, never by ID.
+	if !containsAll(string(payload), []string{"\"Participant\":\"" + app.PublicID("p1") + "\"", "\"Name\":\"Alice\""}) || contains(string(payload), "ParticipantID") {
 		t.Fatalf("unexpected payload: %s", string(payload))
 	}
 }
@@ -0,0 +1,205 @@
+package app
+
+import (
+	"context"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jaminalder/estimations/internal/domain"
+)
+
+// MaxPasscodeLen is the maximum length of a room passcode, in characters.
+const MaxPasscodeLen = 64
+
+var (
+	// ErrWrongPasscode is returned by Join when the room has a passcode and
+	// the one given does not match.
+	ErrWrongPasscode = errors.New("wrong passcode")
+	// ErrInvalidPasscode is returned by CreateRoom for an unusable passcode.
+	ErrInvalidPasscode = errors.New("invalid passcode")
+	// ErrTooManyAttempts is returned by Join and Rejoin while a room turns
+	// away a client's passcodes or recovery codes after too many wrong ones.
+	ErrTooManyAttempts = errors.New("too many attempts")
+)
+
+// Passcodes are stored as "pbkdf2-sha256$<iterations>$<salt>$<key>", with
+// salt and key in unpadded base64url. Passcodes are short and shared, so the
+// hash only has to keep a leaked database from handing them out for free;
+// the iteration count is kept modest because every join with a passcode
+// checks it (outside the room's lock, see Join).
+const (
+	passcodeScheme     = "pbkdf2-sha256"
+	passcodeIterations = 100_000
+	passcodeKeyLen     = 32
+)
+
+// A client gets at most maxFailures wrong passcodes, and as many wrong
+// recovery codes, per room and failureWindow; its further attempts in the
+// window are refused without being checked, which bounds both guessing and
+// the hashing anyone can make the server do. Clients are told apart by
+// WithClient, so one client's guesses do not keep others out.
+const (
+	maxFailures   = 10
+	failureWindow = time.Minute
+)
+
+type clientKey struct{}
+
+// WithClient returns a context naming the client a use-case runs for, e.g.
+// its network address, so limits on failed attempts apply per client.
+// Without it, all clients share one limit.
+func WithClient(ctx context.Context, client string) context.Context {
+	return context.WithValue(ctx, clientKey{}, client)
+}
+
+func clientOf(ctx context.Context) string {
+	client, _ := ctx.Value(clientKey{}).(string)
+	return client
+}
+
+// hashPasscode validates passcode and returns its hash; "" stays "" (no
+// passcode).
+func hashPasscode(passcode string) (string, error) {
+	if passcode == "" {
+		return "", nil
+	}
+	if utf8.RuneCountInString(passcode) > MaxPasscodeLen {
+		return "", fmt.Errorf("%w: longer than %d characters", ErrInvalidPasscode, MaxPasscodeLen)
+	}
+	salt := make([]byte, 16)
+	_, _ = rand.Read(salt)
+	key, err := pbkdf2.Key(sha256.New, passcode, salt, passcodeIterations, passcodeKeyLen)
+	if err != nil {
+		return "", fmt.Errorf("hash passcode: %w", err)
+	}
+	enc := base64.RawURLEncoding
+	return strings.Join([]string{passcodeScheme, strconv.Itoa(passcodeIterations), enc.EncodeToString(salt), enc.EncodeToString(key)}, "$"), nil
+}
+
+// checkPasscode reports whether passcode matches hash. Every passcode
+// matches an empty hash; nothing matches a malformed one.
+func checkPasscode(hash, passcode string) bool {
+	if hash == "" {
+		return true
+	}
+	parts := strings.Split(hash, "$")
+	if len(parts) != 4 || parts[0] != passcodeScheme {
+		return false
+	}
+	iter, err := strconv.Atoi(parts[1])
+	if err != nil || iter <= 0 {
+		return false
+	}
+	salt, err1 := base64.RawURLEncoding.DecodeString(parts[2])
+	want, err2 := base64.RawURLEncoding.DecodeString(parts[3])
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	got, err := pbkdf2.Key(sha256.New, passcode, salt, iter, len(want))
+	return err == nil && subtle.ConstantTimeCompare(got, want) == 1
+}
+
+// failedAttempts counts, per room and client, the wrong codes given in the
+// current window. Like roomActivity it is kept in memory only.
+type failedAttempts struct {
+	mu      sync.Mutex
+	clients map[attempter]*failures
+}
+
+type attempter struct {
+	room   domain.RoomID
+	client string
+}
+
+type failures struct {
+	since time.Time // start of the window
+	n     int
+}
+
+// allow reports whether the room still checks the client's codes at now.
+func (a *failedAttempts) allow(id domain.RoomID, client string, now time.Time) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	f, ok := a.clients[attempter{id, client}]
+	return !ok || f.n < maxFailures || now.Sub(f.since) >= failureWindow
+}
+
+// fail counts a wrong code the client gave at now, and drops counts
+// whose window has passed.
+func (a *failedAttempts) fail(id domain.RoomID, client string, now time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.clients == nil {
+		a.clients = make(map[attempter]*failures)
+	}
+	for k, f := range a.clients {
+		if now.Sub(f.since) >= failureWindow {
+			delete(a.clients, k)
+		}
+	}
+	key := attempter{id, client}
+	f, ok := a.clients[key]
+	if !ok {
+		f = &failures{since: now}
+		a.clients[key] = f
+	}
+	f.n++
+}
+
+func (a *failedAttempts) forget(id domain.RoomID) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for k := range a.clients {
+		if k.room == id {
+			delete(a.clients, k)
+		}
+	}
+}
+
+// checkJoinPasscode checks passcode against the room's passcode, if it has
+// one and is not locked, and returns the hash it checked against. The hash
+// is read under the room's lock but checked outside it, so guessing at a
+// room's passcode does not hold up the commands of those in it; Join
+// verifies that the hash is still the room's before relying on the outcome.
+func (s *Service) checkJoinPasscode(ctx context.Context, roomID domain.RoomID, passcode string) (hash string, err error) {
+	locked := false
+	if err := s.inRoom(ctx, roomID, func(room *domain.Room) error {
+		hash, locked = room.Passcode(), room.IsLocked()
+		return nil
+	}); err != nil || hash == "" || locked {
+		// Join reports a missing or locked room under its own lock.
+		return hash, nil
+	}
+	client := clientOf(ctx)
+	if !s.wrongPasscodes.allow(roomID, client, s.now()) {
+		return hash, ErrTooManyAttempts
+	}
+	if !checkPasscode(hash, passcode) {
+		s.wrongPasscodes.fail(roomID, client, s.now())
+		return hash, ErrWrongPasscode
+	}
+	return hash, nil
+}
+
+// LockRoom locks the room against new participants, or unlocks it, and
+// broadcasts RoomLocked. Facilitator only. Participants already in the room,
+// including those rejoining with their recovery code, are not affected.
+func (s *Service) LockRoom(ctx context.Context, roomID domain.RoomID, by domain.ParticipantID, locked bool) error {
+	return s.withRoom(ctx, &command{name: "lock_room", room: roomID, by: by}, func(room *domain.Room) error {
+		if err := room.SetLocked(by, locked); err != nil {
+			return fmt.Errorf("lock room: %w", err)
+		}
+		s.emit(roomID, RoomLocked{RoomID: roomID, Locked: locked})
+		return nil
+	})
+}
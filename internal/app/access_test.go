@@ -0,0 +1,104 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jaminalder/estimations/internal/domain"
+)
+
+func TestCreateRoom_PasscodeStoredHashed(t *testing.T) {
+	ctx := context.Background()
+	repo := &repoMem{}
+	svc := &Service{Rooms: repo, Ids: idFixed{rid: "r1"}}
+
+	if _, err := svc.CreateRoom(ctx, WithPasscode(strings.Repeat("x", MaxPasscodeLen+1))); !errors.Is(err, ErrInvalidPasscode) {
+		t.Fatalf("long passcode: got %v want ErrInvalidPasscode", err)
+	}
+	id, err := svc.CreateRoom(ctx, WithPasscode("open sesame"))
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	hash := repo.rooms[id].Passcode()
+	if hash == "" || strings.Contains(hash, "open sesame") {
+		t.Fatalf("passcode should be stored hashed, got %q", hash)
+	}
+	if !checkPasscode(hash, "open sesame") || checkPasscode(hash, "open sesame ") || checkPasscode("garbage", "") {
+		t.Fatalf("checkPasscode should match exactly the hashed passcode")
+	}
+}
+
+func TestJoin_PasscodeAndLock(t *testing.T) {
+	ctx := context.Background()
+	repo := &joinRepo{}
+	bus := &captureBroadcaster{}
+	svc := &Service{Rooms: repo, Bus: bus, Ids: &seqIDs{}}
+	roomID, _ := svc.CreateRoom(ctx, WithPasscode("s3cret"))
+
+	for _, opts := range [][]JoinOption{nil, {UsingPasscode("S3CRET")}} {
+		if _, err := svc.Join(ctx, roomID, "Mallory", opts...); !errors.Is(err, ErrWrongPasscode) {
+			t.Fatalf("join without the passcode: got %v want ErrWrongPasscode", err)
+		}
+	}
+	alice, err := svc.Join(ctx, roomID, "Alice", UsingPasscode("s3cret"))
+	if err != nil {
+		t.Fatalf("join with passcode: %v", err)
+	}
+	bob, _ := svc.Join(ctx, roomID, "Bob", UsingPasscode("s3cret"))
+
+	bus.events = nil
+	if err := svc.LockRoom(ctx, roomID, bob, true); !errors.Is(err, domain.ErrNotFacilitator) {
+		t.Fatalf("lock by non-facilitator: got %v", err)
+	}
+	if err := svc.LockRoom(ctx, roomID, alice, true); err != nil {
+		t.Fatalf("lock: %v", err)
+	}
+	if len(bus.events) != 1 || bus.events[0] != (RoomLocked{RoomID: roomID, Locked: true}) {
+		t.Fatalf("want one RoomLocked, got %#v", bus.events)
+	}
+	// Even the right passcode does not open a locked room.
+	if _, err := svc.Join(ctx, roomID, "Olga", UsingPasscode("s3cret")); !errors.Is(err, domain.ErrRoomLocked) {
+		t.Fatalf("join locked room: got %v want ErrRoomLocked", err)
+	}
+	if snap, _ := svc.Snapshot(ctx, roomID); !snap.Locked || !snap.HasPasscode {
+		t.Fatalf("snapshot should show the room locked and with a passcode")
+	}
+	// Participants inside can still come back from another browser.
+	if pid, err := svc.Rejoin(ctx, roomID, RecoveryCode(bob)); err != nil || pid != bob {
+		t.Fatalf("rejoin locked room: %q, %v", pid, err)
+	}
+
+	if err := svc.LockRoom(ctx, roomID, alice, false); err != nil {
+		t.Fatalf("unlock: %v", err)
+	}
+	if _, err := svc.Join(ctx, roomID, "Olga", UsingPasscode("s3cret")); err != nil {
+		t.Fatalf("join unlocked room: %v", err)
+	}
+}
+
+func TestJoin_ThrottlesWrongPasscodes(t *testing.T) {
+	ctx := context.Background()
+	clock := &manualClock{t: time.Date(2025, 9, 5, 9, 0, 0, 0, time.UTC)}
+	svc := &Service{Rooms: &joinRepo{}, Ids: &seqIDs{}, Clock: clock}
+	roomID, _ := svc.CreateRoom(ctx, WithPasscode("s3cret"))
+
+	ctx = WithClient(ctx, "mallory")
+	for i := range maxFailures {
+		if _, err := svc.Join(ctx, roomID, "Mallory", UsingPasscode("guess")); !errors.Is(err, ErrWrongPasscode) {
+			t.Fatalf("guess %d: got %v want ErrWrongPasscode", i, err)
+		}
+	}
+	if _, err := svc.Join(ctx, roomID, "Mallory", UsingPasscode("s3cret")); !errors.Is(err, ErrTooManyAttempts) {
+		t.Fatalf("join after too many guesses: got %v want ErrTooManyAttempts", err)
+	}
+	if _, err := svc.Join(WithClient(ctx, "alice"), roomID, "Alice", UsingPasscode("s3cret")); err != nil {
+		t.Fatalf("another client should not be kept out: %v", err)
+	}
+	clock.Advance(failureWindow)
+	if _, err := svc.Join(ctx, roomID, "Mallory", UsingPasscode("s3cret")); err != nil {
+		t.Fatalf("join after the window: %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+package app
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jaminalder/estimations/internal/domain"
+)
+
+// roomActivity tracks, per room, when it was last active and since when it
+// has been empty. Commands only flag a room as touched; the reaper stamps
+// the flag with the current time on each sweep, so times are accurate to
+// one sweep interval and commands never read the clock for bookkeeping.
+//
+// Activity is kept in memory only: rooms loaded from a persistent repo after
+// a restart start a fresh clock the first time the reaper sees them.
+type roomActivity struct {
+	mu    sync.Mutex
+	rooms map[domain.RoomID]*activity
+}
+
+type activity struct {
+	touched    bool      // a command succeeded since the last sweep
+	last       time.Time // last sweep that saw the room touched
+	emptySince time.Time // first sweep that saw the room empty; zero otherwise
+}
+
+// touch flags a successful command on the room.
+func (a *roomActivity) touch(id domain.RoomID) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.rooms == nil {
+		a.rooms = make(map[domain.RoomID]*activity)
+	}
+	if act, ok := a.rooms[id]; ok {
+		act.touched = true
+		return
+	}
+	a.rooms[id] = &activity{touched: true}
+}
+
+// sweep folds pending touches into timestamps as of now and returns the
+// room's activity; a room seen for the first time counts as touched now.
+func (a *roomActivity) sweep(id domain.RoomID, now time.Time, empty bool) activity {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.rooms == nil {
+		a.rooms = make(map[domain.RoomID]*activity)
+	}
+	act, ok := a.rooms[id]
+	if !ok {
+		act = &activity{touched: true}
+		a.rooms[id] = act
+	}
+	if act.touched {
+		act.last, act.touched = now, false
+	}
+	switch {
+	case !empty:
+		act.emptySince = time.Time{}
+	case act.emptySince.IsZero():
+		act.emptySince = now
+	}
+	return *act
+}
+
+// lastActive returns when the room was last active as of now: now if a
+// command succeeded since the last sweep, else the last sweep that saw one.
+// It is zero for a room no sweep or command has seen yet.
+func (a *roomActivity) lastActive(id domain.RoomID, now time.Time) time.Time {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	act, ok := a.rooms[id]
+	switch {
+	case !ok:
+		return time.Time{}
+	case act.touched:
+		return now
+	}
+	return act.last
+}
+
+func (a *roomActivity) forget(id domain.RoomID) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.rooms, id)
+}
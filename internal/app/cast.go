@@ -9,15 +9,11 @@ import (
 
 // Cast records a participant's vote in the room and broadcasts VoteCast on success.
 func (s *Service) Cast(ctx context.Context, roomID domain.RoomID, participantID domain.ParticipantID, card string) error {
-	room, err := s.getRoom(ctx, roomID)
-	if err != nil {
-		return err
-	}
-	if err := room.CastVote(participantID, card); err != nil {
-		return fmt.Errorf("cast: %w", err)
-	}
-	if err := s.emit(ctx, roomID, VoteCast{RoomID: roomID, ParticipantID: participantID, Card: card}); err != nil {
-		return err
-	}
-	return nil
+	return s.withRoom(ctx, &command{name: "cast", room: roomID, by: participantID}, func(room *domain.Room) error {
+		if err := room.CastVote(participantID, card); err != nil {
+			return fmt.Errorf("cast: %w", err)
+		}
+		s.emit(roomID, VoteCast{RoomID: roomID, ParticipantID: participantID, Card: card})
+		return nil
+	})
 }
@@ -25,11 +25,15 @@ func (r *castRepo) Get(ctx context.Context, id domain.RoomID) (*domain.Room, boo
 	return rm, ok, nil
 }
 
+func (r *castRepo) Save(ctx context.Context, room *domain.Room) error { return nil }
+
 func (r *castRepo) Delete(ctx context.Context, id domain.RoomID) error {
 	delete(r.rooms, id)
 	return nil
 }
 
+func (r *castRepo) List(ctx context.Context) ([]domain.RoomID, error) { return nil, nil }
+
 type eventsSink struct{ events []any }
 
 func (e *eventsSink) Broadcast(ctx context.Context, roomID domain.RoomID, event any) error {
@@ -65,7 +69,8 @@ func TestCast_Success_BroadcastsEvent(t *testing.T) {
 	if !ok {
 		t.Fatalf("wrong event type: %T", bus.events[0])
 	}
-	if evt.RoomID != roomID || evt.ParticipantID != pid || evt.Card != "8" {
+	// Clients learn who voted, not their card or participant ID.
+	if evt != (VoteCast{RoomID: roomID, Participant: PublicID(pid)}) {
 		t.Fatalf("event mismatch: %+v", evt)
 	}
 }
@@ -9,15 +9,11 @@ import (
 
 // Clear removes a participant's current vote and broadcasts VoteCleared on success.
 func (s *Service) Clear(ctx context.Context, roomID domain.RoomID, participantID domain.ParticipantID) error {
-	room, err := s.getRoom(ctx, roomID)
-	if err != nil {
-		return err
-	}
-	if err := room.ClearVote(participantID); err != nil {
-		return fmt.Errorf("clear: %w", err)
-	}
-	if err := s.emit(ctx, roomID, VoteCleared{RoomID: roomID, ParticipantID: participantID}); err != nil {
-		return err
-	}
-	return nil
+	return s.withRoom(ctx, &command{name: "clear", room: roomID, by: participantID}, func(room *domain.Room) error {
+		if err := room.ClearVote(participantID); err != nil {
+			return fmt.Errorf("clear: %w", err)
+		}
+		s.emit(roomID, VoteCleared{RoomID: roomID, ParticipantID: participantID})
+		return nil
+	})
 }
@@ -3,6 +3,7 @@ package app
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/jaminalder/estimations/internal/domain"
 )
@@ -24,11 +25,15 @@ func (r *clearRepo) Get(ctx context.Context, id domain.RoomID) (*domain.Room, bo
 	return rm, ok, nil
 }
 
+func (r *clearRepo) Save(ctx context.Context, room *domain.Room) error { return nil }
+
 func (r *clearRepo) Delete(ctx context.Context, id domain.RoomID) error {
 	delete(r.rooms, id)
 	return nil
 }
 
+func (r *clearRepo) List(ctx context.Context) ([]domain.RoomID, error) { return nil, nil }
+
 type clearBus struct{ events []any }
 
 func (b *clearBus) Broadcast(ctx context.Context, roomID domain.RoomID, event any) error {
@@ -100,7 +105,7 @@ func TestClear_WhileRevealed_NoBroadcast(t *testing.T) {
 	if err := room.CastVote(pid, "5"); err != nil {
 		t.Fatalf("cast: %v", err)
 	}
-	if err := room.Reveal(); err != nil {
+	if err := room.Reveal(pid, time.Now()); err != nil {
 		t.Fatalf("reveal: %v", err)
 	}
 
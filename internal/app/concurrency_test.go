@@ -0,0 +1,202 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jaminalder/estimations/internal/domain"
+)
+
+// syncRepo is a goroutine-safe fake that, like memory.RoomRepo, hands out the
+// same *domain.Room pointer to every caller.
+type syncRepo struct {
+	mu    sync.Mutex
+	rooms map[domain.RoomID]*domain.Room
+}
+
+func (r *syncRepo) Create(ctx context.Context, room *domain.Room) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.rooms == nil {
+		r.rooms = make(map[domain.RoomID]*domain.Room)
+	}
+	r.rooms[room.ID()] = room
+	return nil
+}
+
+func (r *syncRepo) Get(ctx context.Context, id domain.RoomID) (*domain.Room, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rm, ok := r.rooms[id]
+	return rm, ok, nil
+}
+
+func (r *syncRepo) Save(ctx context.Context, room *domain.Room) error { return nil }
+
+func (r *syncRepo) Delete(ctx context.Context, id domain.RoomID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.rooms, id)
+	return nil
+}
+
+func (r *syncRepo) List(ctx context.Context) ([]domain.RoomID, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ids := make([]domain.RoomID, 0, len(r.rooms))
+	for id := range r.rooms {
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// seqIDs generates unique participant ids safely from many goroutines.
+type seqIDs struct{ n atomic.Int64 }
+
+func (s *seqIDs) NewRoomID() domain.RoomID { return "room" }
+func (s *seqIDs) NewParticipantID() domain.ParticipantID {
+	return domain.ParticipantID(fmt.Sprintf("p%d", s.n.Add(1)))
+}
+
+// syncBus records events from many goroutines.
+type syncBus struct {
+	mu     sync.Mutex
+	events []any
+}
+
+func (b *syncBus) Broadcast(ctx context.Context, roomID domain.RoomID, event any) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.events = append(b.events, event)
+	return nil
+}
+
+// Run with -race: many goroutines join, vote, clear, reveal, reset and read
+// the same room concurrently.
+func TestService_ConcurrentCommands_SameRoom(t *testing.T) {
+	ctx := context.Background()
+	bus := &syncBus{}
+	svc := &Service{Rooms: &syncRepo{}, Ids: &seqIDs{}, Bus: bus}
+	roomID, err := svc.CreateRoom(ctx)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	// The host joins first so it is the facilitator driving reveal/reset.
+	host, err := svc.Join(ctx, roomID, "Host")
+	if err != nil {
+		t.Fatalf("host join: %v", err)
+	}
+
+	const players = domain.MaxParticipants - 1
+	const iterations = 50
+	cards := []string{"1", "2", "3", "5", "8", "?"}
+
+	var wg sync.WaitGroup
+	pids := make(chan domain.ParticipantID, players)
+	for i := 0; i < players; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			pid, err := svc.Join(ctx, roomID, fmt.Sprintf("Player %d", i))
+			if err != nil {
+				t.Errorf("join %d: %v", i, err)
+				return
+			}
+			pids <- pid
+			for j := 0; j < iterations; j++ {
+				// Errors are expected while the round is revealed; we only
+				// care that nothing races or panics.
+				_ = svc.Cast(ctx, roomID, pid, cards[(i+j)%len(cards)])
+				if j%5 == 0 {
+					_ = svc.Clear(ctx, roomID, pid)
+				}
+			}
+		}(i)
+	}
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for j := 0; j < iterations; j++ {
+			_ = svc.Cast(ctx, roomID, host, "5")
+			_ = svc.Reveal(ctx, roomID, host)
+			_ = svc.Reset(ctx, roomID, host)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for j := 0; j < iterations; j++ {
+			if _, err := svc.Snapshot(ctx, roomID); err != nil {
+				t.Errorf("snapshot: %v", err)
+			}
+		}
+	}()
+	wg.Wait()
+	close(pids)
+
+	snap, err := svc.Snapshot(ctx, roomID)
+	if err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	if len(snap.Participants) != players+1 {
+		t.Fatalf("expected %d participants, got %d", players+1, len(snap.Participants))
+	}
+	if snap.Facilitator != host {
+		t.Fatalf("host should remain facilitator, got %q", snap.Facilitator)
+	}
+	for pid := range pids {
+		if !snap.HasParticipant(pid) {
+			t.Fatalf("participant %s missing after concurrent joins", pid)
+		}
+	}
+	for pid := range snap.Votes {
+		if !snap.HasParticipant(pid) {
+			t.Fatalf("vote recorded for unknown participant %s", pid)
+		}
+	}
+}
+
+// Capacity must hold even when more players than seats join at once.
+func TestService_ConcurrentJoins_RespectCapacity(t *testing.T) {
+	ctx := context.Background()
+	svc := &Service{Rooms: &syncRepo{}, Ids: &seqIDs{}, Bus: &syncBus{}}
+	roomID, err := svc.CreateRoom(ctx)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	var joined atomic.Int64
+	var wg sync.WaitGroup
+	for i := 0; i < domain.MaxParticipants*2; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, err := svc.Join(ctx, roomID, fmt.Sprintf("Player %d", i)); err == nil {
+				joined.Add(1)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if got := joined.Load(); got != domain.MaxParticipants {
+		t.Fatalf("expected exactly %d successful joins, got %d", domain.MaxParticipants, got)
+	}
+	snap, _ := svc.Snapshot(ctx, roomID)
+	if len(snap.Participants) != domain.MaxParticipants {
+		t.Fatalf("room holds %d participants, want %d", len(snap.Participants), domain.MaxParticipants)
+	}
+}
+
+func TestRoomLocks_ReleasesEntries(t *testing.T) {
+	var l roomLocks
+	unlockA := l.lock("a")
+	unlockB := l.lock("b")
+	unlockA()
+	unlockB()
+	if len(l.rooms) != 0 {
+		t.Fatalf("expected lock table to be empty, got %d entries", len(l.rooms))
+	}
+}
@@ -2,17 +2,83 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/jaminalder/estimations/internal/domain"
 )
 
-// CreateRoom creates a new room with a generated ID and persists it.
-func (s *Service) CreateRoom(ctx context.Context) (domain.RoomID, error) {
-	id := s.Ids.NewRoomID()
-	room := domain.NewRoom(id)
-	if err := s.Rooms.Create(ctx, room); err != nil {
+type (
+	createConfig struct {
+		deck     domain.Deck
+		title    string
+		passcode string
+	}
+	// CreateOption customizes a room at creation time.
+	CreateOption func(*createConfig)
+)
+
+// WithDeck selects the deck the room votes with (default: Fibonacci).
+func WithDeck(d domain.Deck) CreateOption { return func(c *createConfig) { c.deck = d } }
+
+// WithTitle sets the room's session title.
+func WithTitle(title string) CreateOption { return func(c *createConfig) { c.title = title } }
+
+// WithPasscode requires newcomers to know passcode to join the room. It is
+// stored hashed; an empty passcode means none.
+func WithPasscode(passcode string) CreateOption {
+	return func(c *createConfig) { c.passcode = passcode }
+}
+
+// createAttempts bounds how many generated IDs CreateRoom tries when the
+// repository reports them taken.
+const createAttempts = 5
+
+// CreateRoom creates a new room with a generated ID and persists it. Should
+// the ID be taken by the time the room is stored, it retries with a new one.
+func (s *Service) CreateRoom(ctx context.Context, opts ...CreateOption) (_ domain.RoomID, err error) {
+	cmd := &command{name: "create_room"}
+	defer s.observe(ctx, cmd, time.Now(), &err)
+	var cfg createConfig
+	for _, o := range opts {
+		o(&cfg)
+	}
+	room := domain.NewRoomWithDeck(s.Ids.NewRoomID(), cfg.deck)
+	if err := room.SetTitle(cfg.title); err != nil {
+		return "", fmt.Errorf("create room: %w", err)
+	}
+	hash, err := hashPasscode(cfg.passcode)
+	if err != nil {
+		return "", fmt.Errorf("create room: %w", err)
+	}
+	room.SetPasscode(hash)
+	for attempt := 1; ; attempt++ {
+		cmd.room = room.ID()
+		unlock := s.locks.lock(room.ID())
+		err := s.Rooms.Create(ctx, room)
+		if err == nil {
+			defer unlock()
+			break
+		}
+		unlock()
+		if !errors.Is(err, ErrRoomExists) || attempt == createAttempts {
+			return "", fmt.Errorf("create room: %w", err)
+		}
+		// A concurrent create took the ID between its generation and now.
+		st := room.State()
+		st.ID = s.Ids.NewRoomID()
+		if room, err = domain.RestoreRoom(st); err != nil {
+			return "", fmt.Errorf("create room: %w", err)
+		}
+	}
+	id := room.ID()
+	// Recorded only: nobody can be subscribed to the new room yet.
+	s.emit(id, RoomCreated{RoomID: id, Title: room.Title(), Deck: room.DeckName(), Cards: room.Deck(), Passcode: hash})
+	if _, err := s.commit(ctx, room); err != nil {
+		_ = s.Rooms.Delete(ctx, id)
 		return "", fmt.Errorf("create room: %w", err)
 	}
+	s.activity.touch(id)
 	return id, nil
 }
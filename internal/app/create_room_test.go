@@ -2,6 +2,7 @@ package app
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/jaminalder/estimations/internal/domain"
@@ -15,6 +16,9 @@ func (r *repoMem) Create(ctx context.Context, room *domain.Room) error {
 	if r.rooms == nil {
 		r.rooms = make(map[domain.RoomID]*domain.Room)
 	}
+	if _, taken := r.rooms[room.ID()]; taken {
+		return ErrRoomExists
+	}
 	r.rooms[room.ID()] = room
 	return nil
 }
@@ -24,11 +28,15 @@ func (r *repoMem) Get(ctx context.Context, id domain.RoomID) (*domain.Room, bool
 	return rm, ok, nil
 }
 
+func (r *repoMem) Save(ctx context.Context, room *domain.Room) error { return nil }
+
 func (r *repoMem) Delete(ctx context.Context, id domain.RoomID) error {
 	delete(r.rooms, id)
 	return nil
 }
 
+func (r *repoMem) List(ctx context.Context) ([]domain.RoomID, error) { return nil, nil }
+
 type idFixed struct{ rid domain.RoomID }
 
 func (i idFixed) NewRoomID() domain.RoomID               { return i.rid }
@@ -73,3 +81,69 @@ func TestCreateRoom_Basics(t *testing.T) {
 		}
 	}
 }
+
+// idList hands out the given room IDs in turn, repeating the last.
+type idList struct{ rids []domain.RoomID }
+
+func (l *idList) NewRoomID() domain.RoomID {
+	id := l.rids[0]
+	if len(l.rids) > 1 {
+		l.rids = l.rids[1:]
+	}
+	return id
+}
+func (l *idList) NewParticipantID() domain.ParticipantID { return "p-fixed" }
+
+func TestCreateRoom_RetriesTakenID(t *testing.T) {
+	ctx := context.Background()
+	// "taken" was free when generated, but another create stored it first.
+	repo := &repoMem{rooms: map[domain.RoomID]*domain.Room{"taken": domain.NewRoom("taken")}}
+	svc := &Service{Rooms: repo, Ids: &idList{rids: []domain.RoomID{"taken", "free"}}}
+
+	id, err := svc.CreateRoom(ctx, WithTitle("Sprint"))
+	if err != nil || id != "free" {
+		t.Fatalf("create: got %q, %v; want the next ID", id, err)
+	}
+	if snap, _ := svc.Snapshot(ctx, id); snap.Title != "Sprint" {
+		t.Fatalf("retried room lost its settings: %+v", snap)
+	}
+
+	// An ID generator that keeps repeating a taken ID gives up.
+	svc.Ids = idFixed{rid: "taken"}
+	if _, err := svc.CreateRoom(ctx); !errors.Is(err, ErrRoomExists) {
+		t.Fatalf("create with only taken IDs: got %v, want ErrRoomExists", err)
+	}
+}
+
+func TestCreateRoom_WithDeck(t *testing.T) {
+	ctx := context.Background()
+	repo := &repoMem{}
+	svc := &Service{Rooms: repo, Ids: idFixed{rid: domain.RoomID("room-tee")}}
+
+	tshirt, _ := domain.PresetDeck(domain.DeckTShirt)
+	id, err := svc.CreateRoom(ctx, WithDeck(tshirt))
+	if err != nil {
+		t.Fatalf("CreateRoom error: %v", err)
+	}
+	snap, err := svc.Snapshot(ctx, id)
+	if err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	if snap.DeckName != domain.DeckTShirt || snap.Deck[0] != "XS" {
+		t.Fatalf("expected t-shirt deck, got %q %v", snap.DeckName, snap.Deck)
+	}
+}
+
+func TestCreateRoom_WithTitle(t *testing.T) {
+	ctx := context.Background()
+	svc := &Service{Rooms: &repoMem{}, Ids: idFixed{rid: domain.RoomID("room-t")}}
+
+	id, err := svc.CreateRoom(ctx, WithTitle("  Sprint 12  "))
+	if err != nil {
+		t.Fatalf("CreateRoom error: %v", err)
+	}
+	snap, _ := svc.Snapshot(ctx, id)
+	if snap.Title != "Sprint 12" {
+		t.Fatalf("expected trimmed title, got %q", snap.Title)
+	}
+}
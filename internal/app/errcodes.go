@@ -0,0 +1,57 @@
+package app
+
+import (
+	"errors"
+
+	"github.com/jaminalder/estimations/internal/domain"
+)
+
+// ErrorCode names an error use-cases are expected to fail with. Code is a
+// stable identifier adapters report, e.g. in API error bodies and metrics.
+type ErrorCode struct {
+	Err  error
+	Code string
+}
+
+// ErrorCodes lists every expected error, most specific first. Any other
+// error is internal. A new sentinel is added here, and adapters decide how
+// to present it (see the HTTP adapter's commandErrors).
+var ErrorCodes = []ErrorCode{
+	{ErrRoomNotFound, "room_not_found"},
+	{ErrInvalidRecoveryCode, "invalid_recovery_code"},
+	{ErrWrongPasscode, "wrong_passcode"},
+	{ErrTooManyAttempts, "too_many_attempts"},
+	{domain.ErrNotFacilitator, "not_facilitator"},
+	{domain.ErrNotParticipant, "not_participant"},
+	{domain.ErrObserverCannotVote, "observer_cannot_vote"},
+	{domain.ErrDuplicateName, "duplicate_name"},
+	{domain.ErrRoomLocked, "room_locked"},
+	{domain.ErrRoomFull, "room_full"},
+	{domain.ErrVotingClosed, "voting_closed"},
+	{domain.ErrNoVotes, "no_votes"},
+	{domain.ErrNoCurrentStory, "no_current_story"},
+	{domain.ErrTooManyStories, "too_many_stories"},
+	{domain.ErrCreatorJoined, "creator_joined"},
+	{domain.ErrInvalidName, "invalid_name"},
+	{domain.ErrInvalidCard, "invalid_card"},
+	{domain.ErrInvalidLabel, "invalid_label"},
+	{domain.ErrInvalidTitle, "invalid_title"},
+	{domain.ErrInvalidStory, "invalid_story"},
+	{domain.ErrInvalidEstimate, "invalid_estimate"},
+	{domain.ErrInvalidDeck, "invalid_deck"},
+	{ErrInvalidPasscode, "invalid_passcode"},
+}
+
+// InternalCode is the code of errors not in ErrorCodes.
+const InternalCode = "internal"
+
+// CodeOf returns the entry of ErrorCodes that err matches (errors.Is), or
+// false for an internal error.
+func CodeOf(err error) (ErrorCode, bool) {
+	for _, ec := range ErrorCodes {
+		if errors.Is(err, ec.Err) {
+			return ec, true
+		}
+	}
+	return ErrorCode{Code: InternalCode}, false
+}
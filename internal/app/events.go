@@ -1,40 +1,160 @@
 package app
 
-import "github.com/jaminalder/estimations/internal/domain"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/jaminalder/estimations/internal/domain"
+)
+
+// Events name participants by ID, which doubles as the participant's
+// credential. Only the event log sees them as emitted: clients are sent the
+// event's public form (see publicEvent), which names participants by their
+// PublicID instead and leaves out votes that are not yet revealed.
+
+// PublicID is the handle under which clients and logs see a participant: a
+// short hash of the participant ID, stable for the participant's lifetime
+// but useless as a credential.
+func PublicID(id domain.ParticipantID) string {
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:6])
+}
+
+// publicEvent is implemented by events with fields clients must not see;
+// public returns the copy that is broadcast.
+type publicEvent interface {
+	public() any
+}
+
+// RoomCreated is recorded when a room is created, with the settings it was
+// created with. It is the first event of every room's stream.
+type RoomCreated struct {
+	RoomID domain.RoomID
+	Title  string
+	Deck   string   // deck name
+	Cards  []string // deck cards, in display order
+	// Passcode is the hash of the room's passcode, if any. RoomCreated is
+	// never broadcast.
+	Passcode string `json:",omitempty"`
+}
 
 // ParticipantJoined is emitted after a participant successfully joins a room.
 type ParticipantJoined struct {
 	RoomID        domain.RoomID
-	ParticipantID domain.ParticipantID
+	ParticipantID domain.ParticipantID `json:",omitempty"` // event log only
+	Participant   string               `json:",omitempty"` // PublicID, broadcast only
 	Name          string
+	Kind          domain.ParticipantKind
+	Creator       bool `json:",omitempty"` // joined as the room's creator (see AsCreator)
 }
 
 // ParticipantLeft is emitted after a participant leaves.
 type ParticipantLeft struct {
 	RoomID        domain.RoomID
-	ParticipantID domain.ParticipantID
+	ParticipantID domain.ParticipantID `json:",omitempty"` // event log only
+	Participant   string               `json:",omitempty"` // PublicID, broadcast only
+}
+
+// RoomLocked is emitted when the facilitator locks the room against new
+// participants (Locked) or unlocks it again.
+type RoomLocked struct {
+	RoomID domain.RoomID
+	Locked bool
+}
+
+// FacilitatorChanged is emitted when the facilitator role moves to another participant.
+type FacilitatorChanged struct {
+	RoomID        domain.RoomID
+	ParticipantID domain.ParticipantID `json:",omitempty"` // event log only
+	Participant   string               `json:",omitempty"` // PublicID, broadcast only
 }
 
 // VoteCast is emitted when a participant casts a vote.
 type VoteCast struct {
 	RoomID        domain.RoomID
-	ParticipantID domain.ParticipantID
-	Card          string
+	ParticipantID domain.ParticipantID `json:",omitempty"` // event log only
+	Participant   string               `json:",omitempty"` // PublicID, broadcast only
+	Card          string               `json:",omitempty"` // event log only, until revealed
 }
 
 // VoteCleared is emitted when a participant clears their vote.
 type VoteCleared struct {
 	RoomID        domain.RoomID
-	ParticipantID domain.ParticipantID
+	ParticipantID domain.ParticipantID `json:",omitempty"` // event log only
+	Participant   string               `json:",omitempty"` // PublicID, broadcast only
 }
 
-// VotesRevealed is emitted when votes are revealed for the current round.
+// VotesRevealed is emitted when votes are revealed for the current round,
+// together with the statistics of the revealed votes.
 type VotesRevealed struct {
 	RoomID domain.RoomID
+	Stats  domain.Stats
+	At     time.Time
+}
+
+// RoundLabeled is emitted when the current round's label changes. Label is
+// the label as set, empty when it was cleared (the round then shows the
+// current story's title, see domain.Room.Label).
+type RoundLabeled struct {
+	RoomID domain.RoomID
+	Round  int
+	Label  string
 }
 
 // RoundReset is emitted when a new round starts (after reset).
 type RoundReset struct {
 	RoomID domain.RoomID
 	Round  int
+	At     time.Time
+}
+
+// StoriesChanged is emitted when the story backlog is edited (add, move, remove).
+type StoriesChanged struct {
+	RoomID  domain.RoomID
+	Stories []domain.Story
+	Current int
+}
+
+// StoryEstimated is emitted when the facilitator moves past a story, with the
+// agreed estimate (empty if the story was skipped).
+type StoryEstimated struct {
+	RoomID   domain.RoomID
+	Index    int
+	Title    string
+	Estimate string
+	At       time.Time
+}
+
+// RoomExpired is emitted when a room is removed for being empty or idle too
+// long, or closed by an operator; Reason is ExpiredEmpty, ExpiredIdle or
+// ExpiredClosed.
+type RoomExpired struct {
+	RoomID domain.RoomID
+	Reason string
+}
+
+func (e ParticipantJoined) public() any {
+	e.ParticipantID, e.Participant = "", PublicID(e.ParticipantID)
+	return e
+}
+
+func (e ParticipantLeft) public() any {
+	e.ParticipantID, e.Participant = "", PublicID(e.ParticipantID)
+	return e
+}
+
+func (e FacilitatorChanged) public() any {
+	e.ParticipantID, e.Participant = "", PublicID(e.ParticipantID)
+	return e
+}
+
+func (e VoteCast) public() any {
+	e.ParticipantID, e.Participant, e.Card = "", PublicID(e.ParticipantID), ""
+	return e
+}
+
+func (e VoteCleared) public() any {
+	e.ParticipantID, e.Participant = "", PublicID(e.ParticipantID)
+	return e
 }
@@ -0,0 +1,89 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jaminalder/estimations/internal/domain"
+)
+
+// Reasons a room expires, carried by RoomExpired.
+const (
+	ExpiredEmpty  = "empty"
+	ExpiredIdle   = "idle"
+	ExpiredClosed = "closed" // by an operator, see CloseRoom
+)
+
+// ExpiryPolicy decides when rooms are removed. A zero TTL disables that rule.
+type ExpiryPolicy struct {
+	EmptyTTL time.Duration // how long a room may stay without participants
+	IdleTTL  time.Duration // how long a room may go without any command
+}
+
+// ExpireRooms deletes every room that is past the policy's TTLs, broadcasting
+// RoomExpired to its still-connected clients, and returns the IDs of the
+// removed rooms. It is meant to be called periodically by a reaper; activity
+// is measured in sweeps, so expiry is accurate to one call interval.
+func (s *Service) ExpireRooms(ctx context.Context, policy ExpiryPolicy) ([]domain.RoomID, error) {
+	ids, err := s.Rooms.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("expire rooms: %w", err)
+	}
+	var expired []domain.RoomID
+	for _, id := range ids {
+		ok, err := s.expireRoom(ctx, id, policy)
+		if err != nil {
+			return expired, fmt.Errorf("expire room %s: %w", id, err)
+		}
+		if ok {
+			expired = append(expired, id)
+		}
+	}
+	return expired, nil
+}
+
+func (s *Service) expireRoom(ctx context.Context, id domain.RoomID, policy ExpiryPolicy) (bool, error) {
+	unlock := s.locks.lock(id)
+	defer unlock()
+	room, ok, err := s.Rooms.Get(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	if !ok || room == nil {
+		s.activity.forget(id)
+		return false, nil
+	}
+	now := s.now()
+	empty := len(room.Participants()) == 0
+	act := s.activity.sweep(id, now, empty)
+	reason := ""
+	switch {
+	case empty && policy.EmptyTTL > 0 && now.Sub(act.emptySince) >= policy.EmptyTTL:
+		reason = ExpiredEmpty
+	case policy.IdleTTL > 0 && now.Sub(act.last) >= policy.IdleTTL:
+		reason = ExpiredIdle
+	default:
+		return false, nil
+	}
+	return true, s.removeRoom(ctx, id, reason)
+}
+
+// removeRoom deletes the room and its event stream and tells its clients.
+// Callers hold the room's lock.
+func (s *Service) removeRoom(ctx context.Context, id domain.RoomID, reason string) error {
+	if err := s.Rooms.Delete(ctx, id); err != nil {
+		return err
+	}
+	if s.Events != nil {
+		if err := s.Events.Delete(ctx, id); err != nil {
+			return err
+		}
+	}
+	s.journal.forget(id)
+	s.activity.forget(id)
+	s.wrongPasscodes.forget(id)
+	s.wrongCodes.forget(id)
+	s.publish(ctx, id, RoomExpired{RoomID: id, Reason: reason})
+	return nil
+}
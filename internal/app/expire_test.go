@@ -0,0 +1,110 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// manualClock only moves when told to.
+type manualClock struct{ t time.Time }
+
+func (c *manualClock) Now() time.Time          { return c.t }
+func (c *manualClock) Advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func TestExpireRooms_EmptyAndIdle(t *testing.T) {
+	ctx := context.Background()
+	repo := &resetRepo{}
+	clock := &manualClock{t: time.Date(2025, 9, 4, 9, 0, 0, 0, time.UTC)}
+	bus := &resetBus{}
+	svc := &Service{Rooms: repo, Bus: bus, Clock: clock}
+	policy := ExpiryPolicy{EmptyTTL: 10 * time.Minute, IdleTTL: 2 * time.Hour}
+
+	svc.Ids = idFixed{rid: "empty"}
+	emptyID, _ := svc.CreateRoom(ctx)
+	svc.Ids = idFixed{rid: "busy"}
+	busyID, _ := svc.CreateRoom(ctx)
+	alice, err := svc.Join(ctx, busyID, "Alice")
+	if err != nil {
+		t.Fatalf("join: %v", err)
+	}
+
+	// First sweep stamps both rooms; nothing is old enough yet.
+	if got, err := svc.ExpireRooms(ctx, policy); err != nil || len(got) != 0 {
+		t.Fatalf("first sweep: %v, %v", got, err)
+	}
+
+	clock.Advance(10 * time.Minute)
+	bus.events = nil
+	got, err := svc.ExpireRooms(ctx, policy)
+	if err != nil || len(got) != 1 || got[0] != emptyID {
+		t.Fatalf("expected only the empty room to expire, got %v, %v", got, err)
+	}
+	if _, ok, _ := repo.Get(ctx, emptyID); ok {
+		t.Fatalf("expired room should be deleted")
+	}
+	if len(bus.events) != 1 || bus.events[0] != (RoomExpired{RoomID: emptyID, Reason: ExpiredEmpty}) {
+		t.Fatalf("expected RoomExpired(empty), got %#v", bus.events)
+	}
+
+	// Activity keeps a room alive past the idle TTL measured from its creation.
+	clock.Advance(time.Hour)
+	if err := svc.Cast(ctx, busyID, alice, "5"); err != nil {
+		t.Fatalf("cast: %v", err)
+	}
+	_, _ = svc.ExpireRooms(ctx, policy)
+	clock.Advance(90 * time.Minute)
+	if got, _ := svc.ExpireRooms(ctx, policy); len(got) != 0 {
+		t.Fatalf("recently active room must survive, expired %v", got)
+	}
+
+	// Reads do not count as activity.
+	_, _ = svc.Snapshot(ctx, busyID)
+	clock.Advance(30 * time.Minute)
+	bus.events = nil
+	got, _ = svc.ExpireRooms(ctx, policy)
+	if len(got) != 1 || got[0] != busyID {
+		t.Fatalf("expected idle room to expire, got %v", got)
+	}
+	if len(bus.events) != 1 || bus.events[0] != (RoomExpired{RoomID: busyID, Reason: ExpiredIdle}) {
+		t.Fatalf("expected RoomExpired(idle), got %#v", bus.events)
+	}
+}
+
+func TestExpireRooms_EmptyClockRestartsOnJoin(t *testing.T) {
+	ctx := context.Background()
+	repo := &resetRepo{}
+	clock := &manualClock{t: time.Date(2025, 9, 4, 9, 0, 0, 0, time.UTC)}
+	svc := &Service{Rooms: repo, Ids: idFixed{rid: "r1"}, Clock: clock}
+	policy := ExpiryPolicy{EmptyTTL: 10 * time.Minute}
+
+	roomID, _ := svc.CreateRoom(ctx)
+	_, _ = svc.ExpireRooms(ctx, policy)
+	clock.Advance(5 * time.Minute)
+	pid, _ := svc.Join(ctx, roomID, "Alice")
+	_, _ = svc.ExpireRooms(ctx, policy)
+	_ = svc.Leave(ctx, roomID, pid)
+	_, _ = svc.ExpireRooms(ctx, policy) // empty again from here
+
+	clock.Advance(9 * time.Minute)
+	if got, _ := svc.ExpireRooms(ctx, policy); len(got) != 0 {
+		t.Fatalf("room emptied 9 minutes ago must survive, expired %v", got)
+	}
+	clock.Advance(time.Minute)
+	if got, _ := svc.ExpireRooms(ctx, policy); len(got) != 1 {
+		t.Fatalf("expected room to expire 10 minutes after becoming empty")
+	}
+}
+
+func TestExpireRooms_ZeroPolicyKeepsRooms(t *testing.T) {
+	ctx := context.Background()
+	repo := &resetRepo{}
+	clock := &manualClock{t: time.Date(2025, 9, 4, 9, 0, 0, 0, time.UTC)}
+	svc := &Service{Rooms: repo, Ids: idFixed{rid: "r1"}, Clock: clock}
+	_, _ = svc.CreateRoom(ctx)
+	_, _ = svc.ExpireRooms(ctx, ExpiryPolicy{})
+	clock.Advance(1000 * time.Hour)
+	if got, _ := svc.ExpireRooms(ctx, ExpiryPolicy{}); len(got) != 0 {
+		t.Fatalf("zero policy must not expire rooms, expired %v", got)
+	}
+}
@@ -0,0 +1,20 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jaminalder/estimations/internal/domain"
+)
+
+// TransferFacilitator hands the facilitator role from by to another
+// participant and broadcasts FacilitatorChanged.
+func (s *Service) TransferFacilitator(ctx context.Context, roomID domain.RoomID, by, to domain.ParticipantID) error {
+	return s.withRoom(ctx, &command{name: "transfer_facilitator", room: roomID, by: by}, func(room *domain.Room) error {
+		if err := room.TransferFacilitator(by, to); err != nil {
+			return fmt.Errorf("transfer facilitator: %w", err)
+		}
+		s.emit(roomID, FacilitatorChanged{RoomID: roomID, ParticipantID: to})
+		return nil
+	})
+}
@@ -0,0 +1,80 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jaminalder/estimations/internal/domain"
+)
+
+func TestReveal_NonFacilitator_Forbidden_NoBroadcast(t *testing.T) {
+	ctx := context.Background()
+	repo := &revealRepo{}
+	roomID := domain.RoomID("r1")
+	room := domain.NewRoom(roomID)
+	_ = repo.Create(ctx, room)
+	_ = room.Join(domain.ParticipantID("p1"), "Alice")
+	_ = room.Join(domain.ParticipantID("p2"), "Bob")
+	_ = room.CastVote(domain.ParticipantID("p2"), "3")
+
+	bus := &revealBus{}
+	svc := &Service{Rooms: repo, Bus: bus}
+	if err := svc.Reveal(ctx, roomID, "p2"); !errors.Is(err, domain.ErrNotFacilitator) {
+		t.Fatalf("expected ErrNotFacilitator, got %v", err)
+	}
+	if err := svc.Reset(ctx, roomID, "p2"); !errors.Is(err, domain.ErrNotFacilitator) {
+		t.Fatalf("expected ErrNotFacilitator, got %v", err)
+	}
+	if len(bus.events) != 0 {
+		t.Fatalf("no event expected on rejected commands")
+	}
+}
+
+func TestTransferFacilitator_Broadcasts(t *testing.T) {
+	ctx := context.Background()
+	repo := &revealRepo{}
+	roomID := domain.RoomID("r1")
+	room := domain.NewRoom(roomID)
+	_ = repo.Create(ctx, room)
+	_ = room.Join(domain.ParticipantID("p1"), "Alice")
+	_ = room.Join(domain.ParticipantID("p2"), "Bob")
+
+	bus := &revealBus{}
+	svc := &Service{Rooms: repo, Bus: bus}
+	if err := svc.TransferFacilitator(ctx, roomID, "p2", "p2"); !errors.Is(err, domain.ErrNotFacilitator) {
+		t.Fatalf("non-facilitator transfer: got %v", err)
+	}
+	if err := svc.TransferFacilitator(ctx, roomID, "p1", "p2"); err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	if len(bus.events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(bus.events))
+	}
+	evt, ok := bus.events[0].(FacilitatorChanged)
+	if !ok || evt.Participant != PublicID("p2") || evt.ParticipantID != "" {
+		t.Fatalf("unexpected event: %#v", bus.events[0])
+	}
+}
+
+func TestLeave_Facilitator_BroadcastsHandover(t *testing.T) {
+	ctx := context.Background()
+	repo := &leaveRepo{}
+	roomID := domain.RoomID("r1")
+	room := domain.NewRoom(roomID)
+	_ = repo.Create(ctx, room)
+	_ = room.Join(domain.ParticipantID("p1"), "Alice")
+	_ = room.Join(domain.ParticipantID("p2"), "Bob")
+
+	bus := &leaveBus{}
+	svc := &Service{Rooms: repo, Bus: bus}
+	if err := svc.Leave(ctx, roomID, "p1"); err != nil {
+		t.Fatalf("leave: %v", err)
+	}
+	if len(bus.events) != 2 {
+		t.Fatalf("expected ParticipantLeft + FacilitatorChanged, got %d events", len(bus.events))
+	}
+	if evt, ok := bus.events[1].(FacilitatorChanged); !ok || evt.Participant != PublicID("p2") {
+		t.Fatalf("unexpected handover event: %#v", bus.events[1])
+	}
+}
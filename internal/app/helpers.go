@@ -2,28 +2,101 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"time"
 
 	"github.com/jaminalder/estimations/internal/domain"
 )
 
+// ErrRoomNotFound is returned when a use-case targets a room that does not exist.
+var ErrRoomNotFound = errors.New("room not found")
+
+// ErrRoomExists is returned by RoomRepo.Create for an ID already stored.
+var ErrRoomExists = errors.New("room already exists")
+
 func (s *Service) getRoom(ctx context.Context, roomID domain.RoomID) (*domain.Room, error) {
 	room, ok, err := s.Rooms.Get(ctx, roomID)
 	if err != nil {
 		return nil, fmt.Errorf("get room: %w", err)
 	}
 	if !ok || room == nil {
-		return nil, fmt.Errorf("room not found: %s", roomID)
+		return nil, fmt.Errorf("%w: %s", ErrRoomNotFound, roomID)
 	}
 	return room, nil
 }
 
-func (s *Service) emit(ctx context.Context, roomID domain.RoomID, event any) error {
-	if s.Bus == nil {
+// withRoom loads the room and runs fn while holding the room's lock, so the
+// command and the events it emits are atomic with respect to other use-cases.
+// If fn succeeds the room is saved back to the repository, the events it
+// emitted are appended to the room's stream and only then broadcast, and the
+// command counts as activity for expiry. Once fn has changed the room, the
+// rest runs even if ctx is cancelled; if the events cannot be recorded, the
+// room is rolled back so it does not run ahead of its stream. The outcome is
+// reported to Metrics and Logger.
+func (s *Service) withRoom(ctx context.Context, cmd *command, fn func(room *domain.Room) error) (err error) {
+	defer s.observe(ctx, cmd, time.Now(), &err)
+	roomID := cmd.room
+	return s.inRoom(ctx, roomID, func(room *domain.Room) error {
+		var before domain.RoomState
+		if s.Events != nil {
+			before = room.State()
+		}
+		if err := fn(room); err != nil {
+			s.discard(roomID)
+			return err
+		}
+		ctx := context.WithoutCancel(ctx)
+		if err := s.Rooms.Save(ctx, room); err != nil {
+			s.discard(roomID)
+			return fmt.Errorf("save room: %w", err)
+		}
+		events, err := s.commit(ctx, room)
+		if err != nil {
+			s.rollback(ctx, before)
+			return err
+		}
+		s.activity.touch(roomID)
+		s.publish(ctx, roomID, events...)
 		return nil
+	})
+}
+
+// inRoom runs fn on the room under its lock without saving; for reads.
+func (s *Service) inRoom(ctx context.Context, roomID domain.RoomID, fn func(room *domain.Room) error) error {
+	unlock := s.locks.lock(roomID)
+	defer unlock()
+	room, err := s.getRoom(ctx, roomID)
+	if err != nil {
+		return err
+	}
+	return fn(room)
+}
+
+// now returns the current time from the configured Clock, or the system clock.
+func (s *Service) now() time.Time {
+	if s.Clock == nil {
+		return time.Now()
+	}
+	return s.Clock.Now()
+}
+
+// publish broadcasts the public form of events. They describe what has
+// already happened, so they go out even if the client that caused them went
+// away, and a failed broadcast is only logged.
+func (s *Service) publish(ctx context.Context, roomID domain.RoomID, events ...any) {
+	if s.Bus == nil {
+		return
 	}
-	if err := s.Bus.Broadcast(ctx, roomID, event); err != nil {
-		return fmt.Errorf("broadcast: %w", err)
+	ctx = context.WithoutCancel(ctx)
+	for _, event := range events {
+		if pe, ok := event.(publicEvent); ok {
+			event = pe.public()
+		}
+		if err := s.Bus.Broadcast(ctx, roomID, event); err != nil && s.Logger != nil {
+			s.Logger.LogAttrs(ctx, slog.LevelWarn, "broadcast failed", RoomAttr(roomID),
+				slog.String("event", eventName(event)), slog.String("error", err.Error()))
+		}
 	}
-	return nil
 }
@@ -0,0 +1,69 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jaminalder/estimations/internal/domain"
+)
+
+// stepClock returns t and advances by step on every call.
+type stepClock struct {
+	t    time.Time
+	step time.Duration
+}
+
+func (c *stepClock) Now() time.Time {
+	now := c.t
+	c.t = c.t.Add(c.step)
+	return now
+}
+
+func TestHistory_RevealReset_UsesClock(t *testing.T) {
+	ctx := context.Background()
+	repo := &resetRepo{}
+	roomID := domain.RoomID("r1")
+	room := domain.NewRoom(roomID)
+	_ = repo.Create(ctx, room)
+	p1 := domain.ParticipantID("p1")
+	_ = room.Join(p1, "Alice")
+
+	start := time.Date(2025, 9, 2, 9, 0, 0, 0, time.UTC)
+	clock := &stepClock{t: start, step: time.Minute}
+	bus := &resetBus{}
+	svc := &Service{Rooms: repo, Bus: bus, Clock: clock}
+
+	if err := svc.SetLabel(ctx, roomID, p1, "Checkout flow"); err != nil {
+		t.Fatalf("label: %v", err)
+	}
+	if err := svc.Cast(ctx, roomID, p1, "13"); err != nil {
+		t.Fatalf("cast: %v", err)
+	}
+	if err := svc.Reveal(ctx, roomID, p1); err != nil {
+		t.Fatalf("reveal: %v", err)
+	}
+	if err := svc.Reset(ctx, roomID, p1); err != nil {
+		t.Fatalf("reset: %v", err)
+	}
+
+	snap, err := svc.Snapshot(ctx, roomID)
+	if err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	if len(snap.History) != 1 {
+		t.Fatalf("expected 1 archived round, got %d", len(snap.History))
+	}
+	h := snap.History[0]
+	if h.Label != "Checkout flow" || len(h.Votes) != 1 || h.Votes[0].Name != "Alice" || h.Votes[0].Card != "13" {
+		t.Fatalf("unexpected archived round: %+v", h)
+	}
+	if !h.RevealedAt.Equal(start) || !h.ResetAt.Equal(start.Add(time.Minute)) {
+		t.Fatalf("timestamps should come from the clock: %+v", h)
+	}
+
+	evt, ok := bus.events[0].(RoundLabeled)
+	if !ok || evt.Label != "Checkout flow" || evt.Round != 0 {
+		t.Fatalf("expected RoundLabeled first, got %#v", bus.events[0])
+	}
+}
@@ -7,20 +7,104 @@ import (
 	"github.com/jaminalder/estimations/internal/domain"
 )
 
-// Join adds a participant with the given display name to the room and
-// broadcasts a ParticipantJoined event upon success.
-func (s *Service) Join(ctx context.Context, roomID domain.RoomID, name string) (domain.ParticipantID, error) {
-	room, err := s.getRoom(ctx, roomID)
-	if err != nil {
-		return "", err
+type (
+	joinConfig struct {
+		kind     domain.ParticipantKind
+		passcode string
+		creator  bool
 	}
+	// JoinOption customizes how a participant joins a room.
+	JoinOption func(*joinConfig)
+)
+
+// AsObserver joins as an observer who watches without voting.
+func AsObserver() JoinOption { return func(c *joinConfig) { c.kind = domain.Observer } }
 
-	pid := s.Ids.NewParticipantID()
-	if err := room.Join(pid, name); err != nil {
-		return "", fmt.Errorf("join: %w", err)
+// UsingPasscode gives the passcode of a room that has one (see WithPasscode).
+func UsingPasscode(passcode string) JoinOption {
+	return func(c *joinConfig) { c.passcode = passcode }
+}
+
+// AsCreator joins as the room's creator, who needs no passcode, gets into a
+// locked room and becomes its facilitator (see domain.Room.JoinAsCreator).
+// The caller must have established that the participant created the room.
+func AsCreator() JoinOption { return func(c *joinConfig) { c.creator = true } }
+
+// Join adds a participant with the given display name to the room (as a
+// voter unless AsObserver is given) and broadcasts a ParticipantJoined event
+// upon success. A room with a passcode needs it given with UsingPasscode
+// (and refuses it for a while after too many wrong ones); a locked room takes
+// nobody but its creator.
+func (s *Service) Join(ctx context.Context, roomID domain.RoomID, name string, opts ...JoinOption) (domain.ParticipantID, error) {
+	var cfg joinConfig
+	for _, o := range opts {
+		o(&cfg)
+	}
+	var (
+		hash    string
+		passErr error
+	)
+	if !cfg.creator {
+		hash, passErr = s.checkJoinPasscode(ctx, roomID, cfg.passcode)
 	}
-	if err := s.emit(ctx, roomID, ParticipantJoined{RoomID: roomID, ParticipantID: pid, Name: name}); err != nil {
+	var pid domain.ParticipantID
+	cmd := &command{name: "join", room: roomID}
+	err := s.withRoom(ctx, cmd, func(room *domain.Room) error {
+		// A locked room says so (from JoinAs) rather than ask for the passcode.
+		if !cfg.creator && !room.IsLocked() {
+			if passErr != nil {
+				return fmt.Errorf("join: %w", passErr)
+			}
+			if room.Passcode() != hash {
+				// Checked against another room, or none: not a match.
+				return fmt.Errorf("join: %w", ErrWrongPasscode)
+			}
+		}
+		if err := s.checkCapacity(room, cfg.kind); err != nil {
+			return fmt.Errorf("join: %w", err)
+		}
+		id := s.Ids.NewParticipantID()
+		facilitator := room.Facilitator()
+		join := room.JoinAs
+		if cfg.creator {
+			join = room.JoinAsCreator
+		}
+		if err := join(id, name, cfg.kind); err != nil {
+			return fmt.Errorf("join: %w", err)
+		}
+		pid = id
+		cmd.by = id
+		s.emit(roomID, ParticipantJoined{RoomID: roomID, ParticipantID: id, Name: name, Kind: cfg.kind, Creator: cfg.creator})
+		if cfg.creator && facilitator != "" {
+			// The creator took the role from whoever joined first.
+			s.emit(roomID, FacilitatorChanged{RoomID: roomID, ParticipantID: id})
+		}
+		return nil
+	})
+	if err != nil {
 		return "", err
 	}
 	return pid, nil
 }
+
+// checkCapacity enforces the service's capacity limits, if any; the domain
+// enforces its own.
+func (s *Service) checkCapacity(room *domain.Room, kind domain.ParticipantKind) error {
+	limit, what := s.MaxParticipants, "participants"
+	if kind == domain.Observer {
+		limit, what = s.MaxObservers, "observers"
+	}
+	if limit <= 0 {
+		return nil
+	}
+	n := 0
+	for _, p := range room.Participants() {
+		if p.Kind == kind {
+			n++
+		}
+	}
+	if n >= limit {
+		return fmt.Errorf("%w: max %d %s", domain.ErrRoomFull, limit, what)
+	}
+	return nil
+}
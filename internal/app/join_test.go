@@ -37,11 +37,15 @@ func (r *joinRepo) Get(ctx context.Context, id domain.RoomID) (*domain.Room, boo
 	return room, ok, nil
 }
 
+func (r *joinRepo) Save(ctx context.Context, room *domain.Room) error { return nil }
+
 func (r *joinRepo) Delete(ctx context.Context, id domain.RoomID) error {
 	delete(r.rooms, id)
 	return nil
 }
 
+func (r *joinRepo) List(ctx context.Context) ([]domain.RoomID, error) { return nil, nil }
+
 type fixedIDs struct{ nextP domain.ParticipantID }
 
 func (f fixedIDs) NewRoomID() domain.RoomID               { return "unused" }
@@ -84,7 +88,7 @@ func TestJoin_Success_BroadcastsEvent(t *testing.T) {
 	if !ok {
 		t.Fatalf("wrong event type: %T", bus.events[0])
 	}
-	if evt.RoomID != roomID || evt.ParticipantID != pid || evt.Name != "Alice" {
+	if evt.RoomID != roomID || evt.ParticipantID != "" || evt.Participant != PublicID(pid) || evt.Name != "Alice" {
 		t.Fatalf("event contents mismatch: %+v", evt)
 	}
 }
@@ -126,10 +130,61 @@ func TestJoin_DuplicateName_Fails_NoBroadcast(t *testing.T) {
 	ids.nextP = "p2"
 	// Replace ids generator in service for second call
 	svc.Ids = ids
-	if _, err := svc.Join(ctx, roomID, "alice"); err == nil {
-		t.Fatalf("expected duplicate name error")
+	if _, err := svc.Join(ctx, roomID, "alice"); !errors.Is(err, domain.ErrDuplicateName) {
+		t.Fatalf("expected ErrDuplicateName, got %v", err)
 	}
 	if len(bus.events) != 1 {
 		t.Fatalf("only first join should broadcast; got %d", len(bus.events))
 	}
 }
+
+func TestJoin_AsObserver_BroadcastsKind(t *testing.T) {
+	ctx := context.Background()
+	repo := &joinRepo{}
+	roomID := domain.RoomID("r1")
+	room := domain.NewRoom(roomID)
+	_ = repo.Create(ctx, room)
+	bus := &captureBroadcaster{}
+	svc := &Service{Rooms: repo, Ids: fixedIDs{nextP: "o1"}, Bus: bus}
+
+	pid, err := svc.Join(ctx, roomID, "Olivia", AsObserver())
+	if err != nil {
+		t.Fatalf("join: %v", err)
+	}
+	if !room.Participants()[0].IsObserver() {
+		t.Fatalf("participant should be an observer")
+	}
+	evt, ok := bus.events[0].(ParticipantJoined)
+	if !ok || evt.Kind != domain.Observer {
+		t.Fatalf("event should carry observer kind: %#v", bus.events[0])
+	}
+	if err := svc.Cast(ctx, roomID, pid, "5"); !errors.Is(err, domain.ErrObserverCannotVote) {
+		t.Fatalf("expected ErrObserverCannotVote, got %v", err)
+	}
+	if len(bus.events) != 1 {
+		t.Fatalf("failed cast must not broadcast")
+	}
+}
+
+func TestJoin_ServiceCapacity(t *testing.T) {
+	ctx := context.Background()
+	repo := &joinRepo{}
+	roomID := domain.RoomID("r1")
+	_ = repo.Create(ctx, domain.NewRoom(roomID))
+	svc := &Service{Rooms: repo, Ids: &seqIDs{}, MaxParticipants: 2, MaxObservers: 1}
+
+	for _, name := range []string{"alice", "bob"} {
+		if _, err := svc.Join(ctx, roomID, name); err != nil {
+			t.Fatalf("join %s: %v", name, err)
+		}
+	}
+	if _, err := svc.Join(ctx, roomID, "carol"); !errors.Is(err, domain.ErrRoomFull) {
+		t.Fatalf("third voter: expected ErrRoomFull, got %v", err)
+	}
+	if _, err := svc.Join(ctx, roomID, "olga", AsObserver()); err != nil {
+		t.Fatalf("observers have their own capacity: %v", err)
+	}
+	if _, err := svc.Join(ctx, roomID, "oscar", AsObserver()); !errors.Is(err, domain.ErrRoomFull) {
+		t.Fatalf("second observer: expected ErrRoomFull, got %v", err)
+	}
+}
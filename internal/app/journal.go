@@ -0,0 +1,140 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jaminalder/estimations/internal/domain"
+)
+
+// EventRecord is one persisted room event. Seq numbers a room's events from
+// 1 without gaps; Type is the event's name (its Go type name, as broadcast)
+// and Data its JSON encoding. At is when the command producing it ran.
+type EventRecord struct {
+	Seq  uint64          `json:"seq"`
+	At   time.Time       `json:"at"`
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// StateSnapshot is a room's full state as of event Seq, so replay can start
+// there instead of at the first event.
+type StateSnapshot struct {
+	Seq   uint64           `json:"seq"`
+	At    time.Time        `json:"at"`
+	State domain.RoomState `json:"state"`
+}
+
+// ErrStreamConflict is returned by EventStore.Append when the records do not
+// continue the room's stream (another writer appended first, or Seq skips).
+var ErrStreamConflict = errors.New("event stream conflict")
+
+// snapshotEvery is how many events a room's stream grows by between
+// snapshots, bounding how much replay has to apply.
+const snapshotEvery = 100
+
+// roomJournal holds, per room, the events emitted by the running command
+// (recorded and broadcast once it succeeds) and the stream's position. A
+// room's stream is only used while holding that room's lock.
+type roomJournal struct {
+	mu      sync.Mutex
+	streams map[domain.RoomID]*stream
+}
+
+type stream struct {
+	pending []any
+	seq     uint64 // last appended
+	snap    uint64 // Seq of the latest snapshot
+}
+
+func (j *roomJournal) stream(id domain.RoomID) *stream {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.streams == nil {
+		j.streams = make(map[domain.RoomID]*stream)
+	}
+	st, ok := j.streams[id]
+	if !ok {
+		st = &stream{}
+		j.streams[id] = st
+	}
+	return st
+}
+
+func (j *roomJournal) forget(id domain.RoomID) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	delete(j.streams, id)
+}
+
+// emit queues event as an outcome of the running command. It is recorded
+// and broadcast when the command commits (see withRoom), and dropped if the
+// command fails.
+func (s *Service) emit(roomID domain.RoomID, event any) {
+	st := s.journal.stream(roomID)
+	st.pending = append(st.pending, event)
+}
+
+// discard drops the events queued by a failed command.
+func (s *Service) discard(roomID domain.RoomID) {
+	s.journal.stream(roomID).pending = nil
+}
+
+// commit takes the events queued by the current command and returns them
+// for broadcasting, after appending those replay understands to the room's
+// stream, if an EventStore is configured. The room is snapshotted every
+// snapshotEvery events; a failed snapshot is retried with the next command.
+func (s *Service) commit(ctx context.Context, room *domain.Room) ([]any, error) {
+	st := s.journal.stream(room.ID())
+	events := st.pending
+	st.pending = nil
+	if s.Events == nil {
+		return events, nil
+	}
+	at := s.now()
+	var records []EventRecord
+	for _, ev := range events {
+		if _, ok := eventTypes[eventName(ev)]; !ok {
+			continue
+		}
+		data, err := json.Marshal(ev)
+		if err != nil {
+			return nil, fmt.Errorf("encode %s: %w", eventName(ev), err)
+		}
+		records = append(records, EventRecord{Seq: st.seq + uint64(len(records)) + 1, At: at, Type: eventName(ev), Data: data})
+	}
+	if len(records) == 0 {
+		return events, nil
+	}
+	if err := s.Events.Append(ctx, room.ID(), records); err != nil {
+		return nil, fmt.Errorf("append events: %w", err)
+	}
+	st.seq += uint64(len(records))
+	if st.seq-st.snap >= snapshotEvery {
+		if err := s.Events.SaveSnapshot(ctx, room.ID(), StateSnapshot{Seq: st.seq, At: at, State: room.State()}); err != nil {
+			if s.Logger != nil {
+				s.Logger.LogAttrs(ctx, slog.LevelWarn, "snapshot failed", RoomAttr(room.ID()), slog.String("error", err.Error()))
+			}
+		} else {
+			st.snap = st.seq
+		}
+	}
+	return events, nil
+}
+
+// rollback puts a room back to its state before a command whose events
+// could not be recorded, so the live room and its stream agree.
+func (s *Service) rollback(ctx context.Context, before domain.RoomState) {
+	room, err := domain.RestoreRoom(before)
+	if err == nil {
+		err = s.Rooms.Save(ctx, room)
+	}
+	if err != nil && s.Logger != nil {
+		s.Logger.LogAttrs(ctx, slog.LevelError, "rollback failed", RoomAttr(before.ID), slog.String("error", err.Error()))
+	}
+}
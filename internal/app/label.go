@@ -0,0 +1,21 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jaminalder/estimations/internal/domain"
+)
+
+// SetLabel sets the current round's label (e.g. the story being estimated)
+// and broadcasts RoundLabeled. Facilitator only.
+func (s *Service) SetLabel(ctx context.Context, roomID domain.RoomID, by domain.ParticipantID, label string) error {
+	return s.withRoom(ctx, &command{name: "set_label", room: roomID, by: by}, func(room *domain.Room) error {
+		if err := room.SetLabel(by, label); err != nil {
+			return fmt.Errorf("label: %w", err)
+		}
+		s.emit(roomID, RoundLabeled{RoomID: roomID, Round: room.RoundIndex(), Label: strings.TrimSpace(label)})
+		return nil
+	})
+}
@@ -2,22 +2,65 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/jaminalder/estimations/internal/domain"
 )
 
-// Leave removes a participant from the room and broadcasts ParticipantLeft.
+// Leave removes a participant from the room and broadcasts ParticipantLeft,
+// followed by FacilitatorChanged if the facilitator role fell to someone else.
 func (s *Service) Leave(ctx context.Context, roomID domain.RoomID, participantID domain.ParticipantID) error {
-	room, err := s.getRoom(ctx, roomID)
-	if err != nil {
-		return err
-	}
-	if err := room.Leave(participantID); err != nil {
-		return fmt.Errorf("leave: %w", err)
+	return s.withRoom(ctx, &command{name: "leave", room: roomID, by: participantID}, func(room *domain.Room) error {
+		facilitator := room.Facilitator()
+		if err := room.Leave(participantID); err != nil {
+			return fmt.Errorf("leave: %w", err)
+		}
+		s.emitLeft(room, participantID, facilitator)
+		return nil
+	})
+}
+
+// Release removes another participant on the facilitator's behalf, freeing
+// their name, and broadcasts the same events as Leave.
+func (s *Service) Release(ctx context.Context, roomID domain.RoomID, by, participantID domain.ParticipantID) error {
+	return s.withRoom(ctx, &command{name: "release", room: roomID, by: by}, func(room *domain.Room) error {
+		facilitator := room.Facilitator()
+		if err := room.Release(by, participantID); err != nil {
+			return fmt.Errorf("release: %w", err)
+		}
+		s.emitLeft(room, participantID, facilitator)
+		return nil
+	})
+}
+
+// emitLeft announces that participantID left the room, and who took over if
+// the role moved away from facilitator (the facilitator before they left).
+func (s *Service) emitLeft(room *domain.Room, participantID, facilitator domain.ParticipantID) {
+	roomID := room.ID()
+	s.emit(roomID, ParticipantLeft{RoomID: roomID, ParticipantID: participantID})
+	if next := room.Facilitator(); next != facilitator && next != "" {
+		s.emit(roomID, FacilitatorChanged{RoomID: roomID, ParticipantID: next})
 	}
-	if err := s.emit(ctx, roomID, ParticipantLeft{RoomID: roomID, ParticipantID: participantID}); err != nil {
-		return err
+}
+
+// LeaveDisconnected removes, as with Leave, every participant whose event
+// streams have all been closed for at least grace, and returns how many were
+// removed. It is meant to be called periodically, so removal is accurate to
+// one call interval. Participants who already left, or whose room is gone,
+// are skipped.
+func (s *Service) LeaveDisconnected(ctx context.Context, grace time.Duration) (int, error) {
+	n := 0
+	for _, k := range s.presence.claimGone(s.now().Add(-grace)) {
+		err := s.Leave(ctx, k.room, k.pid)
+		switch {
+		case err == nil:
+			n++
+		case errors.Is(err, ErrRoomNotFound), errors.Is(err, domain.ErrNotParticipant):
+		default:
+			return n, fmt.Errorf("leave disconnected: %w", err)
+		}
 	}
-	return nil
+	return n, nil
 }
@@ -3,6 +3,7 @@ package app
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/jaminalder/estimations/internal/domain"
 )
@@ -24,11 +25,15 @@ func (r *leaveRepo) Get(ctx context.Context, id domain.RoomID) (*domain.Room, bo
 	return rm, ok, nil
 }
 
+func (r *leaveRepo) Save(ctx context.Context, room *domain.Room) error { return nil }
+
 func (r *leaveRepo) Delete(ctx context.Context, id domain.RoomID) error {
 	delete(r.rooms, id)
 	return nil
 }
 
+func (r *leaveRepo) List(ctx context.Context) ([]domain.RoomID, error) { return nil, nil }
+
 type leaveBus struct{ events []any }
 
 func (b *leaveBus) Broadcast(ctx context.Context, roomID domain.RoomID, event any) error {
@@ -81,3 +86,85 @@ func TestLeave_NotParticipant_NoBroadcast(t *testing.T) {
 		t.Fatalf("no broadcast on failure")
 	}
 }
+
+func TestLeaveDisconnected_AfterGrace(t *testing.T) {
+	ctx := context.Background()
+	repo := &leaveRepo{}
+	roomID := domain.RoomID("r1")
+	room := domain.NewRoom(roomID)
+	_ = repo.Create(ctx, room)
+	_ = room.Join("alice", "Alice")
+	_ = room.Join("bob", "Bob")
+	_ = room.Join("carol", "Carol")
+	clock := &manualClock{t: time.Date(2025, 9, 4, 9, 0, 0, 0, time.UTC)}
+	bus := &leaveBus{}
+	svc := &Service{Rooms: repo, Bus: bus, Clock: clock}
+	const grace = time.Minute
+
+	// Alice has two tabs open, Bob one; Carol never streams and is never removed.
+	aliceTab1 := svc.Connect(roomID, "alice")
+	aliceTab2 := svc.Connect(roomID, "alice")
+	bobTab := svc.Connect(roomID, "bob")
+
+	bobTab()
+	aliceTab1()
+	clock.Advance(30 * time.Second)
+	if n, err := svc.LeaveDisconnected(ctx, grace); err != nil || n != 0 {
+		t.Fatalf("within grace: removed %d, %v", n, err)
+	}
+
+	// A reconnect within the grace period keeps the participant.
+	aliceTab2()
+	svc.Connect(roomID, "alice")
+	clock.Advance(30 * time.Second)
+	n, err := svc.LeaveDisconnected(ctx, grace)
+	if err != nil || n != 1 {
+		t.Fatalf("after grace: removed %d, %v", n, err)
+	}
+	if len(bus.events) != 1 || bus.events[0] != (ParticipantLeft{RoomID: roomID, Participant: PublicID("bob")}) {
+		t.Fatalf("expected ParticipantLeft for bob, got %#v", bus.events)
+	}
+	if got := len(room.Participants()); got != 2 {
+		t.Fatalf("expected alice and carol to stay, got %d participants", got)
+	}
+
+	// A participant who left explicitly in the meantime is skipped.
+	carolTab := svc.Connect(roomID, "carol")
+	carolTab()
+	_ = svc.Leave(ctx, roomID, "carol")
+	clock.Advance(grace)
+	if n, err := svc.LeaveDisconnected(ctx, grace); err != nil || n != 0 {
+		t.Fatalf("already left: removed %d, %v", n, err)
+	}
+}
+
+func TestRelease_FacilitatorFreesName_Broadcasts(t *testing.T) {
+	ctx := context.Background()
+	repo := &leaveRepo{}
+	roomID := domain.RoomID("r1")
+	room := domain.NewRoom(roomID)
+	_ = repo.Create(ctx, room)
+	_ = room.Join("p1", "Alice")
+	_ = room.Join("p2", "Bob")
+	bus := &leaveBus{}
+	svc := &Service{Rooms: repo, Bus: bus}
+
+	if err := svc.Release(ctx, roomID, "p2", "p1"); err == nil {
+		t.Fatalf("release by non-facilitator should fail")
+	}
+	if len(bus.events) != 0 {
+		t.Fatalf("rejected release must not broadcast, got %v", bus.events)
+	}
+	if err := svc.Release(ctx, roomID, "p1", "p2"); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if len(bus.events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(bus.events))
+	}
+	if ev, ok := bus.events[0].(ParticipantLeft); !ok || ev.Participant != PublicID("p2") {
+		t.Fatalf("want ParticipantLeft for p2, got %#v", bus.events[0])
+	}
+	if err := room.Join("p3", "Bob"); err != nil {
+		t.Fatalf("released name should be free: %v", err)
+	}
+}
@@ -0,0 +1,46 @@
+package app
+
+import (
+	"sync"
+
+	"github.com/jaminalder/estimations/internal/domain"
+)
+
+// roomLocks hands out one mutex per room so that use-cases run atomically
+// against a room. Entries are reference-counted and dropped once unused, so
+// the map does not grow with every room ever touched.
+type roomLocks struct {
+	mu    sync.Mutex
+	rooms map[domain.RoomID]*roomLock
+}
+
+type roomLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// lock blocks until the room's mutex is held and returns the matching unlock.
+func (l *roomLocks) lock(id domain.RoomID) func() {
+	l.mu.Lock()
+	if l.rooms == nil {
+		l.rooms = make(map[domain.RoomID]*roomLock)
+	}
+	rl, ok := l.rooms[id]
+	if !ok {
+		rl = &roomLock{}
+		l.rooms[id] = rl
+	}
+	rl.refs++
+	l.mu.Unlock()
+
+	rl.mu.Lock()
+	return func() {
+		rl.mu.Unlock()
+		l.mu.Lock()
+		rl.refs--
+		if rl.refs == 0 {
+			delete(l.rooms, id)
+		}
+		l.mu.Unlock()
+	}
+}
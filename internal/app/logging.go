@@ -0,0 +1,58 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/jaminalder/estimations/internal/domain"
+)
+
+// command describes a use-case run, for metrics and logs.
+type command struct {
+	name string
+	room domain.RoomID
+	by   domain.ParticipantID // acting (or, for join, joining) participant, if any
+}
+
+type logAttrsKey struct{}
+
+// WithLogAttrs returns a context whose command logs carry attrs in addition
+// to the command's own, e.g. the request ID set by an adapter.
+func WithLogAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	prev, _ := ctx.Value(logAttrsKey{}).([]slog.Attr)
+	return context.WithValue(ctx, logAttrsKey{}, append(prev[:len(prev):len(prev)], attrs...))
+}
+
+// RoomAttr is the log attribute of a room ID.
+func RoomAttr(id domain.RoomID) slog.Attr { return slog.String("room_id", string(id)) }
+
+// ParticipantAttr is the log attribute of a participant. Participant IDs
+// double as credentials, so only the PublicID is logged; it is stable, so a
+// participant's lines can still be followed.
+func ParticipantAttr(id domain.ParticipantID) slog.Attr {
+	return slog.String("participant", PublicID(id))
+}
+
+// observe reports a finished command to Metrics and Logger, if set. Latency
+// is wall time, not the Clock's.
+func (s *Service) observe(ctx context.Context, cmd *command, start time.Time, err *error) {
+	took := time.Since(start)
+	if s.Metrics != nil {
+		s.Metrics.CommandDone(cmd.name, took, *err)
+	}
+	if s.Logger == nil {
+		return
+	}
+	attrs, _ := ctx.Value(logAttrsKey{}).([]slog.Attr)
+	attrs = append(attrs[:len(attrs):len(attrs)], slog.String("command", cmd.name), RoomAttr(cmd.room))
+	if cmd.by != "" {
+		attrs = append(attrs, ParticipantAttr(cmd.by))
+	}
+	attrs = append(attrs, slog.Duration("duration", took))
+	if *err != nil {
+		s.Logger.LogAttrs(ctx, slog.LevelWarn, "command failed", append(attrs, slog.String("error", (*err).Error()))...)
+		return
+	}
+	s.Logger.LogAttrs(ctx, slog.LevelInfo, "command", attrs...)
+}
@@ -0,0 +1,37 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestService_LogsCommands(t *testing.T) {
+	var out strings.Builder
+	svc := &Service{Rooms: &syncRepo{}, Ids: &seqIDs{}, Logger: slog.New(slog.NewTextHandler(&out, nil))}
+	ctx := WithLogAttrs(context.Background(), slog.String("request_id", "req-1"))
+	roomID, _ := svc.CreateRoom(ctx)
+	pid, _ := svc.Join(ctx, roomID, "alice")
+	_ = svc.Cast(ctx, roomID, pid, "XXL")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("want one line per command, got %q", lines)
+	}
+	participant := ParticipantAttr(pid).String()
+	for i, want := range [][]string{
+		{"level=INFO", "msg=command", "request_id=req-1", "command=create_room", "room_id=" + string(roomID)},
+		{"level=INFO", "msg=command", "request_id=req-1", "command=join", participant},
+		{"level=WARN", `msg="command failed"`, "command=cast", participant, "error="},
+	} {
+		for _, w := range want {
+			if !strings.Contains(lines[i], w) {
+				t.Errorf("line %d lacks %s: %s", i, w, lines[i])
+			}
+		}
+	}
+	if strings.Contains(out.String(), string(pid)) {
+		t.Fatalf("logs leak the participant ID: %s", out.String())
+	}
+}
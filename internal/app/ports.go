@@ -8,10 +8,19 @@ import (
 )
 
 // RoomRepo is the repository interface for Room aggregates.
+//
+// Get may return a shared pointer (in-memory) or a freshly loaded copy
+// (persistent stores); the Service always calls Save after a successful
+// command, so implementations must not rely on pointer sharing.
 type RoomRepo interface {
+	// Create stores a new room; a room with its ID already stored fails with
+	// ErrRoomExists.
 	Create(ctx context.Context, room *domain.Room) error
 	Get(ctx context.Context, id domain.RoomID) (*domain.Room, bool, error)
+	Save(ctx context.Context, room *domain.Room) error
 	Delete(ctx context.Context, id domain.RoomID) error
+	// List returns the IDs of all stored rooms, in no particular order.
+	List(ctx context.Context) ([]domain.RoomID, error)
 }
 
 // IdGen provides opaque identifiers for rooms and participants.
@@ -29,3 +38,30 @@ type Clock interface {
 type Broadcaster interface {
 	Broadcast(ctx context.Context, roomID domain.RoomID, event any) error
 }
+
+// Metrics observes use-case outcomes (implemented by the metrics adapter).
+type Metrics interface {
+	// CommandDone reports a finished command, e.g. "cast": how long it took
+	// and its error, if any.
+	CommandDone(command string, took time.Duration, err error)
+}
+
+// EventStore persists each room's ordered, append-only event stream together
+// with its latest snapshot.
+type EventStore interface {
+	// Append adds records to the end of the room's stream; the first record's
+	// Seq must be one past the last stored one (1 for a new stream) and the
+	// rest must follow without gaps, else ErrStreamConflict.
+	Append(ctx context.Context, roomID domain.RoomID, records []EventRecord) error
+	// Load returns the room's records with Seq > after, in order.
+	Load(ctx context.Context, roomID domain.RoomID, after uint64) ([]EventRecord, error)
+	// SaveSnapshot replaces the room's snapshot. The store may then drop the
+	// records the snapshot covers, so Load is only asked for later ones.
+	SaveSnapshot(ctx context.Context, roomID domain.RoomID, snap StateSnapshot) error
+	// LoadSnapshot returns the room's latest snapshot, if any.
+	LoadSnapshot(ctx context.Context, roomID domain.RoomID) (StateSnapshot, bool, error)
+	// Delete removes the room's stream and snapshot.
+	Delete(ctx context.Context, roomID domain.RoomID) error
+	// List returns the IDs of all rooms with a stream, in no particular order.
+	List(ctx context.Context) ([]domain.RoomID, error)
+}
@@ -25,7 +25,9 @@ func (f *fakeRepo) Create(ctx context.Context, room *domain.Room) error { return
 func (f *fakeRepo) Get(ctx context.Context, id domain.RoomID) (*domain.Room, bool, error) {
 	return nil, false, nil
 }
+func (f *fakeRepo) Save(ctx context.Context, room *domain.Room) error  { return nil }
 func (f *fakeRepo) Delete(ctx context.Context, id domain.RoomID) error { return nil }
+func (f *fakeRepo) List(ctx context.Context) ([]domain.RoomID, error)  { return nil, nil }
 
 type fakeIDGen struct{}
 
@@ -0,0 +1,67 @@
+package app
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jaminalder/estimations/internal/domain"
+)
+
+// roomPresence counts the open event streams of each participant and
+// remembers when a participant's last stream closed. Participants that never
+// open a stream (e.g. API clients) are not tracked at all.
+type roomPresence struct {
+	mu    sync.Mutex
+	conns map[presenceKey]int
+	gone  map[presenceKey]time.Time // last stream closed at
+}
+
+type presenceKey struct {
+	room domain.RoomID
+	pid  domain.ParticipantID
+}
+
+func (p *roomPresence) connect(k presenceKey) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conns == nil {
+		p.conns = make(map[presenceKey]int)
+		p.gone = make(map[presenceKey]time.Time)
+	}
+	p.conns[k]++
+	delete(p.gone, k)
+}
+
+func (p *roomPresence) disconnect(k presenceKey, at time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conns[k]--; p.conns[k] > 0 {
+		return
+	}
+	delete(p.conns, k)
+	p.gone[k] = at
+}
+
+// claimGone removes and returns the participants disconnected since before
+// deadline.
+func (p *roomPresence) claimGone(deadline time.Time) []presenceKey {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var out []presenceKey
+	for k, at := range p.gone {
+		if !at.After(deadline) {
+			out = append(out, k)
+			delete(p.gone, k)
+		}
+	}
+	return out
+}
+
+// Connect marks the participant present while one of its event streams is
+// open; call the returned func when the stream closes. See LeaveDisconnected.
+func (s *Service) Connect(roomID domain.RoomID, participantID domain.ParticipantID) (disconnect func()) {
+	k := presenceKey{room: roomID, pid: participantID}
+	s.presence.connect(k)
+	var once sync.Once
+	return func() { once.Do(func() { s.presence.disconnect(k, s.now()) }) }
+}
@@ -0,0 +1,84 @@
+package app
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jaminalder/estimations/internal/domain"
+)
+
+// ErrInvalidRecoveryCode is returned by Rejoin when no participant of the
+// room has the given recovery code.
+var ErrInvalidRecoveryCode = errors.New("invalid recovery code")
+
+// CrockfordAlphabet is Crockford's base32 alphabet: digits and upper-case
+// letters without I, L, O and U, so codes survive being read aloud or
+// retyped. Recovery codes and room codes (idgen.Codes) are written in it,
+// and NormalizeCode undoes the slips it forgives.
+const CrockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+var crockford = base32.NewEncoding(CrockfordAlphabet).WithPadding(base32.NoPadding)
+
+// RecoveryCode returns the code with which a participant can reattach to
+// the room from another browser (see Rejoin), formatted as two groups of
+// four characters. Like PublicID it is derived from the participant ID, so
+// it needs no storage and lives exactly as long as the participant; unlike
+// PublicID it is a credential and must only be shown to the participant.
+// Eight characters give 40 bits, and a guess is only good for one room.
+func RecoveryCode(id domain.ParticipantID) string {
+	sum := sha256.Sum256([]byte("recovery\x00" + string(id)))
+	code := crockford.EncodeToString(sum[:5])
+	return code[:4] + "-" + code[4:]
+}
+
+// NormalizeCode brings a code in CrockfordAlphabet as a person typed it (a
+// recovery code, or a room code from idgen.Codes) into canonical form,
+// undoing what the alphabet forgives: case, separators, O for 0 and I or L
+// for 1.
+func NormalizeCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '-', ' ':
+			return -1
+		case 'o', 'O':
+			return '0'
+		case 'i', 'I', 'l', 'L':
+			return '1'
+		}
+		if 'a' <= r && r <= 'z' {
+			return r - 'a' + 'A'
+		}
+		return r
+	}, code)
+}
+
+// Rejoin returns the participant of the room whose recovery code is code,
+// so a browser that lost (or never had) the participant's session can take
+// it over, votes and role included. Like wrong passcodes, wrong codes are
+// limited per client (see WithClient).
+func (s *Service) Rejoin(ctx context.Context, roomID domain.RoomID, code string) (pid domain.ParticipantID, err error) {
+	cmd := &command{name: "rejoin", room: roomID}
+	defer s.observe(ctx, cmd, time.Now(), &err)
+	want := []byte(NormalizeCode(code))
+	client := clientOf(ctx)
+	err = s.inRoom(ctx, roomID, func(room *domain.Room) error {
+		if !s.wrongCodes.allow(roomID, client, s.now()) {
+			return ErrTooManyAttempts
+		}
+		for _, p := range room.Participants() {
+			if subtle.ConstantTimeCompare([]byte(NormalizeCode(RecoveryCode(p.ID))), want) == 1 {
+				pid = p.ID
+				return nil
+			}
+		}
+		s.wrongCodes.fail(roomID, client, s.now())
+		return ErrInvalidRecoveryCode
+	})
+	cmd.by = pid
+	return pid, err
+}
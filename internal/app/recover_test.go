@@ -0,0 +1,82 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jaminalder/estimations/internal/domain"
+)
+
+func TestRecoveryCode_Format(t *testing.T) {
+	code := RecoveryCode("p1")
+	if len(code) != 9 || code[4] != '-' {
+		t.Fatalf("want XXXX-XXXX, got %q", code)
+	}
+	if strings.ContainsAny(code, "ILOU") {
+		t.Fatalf("code %q uses ambiguous letters", code)
+	}
+	if code != RecoveryCode("p1") || code == RecoveryCode("p2") {
+		t.Fatalf("codes should be stable per participant and differ between them")
+	}
+	if strings.Contains(strings.ToLower(code), PublicID("p1")[:4]) {
+		t.Fatalf("code %q should not be derivable from the public ID", code)
+	}
+}
+
+func TestRejoin_FindsParticipantByCode(t *testing.T) {
+	ctx := context.Background()
+	repo := &leaveRepo{}
+	roomID := domain.RoomID("r1")
+	room := domain.NewRoom(roomID)
+	_ = repo.Create(ctx, room)
+	_ = room.Join("p1", "Alice")
+	_ = room.Join("p2", "Bob")
+	_ = room.CastVote("p2", "5")
+	svc := &Service{Rooms: repo}
+
+	// Codes are forgiving about case, separators and look-alike characters.
+	typed := strings.NewReplacer("-", " ", "0", "o", "1", "l").Replace(strings.ToLower(RecoveryCode("p2")))
+	pid, err := svc.Rejoin(ctx, roomID, typed)
+	if err != nil || pid != "p2" {
+		t.Fatalf("rejoin %q: got %q, %v", typed, pid, err)
+	}
+	if room.Votes()["p2"] != "5" {
+		t.Fatalf("rejoining must keep the vote")
+	}
+
+	for _, code := range []string{"", "0000-0000", RecoveryCode("p3")} {
+		if _, err := svc.Rejoin(ctx, roomID, code); !errors.Is(err, ErrInvalidRecoveryCode) {
+			t.Fatalf("rejoin %q: got %v want ErrInvalidRecoveryCode", code, err)
+		}
+	}
+	_ = room.Leave("p2")
+	if _, err := svc.Rejoin(ctx, roomID, RecoveryCode("p2")); !errors.Is(err, ErrInvalidRecoveryCode) {
+		t.Fatalf("code of a participant who left: got %v", err)
+	}
+	if _, err := svc.Rejoin(ctx, "nope", RecoveryCode("p1")); !errors.Is(err, ErrRoomNotFound) {
+		t.Fatalf("unknown room: got %v", err)
+	}
+}
+
+func TestRejoin_ThrottlesWrongCodes(t *testing.T) {
+	ctx := WithClient(context.Background(), "mallory")
+	repo := &leaveRepo{}
+	room := domain.NewRoom("r1")
+	_ = repo.Create(ctx, room)
+	_ = room.Join("p1", "Alice")
+	svc := &Service{Rooms: repo}
+
+	for i := range maxFailures {
+		if _, err := svc.Rejoin(ctx, "r1", "0000-0000"); !errors.Is(err, ErrInvalidRecoveryCode) {
+			t.Fatalf("guess %d: got %v want ErrInvalidRecoveryCode", i, err)
+		}
+	}
+	if _, err := svc.Rejoin(ctx, "r1", RecoveryCode("p1")); !errors.Is(err, ErrTooManyAttempts) {
+		t.Fatalf("rejoin after too many guesses: got %v want ErrTooManyAttempts", err)
+	}
+	if pid, err := svc.Rejoin(WithClient(ctx, "alice"), "r1", RecoveryCode("p1")); err != nil || pid != "p1" {
+		t.Fatalf("another client should not be kept out: %q, %v", pid, err)
+	}
+}
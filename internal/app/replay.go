@@ -0,0 +1,160 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+
+	"github.com/jaminalder/estimations/internal/domain"
+)
+
+// eventTypes are the events recorded in room streams, by name, each with a
+// constructor to decode into. Events not listed here (e.g. RoomExpired) are
+// broadcast only.
+var eventTypes = map[string]func() any{
+	"RoomCreated":        func() any { return new(RoomCreated) },
+	"ParticipantJoined":  func() any { return new(ParticipantJoined) },
+	"ParticipantLeft":    func() any { return new(ParticipantLeft) },
+	"FacilitatorChanged": func() any { return new(FacilitatorChanged) },
+	"VoteCast":           func() any { return new(VoteCast) },
+	"VoteCleared":        func() any { return new(VoteCleared) },
+	"VotesRevealed":      func() any { return new(VotesRevealed) },
+	"RoundLabeled":       func() any { return new(RoundLabeled) },
+	"RoundReset":         func() any { return new(RoundReset) },
+	"StoriesChanged":     func() any { return new(StoriesChanged) },
+	"StoryEstimated":     func() any { return new(StoryEstimated) },
+	"RoomLocked":         func() any { return new(RoomLocked) },
+}
+
+// eventName is the name an event is broadcast and recorded under.
+func eventName(event any) string { return reflect.TypeOf(event).Name() }
+
+// ReplayRooms rebuilds every room in the EventStore from its latest snapshot
+// and the events recorded after it, adds it to Rooms and returns how many
+// rooms were restored. It is meant to run once at startup, with an empty
+// RoomRepo, before the service handles requests. A room whose stream cannot
+// be replayed is logged and left out, its stream untouched for inspection,
+// so one broken room does not keep the others from starting.
+func (s *Service) ReplayRooms(ctx context.Context) (int, error) {
+	if s.Events == nil {
+		return 0, nil
+	}
+	ids, err := s.Events.List(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("replay rooms: %w", err)
+	}
+	n := 0
+	for _, id := range ids {
+		room, seq, snap, err := s.replayRoom(ctx, id)
+		if err != nil {
+			if s.Logger != nil {
+				s.Logger.LogAttrs(ctx, slog.LevelError, "replay room skipped", RoomAttr(id), slog.String("error", err.Error()))
+			}
+			continue
+		}
+		if err := s.Rooms.Create(ctx, room); err != nil {
+			return n, fmt.Errorf("replay room %s: %w", id, err)
+		}
+		st := s.journal.stream(id)
+		st.seq, st.snap = seq, snap
+		s.activity.touch(id)
+		n++
+	}
+	return n, nil
+}
+
+// replayRoom rebuilds a room from its stream and returns it with the Seq of
+// its last event and of the snapshot replay started from (0 if none).
+func (s *Service) replayRoom(ctx context.Context, id domain.RoomID) (room *domain.Room, seq, snap uint64, err error) {
+	snapshot, ok, err := s.Events.LoadSnapshot(ctx, id)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if ok {
+		if room, err = domain.RestoreRoom(snapshot.State); err != nil {
+			return nil, 0, 0, err
+		}
+		seq, snap = snapshot.Seq, snapshot.Seq
+	}
+	records, err := s.Events.Load(ctx, id, seq)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	for _, rec := range records {
+		if rec.Seq != seq+1 {
+			return nil, 0, 0, fmt.Errorf("event %d follows %d", rec.Seq, seq)
+		}
+		if room, err = applyRecord(id, room, rec); err != nil {
+			return nil, 0, 0, fmt.Errorf("event %d (%s): %w", rec.Seq, rec.Type, err)
+		}
+		seq = rec.Seq
+	}
+	if room == nil {
+		return nil, 0, 0, fmt.Errorf("empty stream")
+	}
+	return room, seq, snap, nil
+}
+
+// applyRecord re-runs the command behind a recorded event on room; room is nil
+// until RoomCreated. Commands acting "by" someone act by the facilitator of
+// the time, who is the one that issued them.
+func applyRecord(id domain.RoomID, room *domain.Room, rec EventRecord) (*domain.Room, error) {
+	newEvent, ok := eventTypes[rec.Type]
+	if !ok {
+		return nil, fmt.Errorf("unknown event type")
+	}
+	ev := newEvent()
+	if err := json.Unmarshal(rec.Data, ev); err != nil {
+		return nil, err
+	}
+	if created, ok := ev.(*RoomCreated); ok {
+		if room != nil {
+			return nil, fmt.Errorf("room already created")
+		}
+		return domain.RestoreRoom(domain.RoomState{ID: id, Title: created.Title, DeckName: created.Deck, DeckCards: created.Cards, Passcode: created.Passcode})
+	}
+	if room == nil {
+		return nil, fmt.Errorf("stream does not start with RoomCreated")
+	}
+	facilitator := room.Facilitator()
+	var err error
+	switch ev := ev.(type) {
+	case *ParticipantJoined:
+		if ev.Creator {
+			err = room.JoinAsCreator(ev.ParticipantID, ev.Name, ev.Kind)
+		} else {
+			err = room.JoinAs(ev.ParticipantID, ev.Name, ev.Kind)
+		}
+	case *ParticipantLeft:
+		err = room.Leave(ev.ParticipantID)
+	case *FacilitatorChanged:
+		// Also recorded when the role passes on because the facilitator left.
+		if facilitator != ev.ParticipantID {
+			err = room.TransferFacilitator(facilitator, ev.ParticipantID)
+		}
+	case *VoteCast:
+		err = room.CastVote(ev.ParticipantID, ev.Card)
+	case *VoteCleared:
+		err = room.ClearVote(ev.ParticipantID)
+	case *VotesRevealed:
+		err = room.Reveal(facilitator, ev.At)
+	case *RoundLabeled:
+		err = room.SetLabel(facilitator, ev.Label)
+	case *RoundReset:
+		// NextStory already started the round its StoryEstimated stands for.
+		if room.RoundIndex() != ev.Round {
+			err = room.Reset(facilitator, ev.At)
+		}
+	case *StoryEstimated:
+		err = room.NextStory(facilitator, ev.Estimate, ev.At)
+	case *RoomLocked:
+		err = room.SetLocked(facilitator, ev.Locked)
+	case *StoriesChanged:
+		st := room.State()
+		st.Stories, st.CurrentStory = ev.Stories, ev.Current
+		return domain.RestoreRoom(st)
+	}
+	return room, err
+}
@@ -0,0 +1,333 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jaminalder/estimations/internal/domain"
+)
+
+// eventsMem is a minimal EventStore keeping streams in maps.
+type eventsMem struct {
+	streams map[domain.RoomID][]EventRecord
+	snaps   map[domain.RoomID]StateSnapshot
+}
+
+func newEventsMem() *eventsMem {
+	return &eventsMem{streams: make(map[domain.RoomID][]EventRecord), snaps: make(map[domain.RoomID]StateSnapshot)}
+}
+
+func (e *eventsMem) Append(ctx context.Context, id domain.RoomID, records []EventRecord) error {
+	for _, rec := range records {
+		if rec.Seq != uint64(len(e.streams[id]))+1 {
+			return ErrStreamConflict
+		}
+		e.streams[id] = append(e.streams[id], rec)
+	}
+	return nil
+}
+
+func (e *eventsMem) Load(ctx context.Context, id domain.RoomID, after uint64) ([]EventRecord, error) {
+	return append([]EventRecord(nil), e.streams[id][after:]...), nil
+}
+
+func (e *eventsMem) SaveSnapshot(ctx context.Context, id domain.RoomID, snap StateSnapshot) error {
+	e.snaps[id] = snap
+	return nil
+}
+
+func (e *eventsMem) LoadSnapshot(ctx context.Context, id domain.RoomID) (StateSnapshot, bool, error) {
+	snap, ok := e.snaps[id]
+	return snap, ok, nil
+}
+
+func (e *eventsMem) Delete(ctx context.Context, id domain.RoomID) error {
+	delete(e.streams, id)
+	delete(e.snaps, id)
+	return nil
+}
+
+func (e *eventsMem) List(ctx context.Context) ([]domain.RoomID, error) {
+	var ids []domain.RoomID
+	for id := range e.streams {
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// replayed rebuilds the service's rooms in a fresh service over the same store.
+func replayed(t *testing.T, store EventStore, id domain.RoomID) *domain.Room {
+	t.Helper()
+	fresh := &Service{Rooms: &resetRepo{}, Events: store}
+	if n, err := fresh.ReplayRooms(context.Background()); err != nil || n != 1 {
+		t.Fatalf("replay: %d rooms, %v", n, err)
+	}
+	room, err := fresh.getRoom(context.Background(), id)
+	if err != nil {
+		t.Fatalf("replayed room: %v", err)
+	}
+	return room
+}
+
+func TestReplayRooms_RebuildsState(t *testing.T) {
+	ctx := context.Background()
+	store := newEventsMem()
+	clock := &manualClock{t: time.Date(2025, 9, 5, 9, 0, 0, 0, time.UTC)}
+	svc := &Service{Rooms: &resetRepo{}, Ids: &seqIDs{}, Clock: clock, Events: store}
+
+	deck, _ := domain.PresetDeck(domain.DeckTShirt)
+	roomID, err := svc.CreateRoom(ctx, WithDeck(deck), WithTitle("Sprint 7"), WithPasscode("s3cret"))
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	alice, _ := svc.Join(ctx, roomID, "Alice", UsingPasscode("s3cret"))
+	bob, _ := svc.Join(ctx, roomID, "Bob", UsingPasscode("s3cret"))
+	olga, _ := svc.Join(ctx, roomID, "Olga", AsObserver(), UsingPasscode("s3cret"))
+	steps := []error{
+		svc.AddStory(ctx, roomID, alice, domain.Story{Title: "Login", Key: "K-1"}),
+		svc.AddStory(ctx, roomID, alice, domain.Story{Title: "Logout"}),
+		svc.AddStory(ctx, roomID, alice, domain.Story{Title: "Profile"}),
+		svc.MoveStory(ctx, roomID, alice, 2, 1),
+		svc.Cast(ctx, roomID, alice, "M"),
+		svc.Cast(ctx, roomID, bob, "L"),
+		svc.Clear(ctx, roomID, bob),
+		svc.Cast(ctx, roomID, bob, "M"),
+		svc.Reveal(ctx, roomID, alice),
+		svc.NextStory(ctx, roomID, alice, "M"),
+		svc.SetLabel(ctx, roomID, alice, "Profile page"),
+		svc.Cast(ctx, roomID, bob, "S"),
+		svc.Reveal(ctx, roomID, alice),
+		svc.Reset(ctx, roomID, alice),
+		svc.RemoveStory(ctx, roomID, alice, 2),
+		svc.TransferFacilitator(ctx, roomID, alice, bob),
+		svc.Cast(ctx, roomID, alice, "XL"),
+		svc.Leave(ctx, roomID, bob),
+		svc.Leave(ctx, roomID, olga),
+		svc.LockRoom(ctx, roomID, alice, true),
+	}
+	for i, err := range steps {
+		if err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+		clock.Advance(time.Minute)
+	}
+	// Rejected commands record nothing.
+	before := len(store.streams[roomID])
+	if err := svc.Cast(ctx, roomID, alice, "13"); err == nil {
+		t.Fatalf("card outside deck should be rejected")
+	}
+	if len(store.streams[roomID]) != before {
+		t.Fatalf("rejected command must not be recorded")
+	}
+
+	for i, rec := range store.streams[roomID] {
+		if rec.Seq != uint64(i)+1 || rec.Type == "" || rec.At.IsZero() {
+			t.Fatalf("record %d malformed: %+v", i, rec)
+		}
+	}
+	if store.streams[roomID][0].Type != "RoomCreated" {
+		t.Fatalf("stream should start with RoomCreated, got %s", store.streams[roomID][0].Type)
+	}
+
+	want, _ := svc.getRoom(ctx, roomID)
+	if got := replayed(t, store, roomID); !reflect.DeepEqual(got.State(), want.State()) {
+		t.Fatalf("replay mismatch:\n got %+v\nwant %+v", got.State(), want.State())
+	}
+}
+
+func TestReplayRooms_FromSnapshot(t *testing.T) {
+	ctx := context.Background()
+	store := newEventsMem()
+	svc := &Service{Rooms: &resetRepo{}, Ids: &seqIDs{}, Events: store}
+	roomID, _ := svc.CreateRoom(ctx)
+	alice, _ := svc.Join(ctx, roomID, "Alice")
+	for i := 0; i < snapshotEvery; i++ {
+		_ = svc.Cast(ctx, roomID, alice, "5")
+	}
+	_ = svc.Cast(ctx, roomID, alice, "8")
+
+	snap, ok := store.snaps[roomID]
+	if !ok || snap.Seq != snapshotEvery {
+		t.Fatalf("expected a snapshot at %d, got %+v (%v)", snapshotEvery, snap.Seq, ok)
+	}
+	// Replay must not need the events the snapshot covers.
+	store.streams[roomID] = append(make([]EventRecord, snap.Seq), store.streams[roomID][snap.Seq:]...)
+
+	got := replayed(t, store, roomID)
+	if got.Votes()[alice] != "8" || len(got.Participants()) != 1 {
+		t.Fatalf("unexpected replayed room: %+v", got.State())
+	}
+
+	// The replaying service continues the stream where it left off.
+	fresh := &Service{Rooms: &resetRepo{}, Events: store}
+	_, _ = fresh.ReplayRooms(ctx)
+	if err := fresh.Cast(ctx, roomID, alice, "13"); err != nil {
+		t.Fatalf("cast after replay: %v", err)
+	}
+}
+
+func TestExpireRooms_DeletesStream(t *testing.T) {
+	ctx := context.Background()
+	store := newEventsMem()
+	clock := &manualClock{t: time.Date(2025, 9, 5, 9, 0, 0, 0, time.UTC)}
+	svc := &Service{Rooms: &resetRepo{}, Ids: &seqIDs{}, Clock: clock, Events: store}
+	roomID, _ := svc.CreateRoom(ctx)
+	policy := ExpiryPolicy{EmptyTTL: time.Minute}
+	_, _ = svc.ExpireRooms(ctx, policy)
+	clock.Advance(time.Minute)
+	if got, err := svc.ExpireRooms(ctx, policy); err != nil || len(got) != 1 {
+		t.Fatalf("expire: %v, %v", got, err)
+	}
+	if _, ok := store.streams[roomID]; ok {
+		t.Fatalf("expired room's stream should be deleted")
+	}
+}
+
+func TestEmit_LogKeepsIDsBroadcastDoesNot(t *testing.T) {
+	ctx := context.Background()
+	store := newEventsMem()
+	bus := &resetBus{}
+	svc := &Service{Rooms: &resetRepo{}, Ids: &seqIDs{}, Bus: bus, Events: store}
+	roomID, _ := svc.CreateRoom(ctx)
+	alice, _ := svc.Join(ctx, roomID, "Alice")
+	if err := svc.Cast(ctx, roomID, alice, "5"); err != nil {
+		t.Fatalf("cast: %v", err)
+	}
+
+	recs := store.streams[roomID]
+	logged := string(recs[len(recs)-1].Data)
+	if !strings.Contains(logged, `"ParticipantID":"`+string(alice)+`"`) || !strings.Contains(logged, `"Card":"5"`) {
+		t.Fatalf("event log must keep the vote: %s", logged)
+	}
+	if got := bus.events[len(bus.events)-1]; got != (VoteCast{RoomID: roomID, Participant: PublicID(alice)}) {
+		t.Fatalf("broadcast must hide ID and card, got %#v", got)
+	}
+}
+
+// failingBus fails every broadcast, as the SSE hub does once the request
+// that caused it is gone.
+type failingBus struct{ calls int }
+
+func (b *failingBus) Broadcast(ctx context.Context, roomID domain.RoomID, event any) error {
+	b.calls++
+	return context.Canceled
+}
+
+func TestWithRoom_FailedBroadcastKeepsCommittedCommand(t *testing.T) {
+	ctx := context.Background()
+	store := newEventsMem()
+	bus := &failingBus{}
+	svc := &Service{Rooms: &resetRepo{}, Ids: &seqIDs{}, Bus: bus, Events: store}
+	roomID, _ := svc.CreateRoom(ctx)
+	if _, err := svc.Join(ctx, roomID, "Alice"); err != nil {
+		t.Fatalf("join must succeed once recorded: %v", err)
+	}
+	if bus.calls == 0 {
+		t.Fatalf("join should have been broadcast")
+	}
+	if got := store.streams[roomID][len(store.streams[roomID])-1].Type; got != "ParticipantJoined" {
+		t.Fatalf("last recorded event = %s", got)
+	}
+}
+
+// appendFails is an EventStore whose appends fail once fail is set.
+type appendFails struct {
+	*eventsMem
+	fail bool
+}
+
+func (e *appendFails) Append(ctx context.Context, id domain.RoomID, records []EventRecord) error {
+	if e.fail {
+		return errors.New("disk full")
+	}
+	return e.eventsMem.Append(ctx, id, records)
+}
+
+func TestWithRoom_UnrecordedCommandIsRolledBackAndNotBroadcast(t *testing.T) {
+	ctx := context.Background()
+	store := &appendFails{eventsMem: newEventsMem()}
+	bus := &resetBus{}
+	svc := &Service{Rooms: &resetRepo{}, Ids: &seqIDs{}, Bus: bus, Events: store}
+	roomID, _ := svc.CreateRoom(ctx)
+	store.fail = true
+	if _, err := svc.Join(ctx, roomID, "Alice"); err == nil {
+		t.Fatalf("join should fail when it cannot be recorded")
+	}
+	room, _ := svc.getRoom(ctx, roomID)
+	if n := len(room.Participants()); n != 0 {
+		t.Fatalf("unrecorded join left %d participants", n)
+	}
+	if len(bus.events) != 0 {
+		t.Fatalf("unrecorded join was broadcast: %v", bus.events)
+	}
+}
+
+func TestReplayRooms_SkipsBrokenStream(t *testing.T) {
+	ctx := context.Background()
+	store := newEventsMem()
+	svc := &Service{Rooms: &resetRepo{}, Ids: &seqIDs{}, Events: store}
+	good, _ := svc.CreateRoom(ctx)
+	store.streams["broken"] = []EventRecord{{Seq: 1, Type: "ParticipantLeft", Data: []byte(`{"RoomID":"broken","ParticipantID":"ghost"}`)}}
+
+	fresh := &Service{Rooms: &resetRepo{}, Events: store}
+	if n, err := fresh.ReplayRooms(ctx); err != nil || n != 1 {
+		t.Fatalf("replay: %d rooms, %v", n, err)
+	}
+	if _, err := fresh.getRoom(ctx, good); err != nil {
+		t.Fatalf("good room not replayed: %v", err)
+	}
+	if _, ok := store.streams["broken"]; !ok {
+		t.Fatalf("broken stream should be kept for inspection")
+	}
+}
+
+func TestReplayRooms_ClearedLabelFollowsStory(t *testing.T) {
+	ctx := context.Background()
+	store := newEventsMem()
+	svc := &Service{Rooms: &resetRepo{}, Ids: &seqIDs{}, Events: store}
+	roomID, _ := svc.CreateRoom(ctx)
+	alice, _ := svc.Join(ctx, roomID, "Alice")
+	steps := []error{
+		svc.AddStory(ctx, roomID, alice, domain.Story{Title: "Login"}),
+		svc.AddStory(ctx, roomID, alice, domain.Story{Title: "Logout"}),
+		svc.SetLabel(ctx, roomID, alice, "Spike"),
+		svc.SetLabel(ctx, roomID, alice, " "),
+		svc.MoveStory(ctx, roomID, alice, 1, 0),
+	}
+	for i, err := range steps {
+		if err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+	}
+	live, _ := svc.getRoom(ctx, roomID)
+	if got := replayed(t, store, roomID); got.Label() != "Logout" || !reflect.DeepEqual(got.State(), live.State()) {
+		t.Fatalf("replayed label %q, live %q", got.Label(), live.Label())
+	}
+}
+
+func TestJoin_CreatorTakesOverAndReplays(t *testing.T) {
+	ctx := context.Background()
+	store := newEventsMem()
+	bus := &resetBus{}
+	svc := &Service{Rooms: &resetRepo{}, Ids: &seqIDs{}, Bus: bus, Events: store}
+	roomID, _ := svc.CreateRoom(ctx, WithPasscode("s3cret"))
+	mallory, _ := svc.Join(ctx, roomID, "Mallory", UsingPasscode("s3cret"))
+	if err := svc.LockRoom(ctx, roomID, mallory, true); err != nil {
+		t.Fatalf("lock: %v", err)
+	}
+	alice, err := svc.Join(ctx, roomID, "Alice", AsCreator())
+	if err != nil {
+		t.Fatalf("creator join: %v", err)
+	}
+	if got := bus.events[len(bus.events)-1]; got != (FacilitatorChanged{RoomID: roomID, Participant: PublicID(alice)}) {
+		t.Fatalf("want FacilitatorChanged to the creator, got %#v", got)
+	}
+	live, _ := svc.getRoom(ctx, roomID)
+	if got := replayed(t, store, roomID); !got.IsFacilitator(alice) || !reflect.DeepEqual(got.State(), live.State()) {
+		t.Fatalf("replay mismatch:\n got %+v\nwant %+v", got.State(), live.State())
+	}
+}
@@ -7,17 +7,15 @@ import (
 	"github.com/jaminalder/estimations/internal/domain"
 )
 
-// Reset clears all votes, increments round, and reopens voting. Broadcasts RoundReset with new round index.
-func (s *Service) Reset(ctx context.Context, roomID domain.RoomID) error {
-	room, err := s.getRoom(ctx, roomID)
-	if err != nil {
-		return err
-	}
-	if err := room.Reset(); err != nil {
-		return fmt.Errorf("reset: %w", err)
-	}
-	if err := s.emit(ctx, roomID, RoundReset{RoomID: roomID, Round: room.RoundIndex()}); err != nil {
-		return err
-	}
-	return nil
+// Reset clears all votes, increments round, and reopens voting; only the
+// facilitator may reset. Broadcasts RoundReset with new round index.
+func (s *Service) Reset(ctx context.Context, roomID domain.RoomID, by domain.ParticipantID) error {
+	return s.withRoom(ctx, &command{name: "reset", room: roomID, by: by}, func(room *domain.Room) error {
+		now := s.now()
+		if err := room.Reset(by, now); err != nil {
+			return fmt.Errorf("reset: %w", err)
+		}
+		s.emit(roomID, RoundReset{RoomID: roomID, Round: room.RoundIndex(), At: now})
+		return nil
+	})
 }
@@ -3,6 +3,7 @@ package app
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/jaminalder/estimations/internal/domain"
 )
@@ -24,11 +25,24 @@ func (r *resetRepo) Get(ctx context.Context, id domain.RoomID) (*domain.Room, bo
 	return rm, ok, nil
 }
 
+func (r *resetRepo) Save(ctx context.Context, room *domain.Room) error {
+	r.rooms[room.ID()] = room
+	return nil
+}
+
 func (r *resetRepo) Delete(ctx context.Context, id domain.RoomID) error {
 	delete(r.rooms, id)
 	return nil
 }
 
+func (r *resetRepo) List(ctx context.Context) ([]domain.RoomID, error) {
+	ids := make([]domain.RoomID, 0, len(r.rooms))
+	for id := range r.rooms {
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
 type resetBus struct{ events []any }
 
 func (b *resetBus) Broadcast(ctx context.Context, roomID domain.RoomID, event any) error {
@@ -48,12 +62,12 @@ func TestReset_IncrementsRound_ClearsVotes_Broadcasts(t *testing.T) {
 	_ = room.Join(p2, "Bob")
 	_ = room.CastVote(p1, "5")
 	_ = room.CastVote(p2, "8")
-	_ = room.Reveal()
+	_ = room.Reveal(p1, time.Now())
 	before := room.RoundIndex()
 
 	bus := &resetBus{}
 	svc := &Service{Rooms: repo, Bus: bus}
-	if err := svc.Reset(ctx, roomID); err != nil {
+	if err := svc.Reset(ctx, roomID, p1); err != nil {
 		t.Fatalf("reset: %v", err)
 	}
 
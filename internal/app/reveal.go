@@ -7,20 +7,19 @@ import (
 	"github.com/jaminalder/estimations/internal/domain"
 )
 
-// Reveal reveals the votes if at least one vote exists. Emits VotesRevealed once.
-func (s *Service) Reveal(ctx context.Context, roomID domain.RoomID) error {
-	room, err := s.getRoom(ctx, roomID)
-	if err != nil {
-		return err
-	}
-	wasRevealed := room.IsRevealed()
-	if err := room.Reveal(); err != nil {
-		return fmt.Errorf("reveal: %w", err)
-	}
-	if !wasRevealed {
-		if err := s.emit(ctx, roomID, VotesRevealed{RoomID: roomID}); err != nil {
-			return err
+// Reveal reveals the votes if at least one vote exists; only the facilitator
+// may reveal. Emits VotesRevealed once.
+func (s *Service) Reveal(ctx context.Context, roomID domain.RoomID, by domain.ParticipantID) error {
+	return s.withRoom(ctx, &command{name: "reveal", room: roomID, by: by}, func(room *domain.Room) error {
+		wasRevealed := room.IsRevealed()
+		now := s.now()
+		if err := room.Reveal(by, now); err != nil {
+			return fmt.Errorf("reveal: %w", err)
 		}
-	}
-	return nil
+		if wasRevealed {
+			return nil
+		}
+		s.emit(roomID, VotesRevealed{RoomID: roomID, Stats: room.Stats(), At: now})
+		return nil
+	})
 }
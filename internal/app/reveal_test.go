@@ -24,11 +24,15 @@ func (r *revealRepo) Get(ctx context.Context, id domain.RoomID) (*domain.Room, b
 	return rm, ok, nil
 }
 
+func (r *revealRepo) Save(ctx context.Context, room *domain.Room) error { return nil }
+
 func (r *revealRepo) Delete(ctx context.Context, id domain.RoomID) error {
 	delete(r.rooms, id)
 	return nil
 }
 
+func (r *revealRepo) List(ctx context.Context) ([]domain.RoomID, error) { return nil, nil }
+
 type revealBus struct{ events []any }
 
 func (b *revealBus) Broadcast(ctx context.Context, roomID domain.RoomID, event any) error {
@@ -53,18 +57,22 @@ func TestReveal_Success_EmitsOnce(t *testing.T) {
 	bus := &revealBus{}
 	svc := &Service{Rooms: repo, Bus: bus}
 
-	if err := svc.Reveal(ctx, roomID); err != nil {
+	if err := svc.Reveal(ctx, roomID, pid); err != nil {
 		t.Fatalf("reveal: %v", err)
 	}
 	if len(bus.events) != 1 {
 		t.Fatalf("expected 1 event, got %d", len(bus.events))
 	}
-	if _, ok := bus.events[0].(VotesRevealed); !ok {
+	evt, ok := bus.events[0].(VotesRevealed)
+	if !ok {
 		t.Fatalf("wrong event: %T", bus.events[0])
 	}
+	if evt.Stats.Votes != 1 || evt.Stats.Mean != 8 || !evt.Stats.Consensus {
+		t.Fatalf("event should carry vote statistics: %+v", evt.Stats)
+	}
 
 	// Idempotent: calling again should not emit a second event
-	if err := svc.Reveal(ctx, roomID); err != nil {
+	if err := svc.Reveal(ctx, roomID, pid); err != nil {
 		t.Fatalf("second reveal should be ok: %v", err)
 	}
 	if len(bus.events) != 1 {
@@ -82,7 +90,7 @@ func TestReveal_NoVotes_NoBroadcast(t *testing.T) {
 
 	bus := &revealBus{}
 	svc := &Service{Rooms: repo, Bus: bus}
-	if err := svc.Reveal(ctx, roomID); err == nil {
+	if err := svc.Reveal(ctx, roomID, "p1"); err == nil {
 		t.Fatalf("expected reveal to fail without votes")
 	}
 	if len(bus.events) != 0 {
@@ -0,0 +1,89 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jaminalder/estimations/internal/domain"
+)
+
+// RoomSummary is an operator's view of a room (see ListRooms).
+type RoomSummary struct {
+	ID        domain.RoomID
+	Voters    int
+	Observers int
+	Round     int
+	Revealed  bool
+	// LastActive is when a command last succeeded in the room, accurate to
+	// one reaper sweep; zero if none has since the server started.
+	LastActive time.Time
+}
+
+// ListRooms summarizes every stored room, in no particular order. Rooms
+// removed while listing are left out.
+func (s *Service) ListRooms(ctx context.Context) ([]RoomSummary, error) {
+	ids, err := s.Rooms.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list rooms: %w", err)
+	}
+	now := s.now()
+	out := make([]RoomSummary, 0, len(ids))
+	for _, id := range ids {
+		var sum RoomSummary
+		err := s.inRoom(ctx, id, func(room *domain.Room) error {
+			sum = RoomSummary{
+				ID:         id,
+				Round:      room.RoundIndex(),
+				Revealed:   room.IsRevealed(),
+				LastActive: s.activity.lastActive(id, now),
+			}
+			for _, p := range room.Participants() {
+				if p.IsObserver() {
+					sum.Observers++
+				} else {
+					sum.Voters++
+				}
+			}
+			return nil
+		})
+		if errors.Is(err, ErrRoomNotFound) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("list rooms: %w", err)
+		}
+		out = append(out, sum)
+	}
+	return out, nil
+}
+
+// CloseRoom removes a room at once, as the reaper would an expired one: its
+// clients get RoomExpired with reason ExpiredClosed.
+func (s *Service) CloseRoom(ctx context.Context, roomID domain.RoomID) (err error) {
+	defer s.observe(ctx, &command{name: "close_room", room: roomID}, time.Now(), &err)
+	unlock := s.locks.lock(roomID)
+	defer unlock()
+	if _, err := s.getRoom(ctx, roomID); err != nil {
+		return err
+	}
+	if err := s.removeRoom(ctx, roomID, ExpiredClosed); err != nil {
+		return fmt.Errorf("close room: %w", err)
+	}
+	return nil
+}
+
+// Pinger is implemented by room repositories that can check their backing
+// store more cheaply than listing it.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Ping reports whether the room repository answers, for readiness checks.
+func (s *Service) Ping(ctx context.Context) error {
+	if p, ok := s.Rooms.(Pinger); ok {
+		return p.Ping(ctx)
+	}
+	_, err := s.Rooms.List(ctx)
+	return err
+}
@@ -0,0 +1,94 @@
+package app
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jaminalder/estimations/internal/domain"
+)
+
+// commandLog records what Service reports to Metrics.
+type commandLog struct{ done []string }
+
+func (c *commandLog) CommandDone(command string, took time.Duration, err error) {
+	outcome := "ok"
+	if err != nil {
+		outcome = err.Error()
+	}
+	c.done = append(c.done, command+": "+outcome)
+}
+
+func TestListRooms_CountsParticipants(t *testing.T) {
+	ctx := context.Background()
+	clock := &manualClock{t: time.Date(2025, 9, 4, 9, 0, 0, 0, time.UTC)}
+	svc := &Service{Rooms: &syncRepo{}, Clock: clock}
+	svc.Ids = idFixed{rid: "a"}
+	a, _ := svc.CreateRoom(ctx)
+	svc.Ids = idFixed{rid: "b"}
+	b, _ := svc.CreateRoom(ctx)
+	svc.Ids = &seqIDs{}
+	alice, _ := svc.Join(ctx, a, "alice")
+	_, _ = svc.Join(ctx, a, "bob")
+	_, _ = svc.Join(ctx, a, "olga", AsObserver())
+	_ = svc.Cast(ctx, a, alice, "5")
+	_ = svc.Reveal(ctx, a, alice)
+	start := clock.t
+	_, _ = svc.ExpireRooms(ctx, ExpiryPolicy{})
+	clock.Advance(time.Hour)
+
+	got, err := svc.ListRooms(ctx)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	slices.SortFunc(got, func(x, y RoomSummary) int { return cmp.Compare(x.ID, y.ID) })
+	want := []RoomSummary{
+		{ID: a, Voters: 2, Observers: 1, Revealed: true, LastActive: start},
+		{ID: b, LastActive: start},
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("got %+v want %+v", got, want)
+	}
+}
+
+func TestCloseRoom_RemovesRoomAndTellsClients(t *testing.T) {
+	ctx := context.Background()
+	repo := &resetRepo{}
+	bus := &resetBus{}
+	svc := &Service{Rooms: repo, Bus: bus, Ids: idFixed{rid: "r1"}}
+	id, _ := svc.CreateRoom(ctx)
+
+	if err := svc.CloseRoom(ctx, id); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if _, ok, _ := repo.Get(ctx, id); ok {
+		t.Fatalf("closed room should be deleted")
+	}
+	if len(bus.events) != 1 || bus.events[0] != (RoomExpired{RoomID: id, Reason: ExpiredClosed}) {
+		t.Fatalf("expected RoomExpired(closed), got %#v", bus.events)
+	}
+	if err := svc.CloseRoom(ctx, id); !errors.Is(err, ErrRoomNotFound) {
+		t.Fatalf("closing again: got %v want ErrRoomNotFound", err)
+	}
+}
+
+func TestService_ReportsCommandsToMetrics(t *testing.T) {
+	ctx := context.Background()
+	log := &commandLog{}
+	svc := &Service{Rooms: &syncRepo{}, Ids: &seqIDs{}, Metrics: log}
+	roomID, _ := svc.CreateRoom(ctx)
+	pid, _ := svc.Join(ctx, roomID, "alice")
+	err := svc.Cast(ctx, roomID, pid, "XXL")
+	if !errors.Is(err, domain.ErrInvalidCard) {
+		t.Fatalf("cast: %v", err)
+	}
+	_ = svc.Reveal(ctx, "gone", pid)
+
+	want := []string{"create_room: ok", "join: ok", "cast: " + err.Error(), "reveal: room not found: gone"}
+	if !slices.Equal(log.done, want) {
+		t.Fatalf("got %q want %q", log.done, want)
+	}
+}
@@ -1,8 +1,31 @@
 package app
 
+import "log/slog"
+
 // Service aggregates application use-cases.
+//
+// Service is safe for concurrent use: every use-case touching a room runs
+// while holding that room's lock, so domain.Room (which is not itself
+// synchronized) only ever sees one command at a time.
 type Service struct {
 	Rooms RoomRepo
 	Ids   IdGen
 	Bus   Broadcaster
+	Clock Clock // optional; defaults to the system clock
+	// Events, if set, records every room's events so rooms can be rebuilt
+	// by replay (see ReplayRooms).
+	Events EventStore
+	// MaxParticipants and MaxObservers, if set, lower the per-room capacity
+	// below domain.MaxParticipants and domain.MaxObservers.
+	MaxParticipants int
+	MaxObservers    int
+	Metrics         Metrics      // optional
+	Logger          *slog.Logger // optional; logs the outcome of every command
+
+	locks          roomLocks
+	activity       roomActivity
+	presence       roomPresence
+	journal        roomJournal
+	wrongPasscodes failedAttempts
+	wrongCodes     failedAttempts // recovery codes
 }
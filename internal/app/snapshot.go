@@ -0,0 +1,81 @@
+package app
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jaminalder/estimations/internal/domain"
+)
+
+// RoomSnapshot is a read-only copy of a room's state, safe to use after the
+// room lock has been released (e.g. while rendering a page).
+type RoomSnapshot struct {
+	ID            domain.RoomID
+	Title         string
+	Participants  []domain.Participant
+	Facilitator   domain.ParticipantID
+	Votes         map[domain.ParticipantID]string
+	Deck          []string
+	DeckName      string
+	Revealed      bool
+	Stats         domain.Stats // only populated once revealed
+	Round         int
+	Label         string
+	History       []domain.RoundResult
+	Stories       []domain.Story
+	Current       int  // index into Stories of the story being estimated
+	HasPasscode   bool // whether newcomers need the room's passcode
+	Locked        bool // whether the room takes no newcomers
+	CreatorJoined bool // whether the creator's one-time claim was used
+}
+
+// Snapshot returns a consistent copy of the room's current state.
+func (s *Service) Snapshot(ctx context.Context, roomID domain.RoomID) (RoomSnapshot, error) {
+	var snap RoomSnapshot
+	err := s.inRoom(ctx, roomID, func(room *domain.Room) error {
+		snap = RoomSnapshot{
+			ID:            room.ID(),
+			Title:         room.Title(),
+			Participants:  room.Participants(),
+			Facilitator:   room.Facilitator(),
+			Votes:         room.Votes(),
+			Deck:          room.Deck(),
+			DeckName:      room.DeckName(),
+			Revealed:      room.IsRevealed(),
+			Round:         room.RoundIndex(),
+			Label:         room.Label(),
+			History:       room.History(),
+			Stories:       room.Stories(),
+			Current:       room.CurrentStoryIndex(),
+			HasPasscode:   room.Passcode() != "",
+			Locked:        room.IsLocked(),
+			CreatorJoined: room.CreatorJoined(),
+		}
+		if snap.Revealed {
+			snap.Stats = room.Stats()
+		}
+		return nil
+	})
+	return snap, err
+}
+
+// ParticipantByName finds a participant by display name (case-insensitive).
+func (r RoomSnapshot) ParticipantByName(name string) (domain.Participant, bool) {
+	name = strings.TrimSpace(name)
+	for _, p := range r.Participants {
+		if strings.EqualFold(p.Name, name) {
+			return p, true
+		}
+	}
+	return domain.Participant{}, false
+}
+
+// HasParticipant reports whether the participant is a member of the snapshot's room.
+func (r RoomSnapshot) HasParticipant(id domain.ParticipantID) bool {
+	for _, p := range r.Participants {
+		if p.ID == id {
+			return true
+		}
+	}
+	return false
+}
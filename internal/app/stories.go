@@ -0,0 +1,65 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jaminalder/estimations/internal/domain"
+)
+
+// AddStory appends a story to the room's backlog and broadcasts
+// StoriesChanged. Facilitator only.
+func (s *Service) AddStory(ctx context.Context, roomID domain.RoomID, by domain.ParticipantID, story domain.Story) error {
+	return s.withRoom(ctx, &command{name: "add_story", room: roomID, by: by}, func(room *domain.Room) error {
+		if err := room.AddStory(by, story); err != nil {
+			return fmt.Errorf("add story: %w", err)
+		}
+		s.emit(roomID, storiesChanged(room))
+		return nil
+	})
+}
+
+// RemoveStory deletes a pending story from the backlog and broadcasts
+// StoriesChanged. Facilitator only.
+func (s *Service) RemoveStory(ctx context.Context, roomID domain.RoomID, by domain.ParticipantID, index int) error {
+	return s.withRoom(ctx, &command{name: "remove_story", room: roomID, by: by}, func(room *domain.Room) error {
+		if err := room.RemoveStory(by, index); err != nil {
+			return fmt.Errorf("remove story: %w", err)
+		}
+		s.emit(roomID, storiesChanged(room))
+		return nil
+	})
+}
+
+// MoveStory reorders a pending story and broadcasts StoriesChanged.
+// Facilitator only.
+func (s *Service) MoveStory(ctx context.Context, roomID domain.RoomID, by domain.ParticipantID, from, to int) error {
+	return s.withRoom(ctx, &command{name: "move_story", room: roomID, by: by}, func(room *domain.Room) error {
+		if err := room.MoveStory(by, from, to); err != nil {
+			return fmt.Errorf("move story: %w", err)
+		}
+		s.emit(roomID, storiesChanged(room))
+		return nil
+	})
+}
+
+// NextStory records the estimate on the current story, advances the backlog
+// and starts a new round. Broadcasts StoryEstimated then RoundReset.
+// Facilitator only.
+func (s *Service) NextStory(ctx context.Context, roomID domain.RoomID, by domain.ParticipantID, estimate string) error {
+	return s.withRoom(ctx, &command{name: "next_story", room: roomID, by: by}, func(room *domain.Room) error {
+		index := room.CurrentStoryIndex()
+		now := s.now()
+		if err := room.NextStory(by, estimate, now); err != nil {
+			return fmt.Errorf("next story: %w", err)
+		}
+		done := room.Stories()[index]
+		s.emit(roomID, StoryEstimated{RoomID: roomID, Index: index, Title: done.Title, Estimate: done.Estimate, At: now})
+		s.emit(roomID, RoundReset{RoomID: roomID, Round: room.RoundIndex(), At: now})
+		return nil
+	})
+}
+
+func storiesChanged(room *domain.Room) StoriesChanged {
+	return StoriesChanged{RoomID: room.ID(), Stories: room.Stories(), Current: room.CurrentStoryIndex()}
+}
@@ -0,0 +1,84 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jaminalder/estimations/internal/domain"
+)
+
+func TestStories_AddMoveRemove_Broadcast(t *testing.T) {
+	ctx := context.Background()
+	repo := &resetRepo{}
+	roomID := domain.RoomID("r1")
+	room := domain.NewRoom(roomID)
+	_ = repo.Create(ctx, room)
+	host, guest := domain.ParticipantID("p1"), domain.ParticipantID("p2")
+	_ = room.Join(host, "Alice")
+	_ = room.Join(guest, "Bob")
+
+	bus := &resetBus{}
+	svc := &Service{Rooms: repo, Bus: bus}
+	if err := svc.AddStory(ctx, roomID, guest, domain.Story{Title: "A"}); !errors.Is(err, domain.ErrNotFacilitator) {
+		t.Fatalf("expected ErrNotFacilitator, got %v", err)
+	}
+	for _, title := range []string{"A", "B"} {
+		if err := svc.AddStory(ctx, roomID, host, domain.Story{Title: title}); err != nil {
+			t.Fatalf("add: %v", err)
+		}
+	}
+	if err := svc.MoveStory(ctx, roomID, host, 1, 0); err != nil {
+		t.Fatalf("move: %v", err)
+	}
+	if err := svc.RemoveStory(ctx, roomID, host, 1); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if len(bus.events) != 4 {
+		t.Fatalf("expected 4 events, got %d", len(bus.events))
+	}
+	last, ok := bus.events[3].(StoriesChanged)
+	if !ok || last.RoomID != roomID || len(last.Stories) != 1 || last.Stories[0].Title != "B" || last.Current != 0 {
+		t.Fatalf("unexpected event: %#v", bus.events[3])
+	}
+}
+
+func TestNextStory_RecordsEstimate_Broadcasts(t *testing.T) {
+	ctx := context.Background()
+	repo := &resetRepo{}
+	roomID := domain.RoomID("r1")
+	room := domain.NewRoom(roomID)
+	_ = repo.Create(ctx, room)
+	host := domain.ParticipantID("p1")
+	_ = room.Join(host, "Alice")
+
+	bus := &resetBus{}
+	svc := &Service{Rooms: repo, Bus: bus}
+	_ = svc.AddStory(ctx, roomID, host, domain.Story{Title: "Login"})
+	_ = svc.AddStory(ctx, roomID, host, domain.Story{Title: "Logout"})
+	_ = svc.Cast(ctx, roomID, host, "5")
+	_ = svc.Reveal(ctx, roomID, host)
+	bus.events = nil
+
+	if err := svc.NextStory(ctx, roomID, host, "5"); err != nil {
+		t.Fatalf("next: %v", err)
+	}
+	if len(bus.events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(bus.events))
+	}
+	est, ok := bus.events[0].(StoryEstimated)
+	if !ok || est.Index != 0 || est.Title != "Login" || est.Estimate != "5" {
+		t.Fatalf("unexpected first event: %#v", bus.events[0])
+	}
+	if rr, ok := bus.events[1].(RoundReset); !ok || rr.Round != 1 {
+		t.Fatalf("unexpected second event: %#v", bus.events[1])
+	}
+
+	snap, _ := svc.Snapshot(ctx, roomID)
+	if snap.Current != 1 || snap.Label != "Logout" || snap.Stories[0].Estimate != "5" {
+		t.Fatalf("unexpected snapshot: current=%d label=%q stories=%+v", snap.Current, snap.Label, snap.Stories)
+	}
+	if len(snap.History) != 1 || snap.History[0].Estimate != "5" {
+		t.Fatalf("expected archived round with estimate, got %+v", snap.History)
+	}
+}
@@ -0,0 +1,286 @@
+// Package config loads the server's settings from, in increasing order of
+// precedence, built-in defaults, an optional JSON file, environment
+// variables and command-line flags.
+//
+// Every setting has one name used everywhere: the flag (-empty-ttl), the key
+// in the file ("empty-ttl") and, upper-cased with an ESTIMATIONS_ prefix, the
+// environment variable (ESTIMATIONS_EMPTY_TTL). Values use the flag syntax,
+// e.g. "10m" for durations.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jaminalder/estimations/internal/domain"
+)
+
+// EnvPrefix prefixes the environment variable of every setting.
+const EnvPrefix = "ESTIMATIONS_"
+
+// Storage backends.
+const (
+	StorageMemory = "memory" // rooms are lost on restart
+	StorageSQLite = "sqlite" // rooms in a SQLite database file
+	StorageEvents = "events" // per-room event logs in a directory, replayed on start
+)
+
+// Room ID generators.
+const (
+	RoomIDsRandom = "random" // opaque URL-safe IDs of room-id-bytes random bytes
+	RoomIDsCode   = "code"   // short codes to read aloud, e.g. 7KQM2X
+)
+
+// LogLevels lists the accepted log levels, most verbose first.
+var LogLevels = []string{"debug", "info", "warn", "error"}
+
+// LogFormats lists the accepted log formats.
+var LogFormats = []string{"text", "json"}
+
+// Config holds the server settings.
+type Config struct {
+	Listen          string        // address to listen on, e.g. ":8080"
+	MetricsListen   string        // address serving /metrics, loopback by default; empty disables
+	BaseURL         string        // public URL of the server, for absolute recovery links and API Locations; optional
+	AdminToken      string        // bearer token of the admin API; empty disables it
+	SessionSecrets  string        // comma-separated keys signing participant cookies, newest first
+	RoomIDs         string        // RoomIDsRandom or RoomIDsCode
+	RoomIDBytes     int           // random bytes per room ID
+	PartIDBytes     int           // random bytes per participant ID
+	HubBuffer       int           // per-subscriber event buffer
+	HubHistory      int           // events kept per room for reconnecting clients
+	MaxParticipants int           // voters per room
+	MaxObservers    int           // observers per room
+	EmptyTTL        time.Duration // remove rooms without participants after this long; 0 disables
+	IdleTTL         time.Duration // remove rooms without activity after this long; 0 disables
+	PresenceGrace   time.Duration // remove participants disconnected this long; 0 disables
+	ShutdownTimeout time.Duration // time allowed for in-flight requests on shutdown
+	LogLevel        string        // one of LogLevels
+	LogFormat       string        // one of LogFormats
+	Storage         string        // StorageMemory, StorageSQLite or StorageEvents
+	StoragePath     string        // database file or event log directory
+}
+
+// Default returns the built-in settings.
+func Default() Config {
+	return Config{
+		Listen:          ":8080",
+		MetricsListen:   "127.0.0.1:9090",
+		RoomIDs:         RoomIDsRandom,
+		RoomIDBytes:     10,
+		PartIDBytes:     8,
+		HubBuffer:       16,
+		HubHistory:      64,
+		MaxParticipants: domain.MaxParticipants,
+		MaxObservers:    domain.MaxObservers,
+		EmptyTTL:        10 * time.Minute,
+		IdleTTL:         12 * time.Hour,
+		PresenceGrace:   time.Minute,
+		ShutdownTimeout: 5 * time.Second,
+		LogLevel:        "info",
+		LogFormat:       "text",
+		Storage:         StorageMemory,
+	}
+}
+
+// Load builds the configuration for a command line (without the program
+// name), reading environment variables through getenv. It reports whether
+// -print-config was given. The returned configuration is validated.
+func Load(args []string, getenv func(string) string) (Config, bool, error) {
+	// A first pass finds the config file, whose values the flags override.
+	var scratch Config
+	pre, file, _ := flagSet(&scratch, io.Discard)
+	if err := pre.Parse(args); err != nil {
+		return Config{}, false, err
+	}
+	if *file == "" {
+		*file = getenv(EnvPrefix + "CONFIG")
+	}
+
+	cfg := Default()
+	fs, _, printCfg := flagSet(&cfg, io.Discard)
+	if *file != "" {
+		if err := loadFile(fs, *file); err != nil {
+			return Config{}, false, err
+		}
+	}
+	var errs []error
+	fs.VisitAll(func(f *flag.Flag) {
+		if f.Name == "config" || f.Name == "print-config" {
+			return
+		}
+		env := EnvName(f.Name)
+		if v := getenv(env); v != "" {
+			if err := fs.Set(f.Name, v); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", env, err))
+			}
+		}
+	})
+	if err := errors.Join(errs...); err != nil {
+		return Config{}, false, err
+	}
+	if err := fs.Parse(args); err != nil {
+		return Config{}, false, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return Config{}, false, err
+	}
+	return cfg, *printCfg, nil
+}
+
+// Usage writes the flag help, with defaults, to w.
+func Usage(w io.Writer) {
+	cfg := Default()
+	fs, _, _ := flagSet(&cfg, w)
+	fmt.Fprintf(w, "Usage of %s:\n", fs.Name())
+	fs.PrintDefaults()
+	fmt.Fprintf(w, "\nEvery setting can also be given as an environment variable (e.g. %s) or in the -config file.\n", EnvName("listen"))
+}
+
+// EnvName returns the environment variable for a setting.
+func EnvName(setting string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(setting, "-", "_"))
+}
+
+// Validate reports every invalid setting.
+func (c Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+	check(c.Listen != "", "listen: must not be empty")
+	check(c.MetricsListen == "" || c.MetricsListen != c.Listen, "metrics-listen: must differ from listen")
+	check(c.AdminToken == "" || len(c.AdminToken) >= minSecret, "admin-token: must be at least %d characters", minSecret)
+	for i, key := range c.SessionKeys() {
+		check(len(key) >= minSecret, "session-secrets: secret %d must be at least %d characters", i+1, minSecret)
+	}
+	if c.BaseURL != "" {
+		u, err := url.Parse(c.BaseURL)
+		check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+			"base-url: %q is not an absolute http(s) URL", c.BaseURL)
+	}
+	check(c.RoomIDs == RoomIDsRandom || c.RoomIDs == RoomIDsCode, "room-ids: %q is not one of %s, %s", c.RoomIDs, RoomIDsRandom, RoomIDsCode)
+	check(c.RoomIDBytes >= 6 && c.RoomIDBytes <= 64, "room-id-bytes: %d is outside 6..64", c.RoomIDBytes)
+	check(c.PartIDBytes >= 8 && c.PartIDBytes <= 64, "participant-id-bytes: %d is outside 8..64", c.PartIDBytes)
+	check(c.HubBuffer >= 1, "hub-buffer: must be at least 1")
+	check(c.HubHistory >= 0, "hub-history: must not be negative")
+	check(c.MaxParticipants >= 1 && c.MaxParticipants <= domain.MaxParticipants,
+		"max-participants: %d is outside 1..%d", c.MaxParticipants, domain.MaxParticipants)
+	check(c.MaxObservers >= 0 && c.MaxObservers <= domain.MaxObservers,
+		"max-observers: %d is outside 0..%d", c.MaxObservers, domain.MaxObservers)
+	check(c.EmptyTTL >= 0, "empty-ttl: must not be negative")
+	check(c.IdleTTL >= 0, "idle-ttl: must not be negative")
+	check(c.PresenceGrace >= 0, "presence-grace: must not be negative")
+	check(c.ShutdownTimeout > 0, "shutdown-timeout: must be positive")
+	check(slices.Contains(LogLevels, c.LogLevel), "log-level: %q is not one of %s", c.LogLevel, strings.Join(LogLevels, ", "))
+	check(slices.Contains(LogFormats, c.LogFormat), "log-format: %q is not one of %s", c.LogFormat, strings.Join(LogFormats, ", "))
+	switch c.Storage {
+	case StorageMemory:
+		check(c.StoragePath == "", "storage-path: not used with storage %q", c.Storage)
+	case StorageSQLite, StorageEvents:
+		check(c.StoragePath != "", "storage-path: required with storage %q", c.Storage)
+	default:
+		check(false, "storage: %q is not one of %s, %s, %s", c.Storage, StorageMemory, StorageSQLite, StorageEvents)
+	}
+	return errors.Join(errs...)
+}
+
+// minSecret is the minimum length of secret settings.
+const minSecret = 16
+
+// secrets lists the settings WriteJSON redacts.
+var secrets = []string{"admin-token", "session-secrets"}
+
+// SessionKeys returns the session secrets as keys, newest first; none if
+// SessionSecrets is empty.
+func (c Config) SessionKeys() [][]byte {
+	var keys [][]byte
+	for _, s := range strings.Split(c.SessionSecrets, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			keys = append(keys, []byte(s))
+		}
+	}
+	return keys
+}
+
+// WriteJSON writes the settings in the config file format. Secrets that are
+// set are written as "REDACTED".
+func (c Config) WriteJSON(w io.Writer) error {
+	fs, _, _ := flagSet(&c, io.Discard)
+	values := make(map[string]string)
+	fs.VisitAll(func(f *flag.Flag) {
+		switch {
+		case f.Name == "config" || f.Name == "print-config":
+		case slices.Contains(secrets, f.Name) && f.Value.String() != "":
+			values[f.Name] = "REDACTED"
+		default:
+			values[f.Name] = f.Value.String()
+		}
+	})
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(values)
+}
+
+// flagSet binds a flag per setting to cfg, plus -config and -print-config.
+func flagSet(cfg *Config, out io.Writer) (fs *flag.FlagSet, file *string, printCfg *bool) {
+	fs = flag.NewFlagSet("server", flag.ContinueOnError)
+	fs.SetOutput(out)
+	file = fs.String("config", "", "JSON file with settings, keyed by flag name")
+	printCfg = fs.Bool("print-config", false, "print the effective settings as JSON and exit")
+	fs.StringVar(&cfg.Listen, "listen", cfg.Listen, "address to listen on")
+	fs.StringVar(&cfg.MetricsListen, "metrics-listen", cfg.MetricsListen, "address serving Prometheus metrics at /metrics; room IDs in it grant access to rooms, so keep it private (loopback by default, empty disables)")
+	fs.StringVar(&cfg.BaseURL, "base-url", cfg.BaseURL, "public URL of the server for absolute recovery links and API Location headers, e.g. https://poker.example.com; empty keeps them relative")
+	fs.StringVar(&cfg.AdminToken, "admin-token", cfg.AdminToken, fmt.Sprintf("bearer token enabling the admin API under /api/v1/admin, at least %d characters (empty disables)", minSecret))
+	fs.StringVar(&cfg.SessionSecrets, "session-secrets", cfg.SessionSecrets, fmt.Sprintf("comma-separated secrets signing participant cookies, each at least %d characters; the first signs, the others still verify, for rotation (empty: a random secret, sessions end on restart)", minSecret))
+	fs.StringVar(&cfg.RoomIDs, "room-ids", cfg.RoomIDs, "room IDs: random (long, unguessable) or code (six characters to read aloud; guessable, so private rooms need a passcode)")
+	fs.IntVar(&cfg.RoomIDBytes, "room-id-bytes", cfg.RoomIDBytes, "random bytes per room ID with -room-ids random")
+	fs.IntVar(&cfg.PartIDBytes, "participant-id-bytes", cfg.PartIDBytes, "random bytes per participant ID")
+	fs.IntVar(&cfg.HubBuffer, "hub-buffer", cfg.HubBuffer, "events buffered per live-update subscriber before it is disconnected")
+	fs.IntVar(&cfg.HubHistory, "hub-history", cfg.HubHistory, "events kept per room for reconnecting clients")
+	fs.IntVar(&cfg.MaxParticipants, "max-participants", cfg.MaxParticipants, "voters per room")
+	fs.IntVar(&cfg.MaxObservers, "max-observers", cfg.MaxObservers, "observers per room")
+	fs.DurationVar(&cfg.EmptyTTL, "empty-ttl", cfg.EmptyTTL, "remove rooms without participants after this long (0 disables)")
+	fs.DurationVar(&cfg.IdleTTL, "idle-ttl", cfg.IdleTTL, "remove rooms without any activity after this long (0 disables)")
+	fs.DurationVar(&cfg.PresenceGrace, "presence-grace", cfg.PresenceGrace, "remove participants whose live updates have been disconnected this long (0 disables)")
+	fs.DurationVar(&cfg.ShutdownTimeout, "shutdown-timeout", cfg.ShutdownTimeout, "time allowed for in-flight requests on shutdown")
+	fs.StringVar(&cfg.LogLevel, "log-level", cfg.LogLevel, "log level: "+strings.Join(LogLevels, ", "))
+	fs.StringVar(&cfg.LogFormat, "log-format", cfg.LogFormat, "log format: "+strings.Join(LogFormats, ", "))
+	fs.StringVar(&cfg.Storage, "storage", cfg.Storage, "room storage: memory (lost on restart), sqlite (database file) or events (event log directory, replayed on start)")
+	fs.StringVar(&cfg.StoragePath, "storage-path", cfg.StoragePath, "database file or event log directory for -storage")
+	return fs, file, printCfg
+}
+
+// loadFile applies the settings of a JSON config file to fs.
+func loadFile(fs *flag.FlagSet, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config file: %w", err)
+	}
+	var values map[string]any
+	if err := json.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(values)) {
+		if name == "config" || name == "print-config" || fs.Lookup(name) == nil {
+			errs = append(errs, fmt.Errorf("config file %s: unknown setting %q", path, name))
+			continue
+		}
+		if err := fs.Set(name, fmt.Sprint(values[name])); err != nil {
+			errs = append(errs, fmt.Errorf("config file %s: %s: %w", path, name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
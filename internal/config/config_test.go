@@ -0,0 +1,113 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func env(vars map[string]string) func(string) string {
+	return func(k string) string { return vars[k] }
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	cfg, printConfig, err := Load(nil, env(nil))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if printConfig || cfg != Default() {
+		t.Fatalf("expected defaults, got %+v (print %v)", cfg, printConfig)
+	}
+	if host, _, _ := strings.Cut(cfg.MetricsListen, ":"); host != "127.0.0.1" {
+		t.Fatalf("metrics must default to loopback, got %q", cfg.MetricsListen)
+	}
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.json")
+	data := `{"listen": ":7000", "empty-ttl": "1m", "hub-buffer": 32, "log-level": "debug"}`
+	if err := os.WriteFile(file, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	vars := map[string]string{
+		"ESTIMATIONS_CONFIG":     file,
+		"ESTIMATIONS_EMPTY_TTL":  "2m",
+		"ESTIMATIONS_HUB_BUFFER": "64",
+		"ESTIMATIONS_ROOM_IDS":   "code",
+	}
+	cfg, _, err := Load([]string{"-hub-buffer", "128"}, env(vars))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	// Flags beat the environment, which beats the file, which beats defaults.
+	if cfg.HubBuffer != 128 || cfg.EmptyTTL != 2*time.Minute || cfg.Listen != ":7000" || cfg.LogLevel != "debug" || cfg.IdleTTL != 12*time.Hour || cfg.RoomIDs != RoomIDsCode {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+}
+
+func TestLoad_Invalid_ReportsEverySetting(t *testing.T) {
+	args := []string{"-storage", "sqlite", "-max-participants", "0", "-log-level", "loud", "-log-format", "xml", "-admin-token", "short", "-session-secrets", "0123456789abcdef, short", "-base-url", "example.com", "-room-ids", "words"}
+	_, _, err := Load(args, env(map[string]string{"ESTIMATIONS_HUB_BUFFER": "0"}))
+	if err == nil {
+		t.Fatal("expected validation errors")
+	}
+	for _, want := range []string{"storage-path", "max-participants", "log-level", "log-format", "admin-token", "session-secrets: secret 2", "base-url", "hub-buffer", "room-ids"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error does not mention %s: %v", want, err)
+		}
+	}
+
+	if _, _, err := Load(nil, env(map[string]string{"ESTIMATIONS_IDLE_TTL": "soon"})); err == nil || !strings.Contains(err.Error(), "ESTIMATIONS_IDLE_TTL") {
+		t.Fatalf("expected bad env var to be named, got %v", err)
+	}
+	file := filepath.Join(t.TempDir(), "config.json")
+	_ = os.WriteFile(file, []byte(`{"colour": "red"}`), 0o600)
+	if _, _, err := Load([]string{"-config", file}, env(nil)); err == nil || !strings.Contains(err.Error(), `unknown setting "colour"`) {
+		t.Fatalf("expected unknown setting error, got %v", err)
+	}
+}
+
+func TestWriteJSON_RoundTrips(t *testing.T) {
+	cfg := Default()
+	cfg.Storage, cfg.StoragePath, cfg.IdleTTL = StorageEvents, "/var/lib/estimations", 90*time.Minute
+	var buf bytes.Buffer
+	if err := cfg.WriteJSON(&buf); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	file := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(file, buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	got, printConfig, err := Load([]string{"-config", file, "-print-config"}, env(nil))
+	if err != nil {
+		t.Fatalf("load printed config: %v\n%s", err, buf.String())
+	}
+	if got != cfg || !printConfig {
+		t.Fatalf("round trip: got %+v want %+v", got, cfg)
+	}
+}
+
+func TestWriteJSON_RedactsSecrets(t *testing.T) {
+	cfg := Default()
+	cfg.AdminToken = "correct-horse-battery-staple"
+	cfg.SessionSecrets = "new-secret-0123456789, old-secret-0123456789"
+	var buf bytes.Buffer
+	if err := cfg.WriteJSON(&buf); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	out := buf.String()
+	for _, secret := range []string{cfg.AdminToken, "new-secret", "old-secret"} {
+		if strings.Contains(out, secret) {
+			t.Fatalf("%s not redacted:\n%s", secret, out)
+		}
+	}
+	if !strings.Contains(out, `"admin-token": "REDACTED"`) || !strings.Contains(out, `"session-secrets": "REDACTED"`) {
+		t.Fatalf("secrets not marked redacted:\n%s", out)
+	}
+	if keys := cfg.SessionKeys(); len(keys) != 2 || string(keys[0]) != "new-secret-0123456789" {
+		t.Fatalf("session keys: %q", keys)
+	}
+}
@@ -0,0 +1,27 @@
+package domain
+
+// A room can be closed to newcomers in two ways: a passcode they must know
+// and a lock that turns them away altogether. Neither affects participants
+// already in the room.
+
+// Passcode returns the hash of the room's passcode, or "" without one. The
+// room keeps the hash opaque: hashing and checking are up to the caller,
+// and the room does not check passcodes on Join.
+func (r *Room) Passcode() string { return r.passcode }
+
+// SetPasscode sets the hash of the passcode newcomers must know ("" for none).
+func (r *Room) SetPasscode(hash string) { r.passcode = hash }
+
+// IsLocked reports whether the room turns away new participants.
+func (r *Room) IsLocked() bool { return r.locked }
+
+// SetLocked locks or unlocks the room. Only the facilitator may do so. A
+// room unlocks by itself once everyone has left, since nobody could unlock
+// it any more.
+func (r *Room) SetLocked(by ParticipantID, locked bool) error {
+	if err := r.requireFacilitator(by); err != nil {
+		return err
+	}
+	r.locked = locked
+	return nil
+}
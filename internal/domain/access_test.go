@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRoom_LockKeepsNewcomersOut(t *testing.T) {
+	r := NewRoom(RoomID("r1"))
+	_ = r.Join(ParticipantID("p1"), "Alice")
+	_ = r.Join(ParticipantID("p2"), "Bob")
+
+	if err := r.SetLocked(ParticipantID("p2"), true); !errors.Is(err, ErrNotFacilitator) {
+		t.Fatalf("lock by non-facilitator: got %v want ErrNotFacilitator", err)
+	}
+	if err := r.SetLocked(ParticipantID("p1"), true); err != nil || !r.IsLocked() {
+		t.Fatalf("lock: %v", err)
+	}
+	if err := r.JoinAs(ParticipantID("p3"), "Olga", Observer); !errors.Is(err, ErrRoomLocked) {
+		t.Fatalf("join locked room: got %v want ErrRoomLocked", err)
+	}
+	// Participants inside carry on.
+	if err := r.CastVote(ParticipantID("p2"), "5"); err != nil {
+		t.Fatalf("vote in locked room: %v", err)
+	}
+	if got, err := RestoreRoom(r.State()); err != nil || !got.IsLocked() {
+		t.Fatalf("restored room should stay locked: %v", err)
+	}
+
+	if err := r.SetLocked(ParticipantID("p1"), false); err != nil {
+		t.Fatalf("unlock: %v", err)
+	}
+	if err := r.Join(ParticipantID("p3"), "Olga"); err != nil {
+		t.Fatalf("join unlocked room: %v", err)
+	}
+}
+
+func TestRoom_EmptyRoomUnlocks(t *testing.T) {
+	r := NewRoom(RoomID("r1"))
+	_ = r.Join(ParticipantID("p1"), "Alice")
+	_ = r.SetLocked(ParticipantID("p1"), true)
+	_ = r.Leave(ParticipantID("p1"))
+	if r.IsLocked() {
+		t.Fatalf("an empty room has nobody to unlock it and should unlock")
+	}
+}
@@ -3,6 +3,7 @@ package domain
 import (
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestRoom_Accessors_SnapshotsAndDeck(t *testing.T) {
@@ -39,7 +40,7 @@ func TestRoom_Accessors_SnapshotsAndDeck(t *testing.T) {
 	votesCopy := r.Votes()
 	// Mutate the returned map; should not affect internal state
 	delete(votesCopy, ParticipantID("p1"))
-	if err := r.Reveal(); err != nil {
+	if err := r.Reveal(ParticipantID("p1"), time.Now()); err != nil {
 		t.Fatalf("reveal should still succeed (internal vote intact): %v", err)
 	}
 
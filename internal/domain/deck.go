@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Deck size and card limits for custom decks.
+const (
+	MinDeckSize = 2
+	MaxDeckSize = 24
+	MaxCardLen  = 8 // runes; keeps cards readable on the UI
+)
+
+// Deck names for the built-in presets and custom decks.
+const (
+	DeckFibonacci         = "fibonacci"
+	DeckModifiedFibonacci = "modified-fibonacci"
+	DeckTShirt            = "tshirt"
+	DeckPowersOfTwo       = "powers-of-two"
+	DeckCustom            = "custom"
+)
+
+// Deck is the immutable, ordered set of cards a room votes with.
+// The zero value is not a valid deck; use a preset or NewCustomDeck.
+type Deck struct {
+	name  string
+	cards []string
+}
+
+// presets lists the built-in decks in display order.
+var presets = []Deck{
+	{name: DeckFibonacci, cards: []string{"0", "1", "2", "3", "5", "8", "13", "21", "34", "?", "∞", "☕", "Pass"}},
+	{name: DeckModifiedFibonacci, cards: []string{"0", "½", "1", "2", "3", "5", "8", "13", "20", "40", "100", "?", "∞", "☕", "Pass"}},
+	{name: DeckTShirt, cards: []string{"XS", "S", "M", "L", "XL", "XXL", "?", "☕", "Pass"}},
+	{name: DeckPowersOfTwo, cards: []string{"0", "1", "2", "4", "8", "16", "32", "64", "?", "∞", "☕", "Pass"}},
+}
+
+// DefaultDeck returns the Fibonacci deck used when none is chosen.
+func DefaultDeck() Deck { return presets[0] }
+
+// PresetDecks returns the built-in decks in display order.
+func PresetDecks() []Deck {
+	out := make([]Deck, len(presets))
+	copy(out, presets)
+	return out
+}
+
+// PresetDeck looks up a built-in deck by name.
+func PresetDeck(name string) (Deck, bool) {
+	for _, d := range presets {
+		if d.name == name {
+			return d, true
+		}
+	}
+	return Deck{}, false
+}
+
+// NewCustomDeck builds a deck from the given cards (display order preserved).
+// Cards are trimmed and must be non-empty, at most MaxCardLen runes and
+// unique (case-insensitive); the deck must hold MinDeckSize..MaxDeckSize cards.
+func NewCustomDeck(cards []string) (Deck, error) {
+	if len(cards) < MinDeckSize || len(cards) > MaxDeckSize {
+		return Deck{}, fmt.Errorf("%w: need %d to %d cards, got %d", ErrInvalidDeck, MinDeckSize, MaxDeckSize, len(cards))
+	}
+	out := make([]string, 0, len(cards))
+	seen := make(map[string]struct{}, len(cards))
+	for _, c := range cards {
+		c = strings.TrimSpace(c)
+		if c == "" {
+			return Deck{}, fmt.Errorf("%w: empty card", ErrInvalidDeck)
+		}
+		if utf8.RuneCountInString(c) > MaxCardLen {
+			return Deck{}, fmt.Errorf("%w: card %q longer than %d characters", ErrInvalidDeck, c, MaxCardLen)
+		}
+		key := strings.ToLower(c)
+		if _, dup := seen[key]; dup {
+			return Deck{}, fmt.Errorf("%w: duplicate card %q", ErrInvalidDeck, c)
+		}
+		seen[key] = struct{}{}
+		out = append(out, c)
+	}
+	return Deck{name: DeckCustom, cards: out}, nil
+}
+
+// Name returns the preset name, or DeckCustom for custom decks.
+func (d Deck) Name() string { return d.name }
+
+// Cards returns a copy of the cards in display order.
+func (d Deck) Cards() []string {
+	out := make([]string, len(d.cards))
+	copy(out, d.cards)
+	return out
+}
+
+// Contains reports whether the card is part of the deck.
+func (d Deck) Contains(card string) bool {
+	for _, c := range d.cards {
+		if c == card {
+			return true
+		}
+	}
+	return false
+}
+
+// IsZero reports whether the deck is the (invalid) zero value.
+func (d Deck) IsZero() bool { return len(d.cards) == 0 }
@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDeck_Presets(t *testing.T) {
+	names := []string{DeckFibonacci, DeckModifiedFibonacci, DeckTShirt, DeckPowersOfTwo}
+	got := PresetDecks()
+	if len(got) != len(names) {
+		t.Fatalf("expected %d presets, got %d", len(names), len(got))
+	}
+	for i, d := range got {
+		if d.Name() != names[i] {
+			t.Fatalf("preset %d: got %q want %q", i, d.Name(), names[i])
+		}
+		if _, ok := PresetDeck(d.Name()); !ok {
+			t.Fatalf("preset %q not found by name", d.Name())
+		}
+	}
+	if DefaultDeck().Name() != DeckFibonacci {
+		t.Fatalf("default deck should be fibonacci, got %q", DefaultDeck().Name())
+	}
+	if _, ok := PresetDeck("nope"); ok {
+		t.Fatalf("unknown preset should not be found")
+	}
+
+	mod, _ := PresetDeck(DeckModifiedFibonacci)
+	want := []string{"0", "½", "1", "2", "3", "5", "8", "13", "20", "40", "100", "?", "∞", "☕", "Pass"}
+	if !reflect.DeepEqual(mod.Cards(), want) {
+		t.Fatalf("modified fibonacci mismatch:\nwant %v\n got %v", want, mod.Cards())
+	}
+}
+
+func TestDeck_Cards_ReturnsCopy(t *testing.T) {
+	d := DefaultDeck()
+	cards := d.Cards()
+	cards[0] = "changed"
+	if d.Cards()[0] != "0" {
+		t.Fatalf("mutating Cards() result must not affect the deck")
+	}
+}
+
+func TestNewCustomDeck_Validation(t *testing.T) {
+	d, err := NewCustomDeck([]string{" 1 ", "2", "Big"})
+	if err != nil {
+		t.Fatalf("valid custom deck: %v", err)
+	}
+	if d.Name() != DeckCustom || !reflect.DeepEqual(d.Cards(), []string{"1", "2", "Big"}) {
+		t.Fatalf("unexpected custom deck: %q %v", d.Name(), d.Cards())
+	}
+
+	cases := map[string][]string{
+		"too few":   {"1"},
+		"too many":  strings.Split(strings.Repeat("x,", MaxDeckSize)+"y", ","),
+		"empty":     {"1", " "},
+		"duplicate": {"S", "M", "s"},
+		"too long":  {"1", "123456789"},
+	}
+	for name, cards := range cases {
+		if _, err := NewCustomDeck(cards); err == nil {
+			t.Fatalf("%s: expected error for %v", name, cards)
+		}
+	}
+}
+
+func TestRoom_CastVote_UsesRoomDeck(t *testing.T) {
+	tshirt, _ := PresetDeck(DeckTShirt)
+	r := NewRoomWithDeck(RoomID("r1"), tshirt)
+	if r.DeckName() != DeckTShirt {
+		t.Fatalf("deck name: got %q", r.DeckName())
+	}
+	if err := r.Join(ParticipantID("p1"), "Alice"); err != nil {
+		t.Fatalf("join: %v", err)
+	}
+	if err := r.CastVote(ParticipantID("p1"), "13"); err == nil {
+		t.Fatalf("expected card outside the room's deck to be rejected")
+	}
+	if err := r.CastVote(ParticipantID("p1"), "XL"); err != nil {
+		t.Fatalf("cast from room deck: %v", err)
+	}
+
+	// Zero deck falls back to the default.
+	if got := NewRoomWithDeck(RoomID("r2"), Deck{}).DeckName(); got != DeckFibonacci {
+		t.Fatalf("zero deck should fall back to fibonacci, got %q", got)
+	}
+}
@@ -0,0 +1,44 @@
+package domain
+
+import "errors"
+
+// Errors returned by Room commands. Commands wrap them with detail (for
+// example the duplicate name), so callers match with errors.Is.
+var (
+	// ErrNotFacilitator is returned when a facilitator-only command (reveal,
+	// reset, room settings) is issued by anyone else.
+	ErrNotFacilitator = errors.New("not the facilitator")
+	// ErrNotParticipant is returned when the acting or target participant is
+	// not in the room.
+	ErrNotParticipant = errors.New("not a participant")
+	// ErrObserverCannotVote is returned when an observer casts a vote.
+	ErrObserverCannotVote = errors.New("observers cannot vote")
+
+	// ErrDuplicateName is returned when a name is already taken in the room
+	// (case-insensitive).
+	ErrDuplicateName = errors.New("duplicate name")
+	// ErrRoomFull is returned when the voter or observer capacity is reached.
+	ErrRoomFull = errors.New("capacity reached")
+	// ErrVotingClosed is returned for vote changes while votes are revealed.
+	ErrVotingClosed = errors.New("voting is closed")
+	// ErrNoVotes is returned when revealing a round nobody has voted in.
+	ErrNoVotes = errors.New("cannot reveal: no votes")
+	// ErrNoCurrentStory is returned by NextStory once the backlog is done.
+	ErrNoCurrentStory = errors.New("no current story")
+	// ErrRoomLocked is returned when joining a room the facilitator locked.
+	ErrRoomLocked = errors.New("room is locked")
+	// ErrTooManyStories is returned when the backlog already holds MaxStories.
+	ErrTooManyStories = errors.New("too many stories")
+	// ErrCreatorJoined is returned when the creator's claim is used a second
+	// time; it is good for one join only.
+	ErrCreatorJoined = errors.New("creator has already joined")
+
+	// Validation errors for command input.
+	ErrInvalidName     = errors.New("invalid name")
+	ErrInvalidCard     = errors.New("invalid card")
+	ErrInvalidLabel    = errors.New("invalid label")
+	ErrInvalidTitle    = errors.New("invalid title")
+	ErrInvalidStory    = errors.New("invalid story")
+	ErrInvalidEstimate = errors.New("invalid estimate")
+	ErrInvalidDeck     = errors.New("invalid deck")
+)
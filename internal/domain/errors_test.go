@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRoom_Errors_AreTyped(t *testing.T) {
+	r := NewRoom(RoomID("r1"))
+	host, guest, watcher := ParticipantID("p1"), ParticipantID("p2"), ParticipantID("p3")
+	_ = r.Join(host, "Alice")
+	_ = r.Join(guest, "Bob")
+	_ = r.JoinAs(watcher, "Olga", Observer)
+
+	check := func(name string, err, want error) {
+		t.Helper()
+		if !errors.Is(err, want) {
+			t.Fatalf("%s: got %v, want %v", name, err, want)
+		}
+	}
+	check("empty name", r.Join("x", "  "), ErrInvalidName)
+	check("duplicate name", r.Join("x", "alice"), ErrDuplicateName)
+	check("reveal without votes", r.Reveal(host, time.Time{}), ErrNoVotes)
+	check("unknown voter", r.CastVote("ghost", "5"), ErrNotParticipant)
+	check("observer vote", r.CastVote(watcher, "5"), ErrObserverCannotVote)
+	check("card outside deck", r.CastVote(guest, "7"), ErrInvalidCard)
+	check("long label", r.SetLabel(host, strings.Repeat("x", MaxLabelLen+1)), ErrInvalidLabel)
+	check("long title", r.SetTitle(strings.Repeat("x", MaxLabelLen+1)), ErrInvalidTitle)
+	check("bad story", r.AddStory(host, Story{}), ErrInvalidStory)
+	check("no story", r.NextStory(host, "", time.Time{}), ErrNoCurrentStory)
+	check("unknown leaver", r.Leave("ghost"), ErrNotParticipant)
+	check("transfer to stranger", r.TransferFacilitator(host, "ghost"), ErrNotParticipant)
+	check("guest reveal", r.Reveal(guest, time.Time{}), ErrNotFacilitator)
+
+	_ = r.CastVote(guest, "5")
+	_ = r.Reveal(host, time.Time{})
+	check("vote after reveal", r.CastVote(guest, "8"), ErrVotingClosed)
+	check("clear after reveal", r.ClearVote(guest), ErrVotingClosed)
+
+	_ = r.AddStory(host, Story{Title: "Login"})
+	check("estimate outside deck", r.NextStory(host, "7", time.Time{}), ErrInvalidEstimate)
+
+	_, err := NewCustomDeck([]string{"1"})
+	check("short deck", err, ErrInvalidDeck)
+
+	full := NewRoom(RoomID("r2"))
+	for i := 0; i < MaxParticipants; i++ {
+		_ = full.Join(ParticipantID(rune('a'+i)), string(rune('a'+i)))
+	}
+	check("room full", full.Join("z1", "Zed"), ErrRoomFull)
+}
+
+func TestRoom_Errors_KeepDetail(t *testing.T) {
+	r := NewRoom(RoomID("r1"))
+	_ = r.Join(ParticipantID("p1"), "Alice")
+	if err := r.Join(ParticipantID("p2"), "alice"); err == nil || err.Error() != `duplicate name: "alice"` {
+		t.Fatalf("unexpected message: %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package domain
+
+// Facilitator returns the current facilitator, or "" if the room is empty.
+func (r *Room) Facilitator() ParticipantID { return r.facilitator }
+
+// IsFacilitator reports whether id is the room's facilitator.
+func (r *Room) IsFacilitator(id ParticipantID) bool {
+	return id != "" && id == r.facilitator
+}
+
+// TransferFacilitator hands the facilitator role to another participant.
+// Only the current facilitator may transfer the role.
+func (r *Room) TransferFacilitator(by, to ParticipantID) error {
+	if err := r.requireFacilitator(by); err != nil {
+		return err
+	}
+	if _, ok := r.participants[to]; !ok {
+		return ErrNotParticipant
+	}
+	r.facilitator = to
+	return nil
+}
+
+// JoinAsCreator adds the participant who created the room, as JoinAs does
+// except that the creator gets in even when the room is locked and becomes
+// its facilitator, taking over from whoever joined first. Checking that id
+// is the creator is up to the caller. The claim is good once: after the
+// creator has joined, later calls fail with ErrCreatorJoined.
+func (r *Room) JoinAsCreator(id ParticipantID, name string, kind ParticipantKind) error {
+	if r.creatorJoined {
+		return ErrCreatorJoined
+	}
+	if err := r.join(id, name, kind); err != nil {
+		return err
+	}
+	r.facilitator = id
+	r.creatorJoined = true
+	return nil
+}
+
+// CreatorJoined reports whether the room's creator has used their claim.
+func (r *Room) CreatorJoined() bool { return r.creatorJoined }
+
+func (r *Room) requireFacilitator(by ParticipantID) error {
+	if !r.IsFacilitator(by) {
+		return ErrNotFacilitator
+	}
+	return nil
+}
+
+// Release removes another participant from the room, as if they had left,
+// freeing their name. Only the facilitator may release participants; it is
+// meant for people who lost their session and cannot leave themselves.
+func (r *Room) Release(by, id ParticipantID) error {
+	if err := r.requireFacilitator(by); err != nil {
+		return err
+	}
+	return r.Leave(id)
+}
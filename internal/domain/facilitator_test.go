@@ -0,0 +1,150 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRoom_FirstJoinerIsFacilitator(t *testing.T) {
+	r := NewRoom(RoomID("r1"))
+	if r.Facilitator() != "" {
+		t.Fatalf("empty room should have no facilitator")
+	}
+	_ = r.Join(ParticipantID("p1"), "Alice")
+	_ = r.Join(ParticipantID("p2"), "Bob")
+	if r.Facilitator() != ParticipantID("p1") || !r.IsFacilitator("p1") || r.IsFacilitator("p2") {
+		t.Fatalf("first joiner should be facilitator, got %q", r.Facilitator())
+	}
+}
+
+func TestRoom_CreatorTakesOverFacilitator(t *testing.T) {
+	r := NewRoom(RoomID("r1"))
+	_ = r.Join(ParticipantID("p1"), "Mallory")
+	_ = r.SetLocked(ParticipantID("p1"), true)
+	if err := r.JoinAsCreator(ParticipantID("p2"), "mallory", Voter); !errors.Is(err, ErrDuplicateName) || r.CreatorJoined() {
+		t.Fatalf("rejected creator join: got %v, claim used %v", err, r.CreatorJoined())
+	}
+	if err := r.JoinAsCreator(ParticipantID("p2"), "Alice", Voter); err != nil {
+		t.Fatalf("creator should get into a locked room: %v", err)
+	}
+	if !r.IsFacilitator("p2") || !r.CreatorJoined() {
+		t.Fatalf("creator should be facilitator, got %q", r.Facilitator())
+	}
+	if err := r.JoinAsCreator(ParticipantID("p3"), "Eve", Voter); !errors.Is(err, ErrCreatorJoined) || !r.IsFacilitator("p2") {
+		t.Fatalf("second creator join: got %v, facilitator %q", err, r.Facilitator())
+	}
+}
+
+func TestRoom_FacilitatorOnlyCommands(t *testing.T) {
+	r := NewRoom(RoomID("r1"))
+	_ = r.Join(ParticipantID("p1"), "Alice")
+	_ = r.Join(ParticipantID("p2"), "Bob")
+	_ = r.CastVote(ParticipantID("p2"), "5")
+
+	if err := r.Reveal(ParticipantID("p2"), time.Now()); !errors.Is(err, ErrNotFacilitator) {
+		t.Fatalf("reveal by non-facilitator: got %v want ErrNotFacilitator", err)
+	}
+	if r.IsRevealed() {
+		t.Fatalf("rejected reveal must not change state")
+	}
+	if err := r.SetLabel(ParticipantID("p2"), "Story"); !errors.Is(err, ErrNotFacilitator) {
+		t.Fatalf("label by non-facilitator: got %v", err)
+	}
+	if err := r.Reveal(ParticipantID("p1"), time.Now()); err != nil {
+		t.Fatalf("reveal by facilitator: %v", err)
+	}
+	if err := r.Reset(ParticipantID("p2"), time.Now()); !errors.Is(err, ErrNotFacilitator) {
+		t.Fatalf("reset by non-facilitator: got %v", err)
+	}
+	if err := r.Reset(ParticipantID("ghost"), time.Now()); !errors.Is(err, ErrNotFacilitator) {
+		t.Fatalf("reset by stranger: got %v", err)
+	}
+	if err := r.Reset(ParticipantID("p1"), time.Now()); err != nil {
+		t.Fatalf("reset by facilitator: %v", err)
+	}
+}
+
+func TestRoom_TransferFacilitator(t *testing.T) {
+	r := NewRoom(RoomID("r1"))
+	_ = r.Join(ParticipantID("p1"), "Alice")
+	_ = r.Join(ParticipantID("p2"), "Bob")
+
+	if err := r.TransferFacilitator(ParticipantID("p2"), ParticipantID("p2")); !errors.Is(err, ErrNotFacilitator) {
+		t.Fatalf("only the facilitator may transfer: got %v", err)
+	}
+	if err := r.TransferFacilitator(ParticipantID("p1"), ParticipantID("ghost")); err == nil {
+		t.Fatalf("transfer to non-participant should fail")
+	}
+	if err := r.TransferFacilitator(ParticipantID("p1"), ParticipantID("p2")); err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	if r.Facilitator() != ParticipantID("p2") {
+		t.Fatalf("facilitator should now be p2, got %q", r.Facilitator())
+	}
+}
+
+func TestRoom_FacilitatorLeaves_FallsBackToLongestPresent(t *testing.T) {
+	r := NewRoom(RoomID("r1"))
+	_ = r.Join(ParticipantID("p1"), "Alice")
+	_ = r.Join(ParticipantID("p2"), "Bob")
+	_ = r.Join(ParticipantID("p3"), "Carol")
+
+	// A non-facilitator leaving keeps the facilitator
+	_ = r.Leave(ParticipantID("p2"))
+	if r.Facilitator() != ParticipantID("p1") {
+		t.Fatalf("facilitator should be unchanged, got %q", r.Facilitator())
+	}
+	_ = r.Leave(ParticipantID("p1"))
+	if r.Facilitator() != ParticipantID("p3") {
+		t.Fatalf("expected fallback to p3, got %q", r.Facilitator())
+	}
+	_ = r.Leave(ParticipantID("p3"))
+	if r.Facilitator() != "" {
+		t.Fatalf("empty room should have no facilitator, got %q", r.Facilitator())
+	}
+	// Next joiner of the now-empty room takes over
+	_ = r.Join(ParticipantID("p4"), "Dave")
+	if r.Facilitator() != ParticipantID("p4") {
+		t.Fatalf("next joiner should become facilitator, got %q", r.Facilitator())
+	}
+}
+
+func TestRoom_Participants_JoinOrder(t *testing.T) {
+	r := NewRoom(RoomID("r1"))
+	names := []string{"Carol", "Alice", "Bob", "Dave"}
+	for i, n := range names {
+		_ = r.Join(ParticipantID(string(rune('a'+i))), n)
+	}
+	_ = r.Leave(ParticipantID("b"))
+	got := r.Participants()
+	want := []string{"Carol", "Bob", "Dave"}
+	for i := range want {
+		if got[i].Name != want[i] {
+			t.Fatalf("participants not in join order: %+v", got)
+		}
+	}
+}
+
+func TestRoom_ReleaseFreesName(t *testing.T) {
+	r := NewRoom(RoomID("r1"))
+	_ = r.Join(ParticipantID("p1"), "Alice")
+	_ = r.Join(ParticipantID("p2"), "Bob")
+	_ = r.CastVote(ParticipantID("p2"), "5")
+
+	if err := r.Release(ParticipantID("p2"), ParticipantID("p1")); !errors.Is(err, ErrNotFacilitator) {
+		t.Fatalf("release by non-facilitator: got %v want ErrNotFacilitator", err)
+	}
+	if err := r.Release(ParticipantID("p1"), ParticipantID("p9")); !errors.Is(err, ErrNotParticipant) {
+		t.Fatalf("release of stranger: got %v want ErrNotParticipant", err)
+	}
+	if err := r.Release(ParticipantID("p1"), ParticipantID("p2")); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if len(r.Participants()) != 1 || len(r.Votes()) != 0 {
+		t.Fatalf("released participant and vote should be gone")
+	}
+	if err := r.Join(ParticipantID("p3"), "bob"); err != nil {
+		t.Fatalf("released name should be free again: %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRoom_Reset_ArchivesRevealedRound(t *testing.T) {
+	r := NewRoom(RoomID("r1"))
+	if err := r.Join(ParticipantID("p1"), "bob"); err != nil {
+		t.Fatalf("join: %v", err)
+	}
+	if err := r.Join(ParticipantID("p2"), "Alice"); err != nil {
+		t.Fatalf("join: %v", err)
+	}
+	if err := r.SetLabel(ParticipantID("p1"), "  Login page  "); err != nil {
+		t.Fatalf("label: %v", err)
+	}
+	_ = r.CastVote(ParticipantID("p1"), "5")
+	_ = r.CastVote(ParticipantID("p2"), "8")
+
+	revealedAt := time.Date(2025, 9, 2, 10, 0, 0, 0, time.UTC)
+	resetAt := revealedAt.Add(3 * time.Minute)
+	if err := r.Reveal(ParticipantID("p1"), revealedAt); err != nil {
+		t.Fatalf("reveal: %v", err)
+	}
+	if err := r.Reset(ParticipantID("p1"), resetAt); err != nil {
+		t.Fatalf("reset: %v", err)
+	}
+
+	h := r.History()
+	if len(h) != 1 {
+		t.Fatalf("expected 1 archived round, got %d", len(h))
+	}
+	got := h[0]
+	if got.Index != 0 || got.Label != "Login page" || !got.RevealedAt.Equal(revealedAt) || !got.ResetAt.Equal(resetAt) {
+		t.Fatalf("unexpected round result: %+v", got)
+	}
+	if len(got.Votes) != 2 || got.Votes[0] != (NamedVote{Name: "Alice", Card: "8"}) || got.Votes[1] != (NamedVote{Name: "bob", Card: "5"}) {
+		t.Fatalf("votes should be archived by name, sorted: %+v", got.Votes)
+	}
+	if r.Label() != "" {
+		t.Fatalf("new round should start without a label, got %q", r.Label())
+	}
+
+	// History is a copy
+	h[0].Votes[0].Card = "13"
+	if r.History()[0].Votes[0].Card != "8" {
+		t.Fatalf("mutating History() result must not affect the room")
+	}
+}
+
+func TestRoom_Reset_UnrevealedRoundNotArchived(t *testing.T) {
+	r := NewRoom(RoomID("r1"))
+	_ = r.Join(ParticipantID("p1"), "Alice")
+	_ = r.CastVote(ParticipantID("p1"), "5")
+	if err := r.Reset(ParticipantID("p1"), time.Now()); err != nil {
+		t.Fatalf("reset: %v", err)
+	}
+	if len(r.History()) != 0 {
+		t.Fatalf("reset while voting should not archive a round")
+	}
+	if r.RoundIndex() != 1 {
+		t.Fatalf("round index should still advance")
+	}
+}
+
+func TestRoom_SetLabel_TooLong(t *testing.T) {
+	r := NewRoom(RoomID("r1"))
+	if err := r.SetLabel(ParticipantID("p1"), strings.Repeat("x", MaxLabelLen+1)); err == nil {
+		t.Fatalf("expected label length error")
+	}
+}
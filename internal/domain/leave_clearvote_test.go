@@ -1,6 +1,9 @@
 package domain
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestRoom_Leave_RemovesVoteAndMember(t *testing.T) {
 	r := NewRoom(RoomID("r1"))
@@ -24,7 +27,7 @@ func TestRoom_Leave_RemovesVoteAndMember(t *testing.T) {
 	}
 
 	// With p1 gone and p2 not voted, reveal should fail (no votes present)
-	if err := r.Reveal(); err == nil {
+	if err := r.Reveal(ParticipantID("p2"), time.Now()); err == nil {
 		t.Fatalf("expected reveal error due to no votes after leave")
 	}
 
@@ -32,7 +35,7 @@ func TestRoom_Leave_RemovesVoteAndMember(t *testing.T) {
 	if err := r.CastVote(ParticipantID("p2"), "5"); err != nil {
 		t.Fatalf("cast: %v", err)
 	}
-	if err := r.Reveal(); err != nil {
+	if err := r.Reveal(ParticipantID("p2"), time.Now()); err != nil {
 		t.Fatalf("reveal: %v", err)
 	}
 }
@@ -50,7 +53,7 @@ func TestRoom_ClearVote_Rules(t *testing.T) {
 	if err := r.ClearVote(ParticipantID("p1")); err != nil {
 		t.Fatalf("clear: %v", err)
 	}
-	if err := r.Reveal(); err == nil {
+	if err := r.Reveal(ParticipantID("p1"), time.Now()); err == nil {
 		t.Fatalf("expected reveal error after clearing all votes")
 	}
 
@@ -59,7 +62,7 @@ func TestRoom_ClearVote_Rules(t *testing.T) {
 	if err := r.CastVote(ParticipantID("p1"), "13"); err != nil {
 		t.Fatalf("cast: %v", err)
 	}
-	if err := r.Reveal(); err != nil {
+	if err := r.Reveal(ParticipantID("p1"), time.Now()); err != nil {
 		t.Fatalf("reveal: %v", err)
 	}
 	if err := r.ClearVote(ParticipantID("p1")); err == nil {
@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestRoom_Observer_CannotVote(t *testing.T) {
+	r := NewRoom(RoomID("r1"))
+	if err := r.Join(ParticipantID("p1"), "Alice"); err != nil {
+		t.Fatalf("join: %v", err)
+	}
+	if err := r.JoinAs(ParticipantID("o1"), "Olivia", Observer); err != nil {
+		t.Fatalf("join observer: %v", err)
+	}
+	if err := r.CastVote(ParticipantID("o1"), "5"); err == nil {
+		t.Fatalf("observer vote should be rejected")
+	}
+	// Observer does not block reveal of voter votes
+	if err := r.CastVote(ParticipantID("p1"), "5"); err != nil {
+		t.Fatalf("cast: %v", err)
+	}
+	if err := r.Reveal(ParticipantID("p1"), time.Now()); err != nil {
+		t.Fatalf("reveal: %v", err)
+	}
+
+	ps := r.Participants()
+	if len(ps) != 2 || ps[0].IsObserver() || !ps[1].IsObserver() {
+		t.Fatalf("unexpected participants: %+v", ps)
+	}
+}
+
+func TestRoom_Observer_SeparateCapacity(t *testing.T) {
+	r := NewRoom(RoomID("r1"))
+	for i := 0; i < MaxParticipants; i++ {
+		if err := r.Join(ParticipantID(fmt.Sprintf("v%d", i)), fmt.Sprintf("Voter %d", i)); err != nil {
+			t.Fatalf("join voter %d: %v", i, err)
+		}
+	}
+	if err := r.Join(ParticipantID("v-over"), "One too many"); err == nil {
+		t.Fatalf("expected voter capacity error")
+	}
+	// Full voter seats do not block observers
+	for i := 0; i < MaxObservers; i++ {
+		if err := r.JoinAs(ParticipantID(fmt.Sprintf("o%d", i)), fmt.Sprintf("Observer %d", i), Observer); err != nil {
+			t.Fatalf("join observer %d: %v", i, err)
+		}
+	}
+	if err := r.JoinAs(ParticipantID("o-over"), "Too curious", Observer); err == nil {
+		t.Fatalf("expected observer capacity error")
+	}
+}
+
+func TestRoom_Observer_NameUniqueAcrossKinds(t *testing.T) {
+	r := NewRoom(RoomID("r1"))
+	_ = r.Join(ParticipantID("p1"), "Alice")
+	if err := r.JoinAs(ParticipantID("o1"), "ALICE", Observer); err == nil {
+		t.Fatalf("observer name must not clash with a voter")
+	}
+}
+
+func TestParticipantKind_MarshalText(t *testing.T) {
+	b, _ := Observer.MarshalText()
+	if string(b) != "observer" || Voter.String() != "voter" {
+		t.Fatalf("unexpected kind encoding: %q %q", b, Voter.String())
+	}
+	var k ParticipantKind
+	if err := k.UnmarshalText(b); err != nil || k != Observer {
+		t.Fatalf("decode: got %v, %v", k, err)
+	}
+	if err := k.UnmarshalText([]byte("boss")); err == nil {
+		t.Fatalf("unknown kind should not decode")
+	}
+}
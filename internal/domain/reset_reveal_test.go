@@ -2,29 +2,30 @@ package domain
 
 import (
 	"testing"
+	"time"
 )
 
 func TestRoom_Reveal_Rules(t *testing.T) {
 	r := NewRoom(RoomID("r1"))
+	if err := r.Join(ParticipantID("p1"), "Alice"); err != nil {
+		t.Fatalf("join: %v", err)
+	}
 
 	// Reveal without any votes should error
-	if err := r.Reveal(); err == nil {
+	if err := r.Reveal(ParticipantID("p1"), time.Now()); err == nil {
 		t.Fatalf("expected error on reveal with no votes")
 	}
 
-	// Add a participant and a vote, then reveal should succeed
-	if err := r.Join(ParticipantID("p1"), "Alice"); err != nil {
-		t.Fatalf("join: %v", err)
-	}
+	// Add a vote, then reveal should succeed
 	if err := r.CastVote(ParticipantID("p1"), "8"); err != nil {
 		t.Fatalf("cast vote: %v", err)
 	}
-	if err := r.Reveal(); err != nil {
+	if err := r.Reveal(ParticipantID("p1"), time.Now()); err != nil {
 		t.Fatalf("reveal after vote: %v", err)
 	}
 
 	// Idempotent: second reveal is a no-op
-	if err := r.Reveal(); err != nil {
+	if err := r.Reveal(ParticipantID("p1"), time.Now()); err != nil {
 		t.Fatalf("second reveal should be idempotent: %v", err)
 	}
 }
@@ -43,12 +44,12 @@ func TestRoom_Reset_ClearsAndReopens(t *testing.T) {
 	if err := r.CastVote(ParticipantID("p2"), "Pass"); err != nil {
 		t.Fatalf("cast: %v", err)
 	}
-	if err := r.Reveal(); err != nil {
+	if err := r.Reveal(ParticipantID("p1"), time.Now()); err != nil {
 		t.Fatalf("reveal: %v", err)
 	}
 
 	before := r.RoundIndex()
-	if err := r.Reset(); err != nil {
+	if err := r.Reset(ParticipantID("p1"), time.Now()); err != nil {
 		t.Fatalf("reset: %v", err)
 	}
 	after := r.RoundIndex()
@@ -57,7 +58,7 @@ func TestRoom_Reset_ClearsAndReopens(t *testing.T) {
 	}
 
 	// After reset, votes cleared: reveal should now fail until someone votes
-	if err := r.Reveal(); err == nil {
+	if err := r.Reveal(ParticipantID("p1"), time.Now()); err == nil {
 		t.Fatalf("expected error on reveal with no votes after reset")
 	}
 
@@ -1,9 +1,11 @@
 package domain
 
 import (
-	"errors"
 	"fmt"
+	"sort"
 	"strings"
+	"time"
+	"unicode/utf8"
 )
 
 type (
@@ -11,25 +13,107 @@ type (
 	ParticipantID string
 )
 
-const MaxParticipants = 25
+// MaxParticipants caps voters per room; MaxObservers caps observers separately.
+const (
+	MaxParticipants = 25
+	MaxObservers    = 25
+)
+
+// MaxLabelLen bounds the optional round/story label, in runes.
+const MaxLabelLen = 120
+
+// ParticipantKind distinguishes voters from observers, who watch without voting.
+type ParticipantKind int
+
+const (
+	Voter ParticipantKind = iota
+	Observer
+)
+
+// String returns "voter" or "observer".
+func (k ParticipantKind) String() string {
+	if k == Observer {
+		return "observer"
+	}
+	return "voter"
+}
+
+// MarshalText encodes the kind by name (e.g. in JSON event payloads).
+func (k ParticipantKind) MarshalText() ([]byte, error) { return []byte(k.String()), nil }
+
+// UnmarshalText decodes a kind encoded by MarshalText.
+func (k *ParticipantKind) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "voter":
+		*k = Voter
+	case "observer":
+		*k = Observer
+	default:
+		return fmt.Errorf("unknown participant kind %q", text)
+	}
+	return nil
+}
 
 type Participant struct {
 	ID   ParticipantID
 	Name string
+	Kind ParticipantKind
 }
 
+// IsObserver reports whether the participant only watches.
+func (p Participant) IsObserver() bool { return p.Kind == Observer }
+
 type Room struct {
-	id           RoomID
-	participants map[ParticipantID]Participant
-	names        map[string]ParticipantID // lowercase name → ID
-	votes        map[ParticipantID]string // current round votes
-	state        roundState
-	round        int // increments on each Reset
+	id            RoomID
+	title         string
+	deck          Deck
+	participants  map[ParticipantID]Participant
+	joined        []ParticipantID          // join order
+	names         map[string]ParticipantID // lowercase name → ID
+	facilitator   ParticipantID            // empty only while the room is empty
+	votes         map[ParticipantID]string // current round votes
+	state         roundState
+	round         int // increments on each Reset
+	label         string
+	revealedAt    time.Time
+	history       []RoundResult // completed rounds, oldest first
+	stories       []Story       // backlog, estimated in order
+	current       int           // index of the story being estimated
+	passcode      string        // hash, see SetPasscode
+	locked        bool          // no new participants, see SetLocked
+	creatorJoined bool          // the creator's claim was used, see JoinAsCreator
+}
+
+// RoundResult is the archived outcome of a completed (revealed) round.
+type RoundResult struct {
+	Index      int
+	Label      string
+	Estimate   string      // agreed card, when the round ended via NextStory
+	Votes      []NamedVote // sorted by name
+	RevealedAt time.Time
+	ResetAt    time.Time
 }
 
+// NamedVote is a revealed vote attributed to the participant's display name.
+type NamedVote struct {
+	Name string
+	Card string
+}
+
+// NewRoom creates a room using the default (Fibonacci) deck.
 func NewRoom(id RoomID) *Room {
+	return NewRoomWithDeck(id, DefaultDeck())
+}
+
+// NewRoomWithDeck creates a room voting with the given deck; a zero Deck
+// falls back to the default deck.
+func NewRoomWithDeck(id RoomID, deck Deck) *Room {
+	if deck.IsZero() {
+		deck = DefaultDeck()
+	}
 	return &Room{
 		id:           id,
+		deck:         deck,
 		participants: make(map[ParticipantID]Participant),
 		names:        make(map[string]ParticipantID),
 		votes:        make(map[ParticipantID]string),
@@ -41,22 +125,45 @@ func NewRoom(id RoomID) *Room {
 // ID returns the room's identifier.
 func (r *Room) ID() RoomID { return r.id }
 
-// Join adds a participant with the given ID and display name.
+// Join adds a voter with the given ID and display name.
 // Name must be unique within the room (case-insensitive) and non-empty after trim.
+// The first participant to join an empty room becomes its facilitator.
 func (r *Room) Join(id ParticipantID, name string) error {
+	return r.JoinAs(id, name, Voter)
+}
+
+// JoinAs adds a participant of the given kind. Voters and observers have
+// separate capacities (MaxParticipants and MaxObservers); names are unique
+// across both.
+func (r *Room) JoinAs(id ParticipantID, name string, kind ParticipantKind) error {
+	if r.locked {
+		return ErrRoomLocked
+	}
+	return r.join(id, name, kind)
+}
+
+func (r *Room) join(id ParticipantID, name string, kind ParticipantKind) error {
 	trimmed := strings.TrimSpace(name)
 	if trimmed == "" {
-		return errors.New("invalid name: empty")
+		return fmt.Errorf("%w: empty", ErrInvalidName)
 	}
-	if len(r.participants) >= MaxParticipants {
-		return fmt.Errorf("capacity reached: max %d participants", MaxParticipants)
+	if kind == Observer {
+		if r.count(Observer) >= MaxObservers {
+			return fmt.Errorf("%w: max %d observers", ErrRoomFull, MaxObservers)
+		}
+	} else if r.count(Voter) >= MaxParticipants {
+		return fmt.Errorf("%w: max %d participants", ErrRoomFull, MaxParticipants)
 	}
 	key := strings.ToLower(trimmed)
 	if _, exists := r.names[key]; exists {
-		return fmt.Errorf("duplicate name: %q", trimmed)
+		return fmt.Errorf("%w: %q", ErrDuplicateName, trimmed)
 	}
-	r.participants[id] = Participant{ID: id, Name: trimmed}
+	r.participants[id] = Participant{ID: id, Name: trimmed, Kind: kind}
+	r.joined = append(r.joined, id)
 	r.names[key] = id
+	if r.facilitator == "" {
+		r.facilitator = id
+	}
 	return nil
 }
 
@@ -67,76 +174,138 @@ const (
 	stateRevealed
 )
 
-var allowedCards = map[string]struct{}{
-	// initialized from deckV1 in init
-}
-
-// deckV1 is the immutable v1 deck (order matters for UI rendering).
-var deckV1 = []string{"0", "1", "2", "3", "5", "8", "13", "21", "34", "?", "∞", "☕", "Pass"}
-
-func init() {
-	// Build the allowedCards set from the deck
-	allowedCards = make(map[string]struct{}, len(deckV1))
-	for _, c := range deckV1 {
-		allowedCards[c] = struct{}{}
-	}
-}
-
 // CastVote records a participant's vote while in Voting state.
 func (r *Room) CastVote(id ParticipantID, card string) error {
 	if r.state != stateVoting {
-		return errors.New("voting is closed")
+		return ErrVotingClosed
 	}
-	if _, ok := r.participants[id]; !ok {
-		return errors.New("not a participant")
+	p, ok := r.participants[id]
+	if !ok {
+		return ErrNotParticipant
+	}
+	if p.IsObserver() {
+		return ErrObserverCannotVote
 	}
-	if _, ok := allowedCards[card]; !ok {
-		return fmt.Errorf("invalid card: %s", card)
+	if !r.deck.Contains(card) {
+		return fmt.Errorf("%w: %s", ErrInvalidCard, card)
 	}
 	r.votes[id] = card
 	return nil
 }
 
-// Reveal reveals votes; requires at least one vote to exist; transitions to Revealed.
-func (r *Room) Reveal() error {
+// Reveal reveals votes at the given time; only the facilitator may reveal and
+// at least one vote must exist; transitions to Revealed.
+func (r *Room) Reveal(by ParticipantID, at time.Time) error {
+	if err := r.requireFacilitator(by); err != nil {
+		return err
+	}
 	if r.state != stateVoting {
 		return nil // idempotent
 	}
 	if len(r.votes) == 0 {
-		return errors.New("cannot reveal: no votes")
+		return ErrNoVotes
 	}
 	r.state = stateRevealed
+	r.revealedAt = at
+	return nil
+}
+
+// Reset starts a new round: archives the current round if it was revealed,
+// clears all votes and the label, and re-opens voting. The current story stays
+// in place (a re-vote); use NextStory to move on. Facilitator only.
+func (r *Room) Reset(by ParticipantID, at time.Time) error {
+	if err := r.requireFacilitator(by); err != nil {
+		return err
+	}
+	if r.state == stateRevealed {
+		r.history = append(r.history, r.currentResult(at))
+	}
+	r.newRound()
 	return nil
 }
 
-// Reset starts a new round: clears all votes and re-opens voting.
-func (r *Room) Reset() error {
+// newRound clears votes and the label and re-opens voting under a new index.
+func (r *Room) newRound() {
 	r.votes = make(map[ParticipantID]string)
 	r.state = stateVoting
 	r.round++
+	r.label = ""
+	r.revealedAt = time.Time{}
+}
+
+func (r *Room) currentResult(resetAt time.Time) RoundResult {
+	votes := make([]NamedVote, 0, len(r.votes))
+	for id, card := range r.votes {
+		votes = append(votes, NamedVote{Name: r.participants[id].Name, Card: card})
+	}
+	sort.Slice(votes, func(i, j int) bool { return strings.ToLower(votes[i].Name) < strings.ToLower(votes[j].Name) })
+	return RoundResult{
+		Index:      r.round,
+		Label:      r.Label(),
+		Votes:      votes,
+		RevealedAt: r.revealedAt,
+		ResetAt:    resetAt,
+	}
+}
+
+// SetLabel sets the optional label (e.g. story title) of the current round.
+// The label is trimmed; an empty label clears it. Facilitator only.
+func (r *Room) SetLabel(by ParticipantID, label string) error {
+	if err := r.requireFacilitator(by); err != nil {
+		return err
+	}
+	trimmed := strings.TrimSpace(label)
+	if utf8.RuneCountInString(trimmed) > MaxLabelLen {
+		return fmt.Errorf("%w: longer than %d characters", ErrInvalidLabel, MaxLabelLen)
+	}
+	r.label = trimmed
 	return nil
 }
 
+// Label returns the current round's label: the one set with SetLabel or,
+// failing that, the title of the current story.
+func (r *Room) Label() string {
+	if r.label != "" {
+		return r.label
+	}
+	if s, ok := r.CurrentStory(); ok {
+		return s.DisplayTitle()
+	}
+	return ""
+}
+
+// History returns the archived results of completed rounds, oldest first.
+func (r *Room) History() []RoundResult {
+	out := make([]RoundResult, len(r.history))
+	for i, h := range r.history {
+		h.Votes = append([]NamedVote(nil), h.Votes...)
+		out[i] = h
+	}
+	return out
+}
+
 // RoundIndex returns the current round index, starting at 0.
 func (r *Room) RoundIndex() int { return r.round }
 
 // ClearVote clears a participant's current vote in Voting state.
 func (r *Room) ClearVote(id ParticipantID) error {
 	if r.state != stateVoting {
-		return errors.New("voting is closed")
+		return ErrVotingClosed
 	}
 	if _, ok := r.participants[id]; !ok {
-		return errors.New("not a participant")
+		return ErrNotParticipant
 	}
 	delete(r.votes, id)
 	return nil
 }
 
 // Leave removes a participant from the room and clears any vote.
+// If the facilitator leaves, the longest-present remaining participant
+// takes over; an empty room has no facilitator and is unlocked.
 func (r *Room) Leave(id ParticipantID) error {
 	p, ok := r.participants[id]
 	if !ok {
-		return errors.New("not a participant")
+		return ErrNotParticipant
 	}
 	// Remove vote if present
 	delete(r.votes, id)
@@ -144,14 +313,40 @@ func (r *Room) Leave(id ParticipantID) error {
 	key := strings.ToLower(strings.TrimSpace(p.Name))
 	delete(r.names, key)
 	delete(r.participants, id)
+	for i, j := range r.joined {
+		if j == id {
+			r.joined = append(r.joined[:i], r.joined[i+1:]...)
+			break
+		}
+	}
+	if r.facilitator == id {
+		r.facilitator = ""
+		if len(r.joined) > 0 {
+			r.facilitator = r.joined[0]
+		}
+	}
+	if len(r.joined) == 0 {
+		r.locked = false
+	}
 	return nil
 }
 
-// Participants returns a snapshot slice of current participants.
-func (r *Room) Participants() []Participant {
-	out := make([]Participant, 0, len(r.participants))
+// count returns the number of participants of the given kind.
+func (r *Room) count(kind ParticipantKind) int {
+	n := 0
 	for _, p := range r.participants {
-		out = append(out, p)
+		if p.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+// Participants returns a snapshot slice of current participants in join order.
+func (r *Room) Participants() []Participant {
+	out := make([]Participant, 0, len(r.joined))
+	for _, id := range r.joined {
+		out = append(out, r.participants[id])
 	}
 	return out
 }
@@ -168,9 +363,8 @@ func (r *Room) Votes() map[ParticipantID]string {
 	return out
 }
 
-// Deck returns the immutable v1 deck in display order.
-func (r *Room) Deck() []string {
-	out := make([]string, len(deckV1))
-	copy(out, deckV1)
-	return out
-}
+// Deck returns the room's cards in display order.
+func (r *Room) Deck() []string { return r.deck.Cards() }
+
+// DeckName returns the name of the room's deck (a preset name or DeckCustom).
+func (r *Room) DeckName() string { return r.deck.Name() }
@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RoomState is a plain-data copy of a Room, used by repositories to persist
+// and reload rooms. It carries no behaviour; RestoreRoom validates it.
+type RoomState struct {
+	ID            RoomID
+	Title         string
+	DeckName      string
+	DeckCards     []string
+	Participants  []Participant // join order
+	Facilitator   ParticipantID
+	Votes         map[ParticipantID]string
+	Revealed      bool
+	Round         int
+	Label         string
+	RevealedAt    time.Time
+	History       []RoundResult
+	Stories       []Story
+	CurrentStory  int
+	Passcode      string // hash
+	Locked        bool
+	CreatorJoined bool // the creator's claim was used
+}
+
+// State returns a deep copy of the room's state.
+func (r *Room) State() RoomState {
+	return RoomState{
+		ID:            r.id,
+		Title:         r.title,
+		DeckName:      r.deck.Name(),
+		DeckCards:     r.deck.Cards(),
+		Participants:  r.Participants(),
+		Facilitator:   r.facilitator,
+		Votes:         r.Votes(),
+		Revealed:      r.state == stateRevealed,
+		Round:         r.round,
+		Label:         r.label,
+		RevealedAt:    r.revealedAt,
+		History:       r.History(),
+		Stories:       r.Stories(),
+		CurrentStory:  r.current,
+		Passcode:      r.passcode,
+		Locked:        r.locked,
+		CreatorJoined: r.creatorJoined,
+	}
+}
+
+// RestoreRoom rebuilds a room from a previously captured state. It checks the
+// invariants that tie the parts together (unique names, votes and the
+// facilitator referring to participants, the story index) but does not
+// re-apply command-level limits, so rooms persisted under older limits load.
+func RestoreRoom(s RoomState) (*Room, error) {
+	if s.ID == "" {
+		return nil, errors.New("restore room: empty id")
+	}
+	r := NewRoomWithDeck(s.ID, Deck{name: s.DeckName, cards: append([]string(nil), s.DeckCards...)})
+	r.title = s.Title
+	for _, p := range s.Participants {
+		key := strings.ToLower(strings.TrimSpace(p.Name))
+		if p.ID == "" || key == "" {
+			return nil, fmt.Errorf("restore room %s: invalid participant %q", s.ID, p.Name)
+		}
+		if _, dup := r.names[key]; dup {
+			return nil, fmt.Errorf("restore room %s: duplicate name %q", s.ID, p.Name)
+		}
+		if _, dup := r.participants[p.ID]; dup {
+			return nil, fmt.Errorf("restore room %s: duplicate participant %s", s.ID, p.ID)
+		}
+		r.participants[p.ID] = p
+		r.joined = append(r.joined, p.ID)
+		r.names[key] = p.ID
+	}
+	if s.Facilitator != "" {
+		if _, ok := r.participants[s.Facilitator]; !ok {
+			return nil, fmt.Errorf("restore room %s: facilitator %s is not a participant", s.ID, s.Facilitator)
+		}
+	} else if len(r.joined) > 0 {
+		return nil, fmt.Errorf("restore room %s: missing facilitator", s.ID)
+	}
+	r.facilitator = s.Facilitator
+	for id, card := range s.Votes {
+		if _, ok := r.participants[id]; !ok {
+			return nil, fmt.Errorf("restore room %s: vote by unknown participant %s", s.ID, id)
+		}
+		r.votes[id] = card
+	}
+	if s.Revealed {
+		r.state = stateRevealed
+	}
+	if s.CurrentStory < 0 || s.CurrentStory > len(s.Stories) {
+		return nil, fmt.Errorf("restore room %s: story index %d out of range", s.ID, s.CurrentStory)
+	}
+	r.round = s.Round
+	r.label = s.Label
+	r.revealedAt = s.RevealedAt
+	for _, h := range s.History {
+		h.Votes = append([]NamedVote(nil), h.Votes...)
+		r.history = append(r.history, h)
+	}
+	r.stories = append([]Story(nil), s.Stories...)
+	r.current = s.CurrentStory
+	r.passcode = s.Passcode
+	r.locked = s.Locked
+	r.creatorJoined = s.CreatorJoined
+	return r, nil
+}
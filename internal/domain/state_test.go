@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRoom_StateRestore_RoundTrip(t *testing.T) {
+	deck, _ := NewCustomDeck([]string{"S", "M", "L"})
+	r := NewRoomWithDeck(RoomID("r1"), deck)
+	_ = r.SetTitle("Sprint")
+	r.SetPasscode("hash")
+	_ = r.JoinAsCreator(ParticipantID("p1"), "Alice", Voter)
+	_ = r.JoinAs(ParticipantID("p2"), "Olga", Observer)
+	_ = r.Join(ParticipantID("p3"), "Bob")
+	_ = r.AddStory(ParticipantID("p1"), Story{Title: "Login", Key: "K-1"})
+	_ = r.AddStory(ParticipantID("p1"), Story{Title: "Logout"})
+	at := time.Date(2025, 9, 3, 10, 0, 0, 0, time.UTC)
+	_ = r.CastVote(ParticipantID("p1"), "M")
+	_ = r.Reveal(ParticipantID("p1"), at)
+	_ = r.NextStory(ParticipantID("p1"), "M", at.Add(time.Minute))
+	_ = r.TransferFacilitator(ParticipantID("p1"), ParticipantID("p3"))
+	_ = r.SetLabel(ParticipantID("p3"), "Logout (split)")
+	_ = r.CastVote(ParticipantID("p3"), "L")
+	_ = r.Reveal(ParticipantID("p3"), at.Add(2*time.Minute))
+
+	got, err := RestoreRoom(r.State())
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if !reflect.DeepEqual(got.State(), r.State()) {
+		t.Fatalf("round trip mismatch:\n got %+v\nwant %+v", got.State(), r.State())
+	}
+
+	// The restored room behaves like the original.
+	if err := got.Join(ParticipantID("p4"), "alice"); err == nil {
+		t.Fatalf("restored room should keep the name index")
+	}
+	if err := got.CastVote(ParticipantID("p1"), "S"); err == nil {
+		t.Fatalf("restored room should stay revealed")
+	}
+	if got.DeckName() != DeckCustom || len(got.Deck()) != 3 {
+		t.Fatalf("restored room should keep its deck, got %s %v", got.DeckName(), got.Deck())
+	}
+}
+
+func TestRestoreRoom_RejectsInconsistentState(t *testing.T) {
+	base := func() RoomState {
+		return RoomState{
+			ID:           RoomID("r1"),
+			Participants: []Participant{{ID: "p1", Name: "Alice"}},
+			Facilitator:  "p1",
+			Votes:        map[ParticipantID]string{},
+		}
+	}
+	cases := map[string]func(*RoomState){
+		"empty id":            func(s *RoomState) { s.ID = "" },
+		"duplicate name":      func(s *RoomState) { s.Participants = append(s.Participants, Participant{ID: "p2", Name: "ALICE"}) },
+		"unknown facilitator": func(s *RoomState) { s.Facilitator = "p9" },
+		"missing facilitator": func(s *RoomState) { s.Facilitator = "" },
+		"unknown voter":       func(s *RoomState) { s.Votes["p9"] = "5" },
+		"story index":         func(s *RoomState) { s.CurrentStory = 1 },
+	}
+	if _, err := RestoreRoom(base()); err != nil {
+		t.Fatalf("base state should restore: %v", err)
+	}
+	for name, mutate := range cases {
+		s := base()
+		mutate(&s)
+		if _, err := RestoreRoom(s); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package domain
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// specialCards are cards that express something other than an estimate and
+// are therefore excluded from statistics and consensus.
+var specialCards = map[string]struct{}{"?": {}, "∞": {}, "☕": {}, "Pass": {}}
+
+// IsSpecialCard reports whether the card is a special ("?", "∞", "☕", "Pass").
+func IsSpecialCard(card string) bool {
+	_, ok := specialCards[card]
+	return ok
+}
+
+// CardValue interprets a card as a number ("½" is 0.5). Specials and
+// non-numeric cards such as T-shirt sizes report ok=false.
+func CardValue(card string) (float64, bool) {
+	if IsSpecialCard(card) {
+		return 0, false
+	}
+	if card == "½" {
+		return 0.5, true
+	}
+	v, err := strconv.ParseFloat(strings.TrimSpace(card), 64)
+	if err != nil || math.IsInf(v, 0) || math.IsNaN(v) {
+		return 0, false
+	}
+	return v, true
+}
+
+// Stats summarizes a set of revealed votes against a deck.
+//
+// Mean, Median, Min, Max and StdDev are computed over numeric cards only and
+// are meaningful only when Numeric > 0. Mode considers every non-special card
+// so it also works for non-numeric decks (e.g. T-shirt sizes).
+type Stats struct {
+	Votes     int            // all votes, including specials
+	Numeric   int            // votes with a numeric card
+	Specials  map[string]int // special card → count
+	Mean      float64
+	Median    float64
+	Min       float64
+	Max       float64
+	StdDev    float64  // population standard deviation
+	Mode      []string // most frequent estimate card(s), in deck order
+	Nearest   string   // numeric deck card closest to the mean (ties round up)
+	Consensus bool     // every estimate vote is the same card
+}
+
+// ComputeStats computes statistics for the given votes (cards) cast from deck.
+func ComputeStats(deck Deck, votes []string) Stats {
+	st := Stats{Votes: len(votes), Specials: make(map[string]int)}
+
+	counts := make(map[string]int)
+	var values []float64
+	for _, card := range votes {
+		if IsSpecialCard(card) {
+			st.Specials[card]++
+			continue
+		}
+		counts[card]++
+		if v, ok := CardValue(card); ok {
+			values = append(values, v)
+		}
+	}
+
+	st.Mode = modeOf(deck, counts)
+	st.Consensus = len(counts) == 1
+
+	st.Numeric = len(values)
+	if len(values) == 0 {
+		return st
+	}
+	sort.Float64s(values)
+	st.Min, st.Max = values[0], values[len(values)-1]
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	st.Mean = sum / float64(len(values))
+	if n := len(values); n%2 == 1 {
+		st.Median = values[n/2]
+	} else {
+		st.Median = (values[n/2-1] + values[n/2]) / 2
+	}
+	var sq float64
+	for _, v := range values {
+		sq += (v - st.Mean) * (v - st.Mean)
+	}
+	st.StdDev = math.Sqrt(sq / float64(len(values)))
+	st.Nearest = nearestCard(deck, st.Mean)
+	return st
+}
+
+// modeOf returns the most frequent card(s), ordered as in the deck.
+func modeOf(deck Deck, counts map[string]int) []string {
+	best := 0
+	for _, n := range counts {
+		if n > best {
+			best = n
+		}
+	}
+	if best == 0 {
+		return nil
+	}
+	var out []string
+	for _, c := range deck.cards {
+		if counts[c] == best {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// nearestCard returns the numeric deck card closest to v; on a tie the
+// larger card wins, following the usual "round up" estimation convention.
+func nearestCard(deck Deck, v float64) string {
+	best, bestDist, bestVal := "", math.Inf(1), math.Inf(-1)
+	for _, c := range deck.cards {
+		cv, ok := CardValue(c)
+		if !ok {
+			continue
+		}
+		d := math.Abs(cv - v)
+		if d < bestDist || (d == bestDist && cv > bestVal) {
+			best, bestDist, bestVal = c, d, cv
+		}
+	}
+	return best
+}
+
+// Stats computes statistics over the current round's votes using the room's deck.
+func (r *Room) Stats() Stats {
+	cards := make([]string, 0, len(r.votes))
+	for _, c := range r.votes {
+		cards = append(cards, c)
+	}
+	return ComputeStats(r.deck, cards)
+}
@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestComputeStats_NumericAndSpecials(t *testing.T) {
+	st := ComputeStats(DefaultDeck(), []string{"3", "5", "5", "13", "?", "☕"})
+
+	if st.Votes != 6 || st.Numeric != 4 {
+		t.Fatalf("counts: votes=%d numeric=%d", st.Votes, st.Numeric)
+	}
+	if st.Specials["?"] != 1 || st.Specials["☕"] != 1 || len(st.Specials) != 2 {
+		t.Fatalf("specials: %v", st.Specials)
+	}
+	if st.Mean != 6.5 || st.Median != 5 || st.Min != 3 || st.Max != 13 {
+		t.Fatalf("mean/median/min/max: %+v", st)
+	}
+	// population stddev of 3,5,5,13 around 6.5
+	want := math.Sqrt((12.25 + 2.25 + 2.25 + 42.25) / 4)
+	if math.Abs(st.StdDev-want) > 1e-9 {
+		t.Fatalf("stddev: got %v want %v", st.StdDev, want)
+	}
+	if !reflect.DeepEqual(st.Mode, []string{"5"}) {
+		t.Fatalf("mode: %v", st.Mode)
+	}
+	// 6.5 is between 5 and 8: 8 is 1.5 away, 5 is 1.5 away → tie rounds up.
+	if st.Nearest != "8" {
+		t.Fatalf("nearest: got %q want 8", st.Nearest)
+	}
+	if st.Consensus {
+		t.Fatalf("mixed votes are not a consensus")
+	}
+}
+
+func TestComputeStats_Consensus_IgnoresSpecials(t *testing.T) {
+	st := ComputeStats(DefaultDeck(), []string{"8", "8", "Pass"})
+	if !st.Consensus || st.Nearest != "8" || st.Median != 8 || st.StdDev != 0 {
+		t.Fatalf("expected consensus on 8: %+v", st)
+	}
+
+	onlySpecials := ComputeStats(DefaultDeck(), []string{"?", "∞"})
+	if onlySpecials.Consensus || onlySpecials.Numeric != 0 || onlySpecials.Nearest != "" || onlySpecials.Mode != nil {
+		t.Fatalf("specials only should yield no estimate: %+v", onlySpecials)
+	}
+}
+
+func TestComputeStats_HalfCardAndEvenMedian(t *testing.T) {
+	mod, _ := PresetDeck(DeckModifiedFibonacci)
+	st := ComputeStats(mod, []string{"½", "1", "2", "3"})
+	if st.Min != 0.5 || st.Median != 1.5 || st.Mean != 1.625 {
+		t.Fatalf("unexpected stats: %+v", st)
+	}
+	if st.Nearest != "2" {
+		t.Fatalf("nearest: got %q want 2", st.Nearest)
+	}
+	if !reflect.DeepEqual(st.Mode, []string{"½", "1", "2", "3"}) {
+		t.Fatalf("all-tied mode should list cards in deck order: %v", st.Mode)
+	}
+}
+
+func TestComputeStats_NonNumericDeck(t *testing.T) {
+	tshirt, _ := PresetDeck(DeckTShirt)
+	st := ComputeStats(tshirt, []string{"M", "L", "M"})
+	if st.Numeric != 0 || st.Nearest != "" {
+		t.Fatalf("t-shirt sizes are not numeric: %+v", st)
+	}
+	if !reflect.DeepEqual(st.Mode, []string{"M"}) || st.Consensus {
+		t.Fatalf("mode/consensus: %+v", st)
+	}
+}
+
+func TestRoom_Stats_FromCurrentVotes(t *testing.T) {
+	r := NewRoom(RoomID("r1"))
+	_ = r.Join(ParticipantID("p1"), "Alice")
+	_ = r.Join(ParticipantID("p2"), "Bob")
+	_ = r.CastVote(ParticipantID("p1"), "2")
+	_ = r.CastVote(ParticipantID("p2"), "3")
+	st := r.Stats()
+	if st.Votes != 2 || st.Mean != 2.5 || st.Nearest != "3" {
+		t.Fatalf("unexpected room stats: %+v", st)
+	}
+}
@@ -0,0 +1,166 @@
+package domain
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Story backlog limits.
+const (
+	MaxStories     = 100
+	MaxStoryKeyLen = 32
+	MaxLinkLen     = 2048
+)
+
+// Story is an item of the room's backlog, estimated in order.
+type Story struct {
+	Title    string
+	Key      string // optional tracker key, e.g. "PROJ-42"
+	Link     string // optional http(s) URL
+	Estimate string // agreed card, set when the facilitator moves on
+}
+
+// DisplayTitle returns the title prefixed with the key, if any.
+func (s Story) DisplayTitle() string {
+	if s.Key == "" {
+		return s.Title
+	}
+	return s.Key + " " + s.Title
+}
+
+// SetTitle sets the room's session title. The title is trimmed and may be empty.
+func (r *Room) SetTitle(title string) error {
+	trimmed := strings.TrimSpace(title)
+	if utf8.RuneCountInString(trimmed) > MaxLabelLen {
+		return fmt.Errorf("%w: longer than %d characters", ErrInvalidTitle, MaxLabelLen)
+	}
+	r.title = trimmed
+	return nil
+}
+
+// Title returns the room's session title, if any.
+func (r *Room) Title() string { return r.title }
+
+// AddStory appends a story to the end of the backlog. Facilitator only.
+func (r *Room) AddStory(by ParticipantID, s Story) error {
+	if err := r.requireFacilitator(by); err != nil {
+		return err
+	}
+	s, err := normalizeStory(s)
+	if err != nil {
+		return err
+	}
+	if len(r.stories) >= MaxStories {
+		return fmt.Errorf("%w: max %d", ErrTooManyStories, MaxStories)
+	}
+	r.stories = append(r.stories, s)
+	return nil
+}
+
+// RemoveStory deletes a pending story (the current one or any after it).
+// Facilitator only.
+func (r *Room) RemoveStory(by ParticipantID, index int) error {
+	if err := r.requireFacilitator(by); err != nil {
+		return err
+	}
+	if err := r.requirePending(index); err != nil {
+		return err
+	}
+	r.stories = append(r.stories[:index], r.stories[index+1:]...)
+	return nil
+}
+
+// MoveStory moves a pending story to another pending position, shifting the
+// stories in between. Facilitator only.
+func (r *Room) MoveStory(by ParticipantID, from, to int) error {
+	if err := r.requireFacilitator(by); err != nil {
+		return err
+	}
+	if err := r.requirePending(from); err != nil {
+		return err
+	}
+	if err := r.requirePending(to); err != nil {
+		return err
+	}
+	s := r.stories[from]
+	r.stories = append(r.stories[:from], r.stories[from+1:]...)
+	r.stories = append(r.stories[:to], append([]Story{s}, r.stories[to:]...)...)
+	return nil
+}
+
+// NextStory records the agreed estimate on the current story, moves on to
+// the next one and starts a new round (archiving a revealed round like
+// Reset). The estimate must be a card of the room's deck, or empty to skip
+// the story unestimated. Facilitator only.
+func (r *Room) NextStory(by ParticipantID, estimate string, at time.Time) error {
+	if err := r.requireFacilitator(by); err != nil {
+		return err
+	}
+	if r.current >= len(r.stories) {
+		return ErrNoCurrentStory
+	}
+	estimate = strings.TrimSpace(estimate)
+	if estimate != "" && !r.deck.Contains(estimate) {
+		return fmt.Errorf("%w: %s", ErrInvalidEstimate, estimate)
+	}
+	if r.state == stateRevealed {
+		res := r.currentResult(at)
+		res.Estimate = estimate
+		r.history = append(r.history, res)
+	}
+	r.stories[r.current].Estimate = estimate
+	r.current++
+	r.newRound()
+	return nil
+}
+
+// CurrentStory returns the story being estimated, if the backlog has one left.
+func (r *Room) CurrentStory() (Story, bool) {
+	if r.current >= len(r.stories) {
+		return Story{}, false
+	}
+	return r.stories[r.current], true
+}
+
+// CurrentStoryIndex returns the index of the story being estimated; it equals
+// len(Stories()) once the backlog is exhausted.
+func (r *Room) CurrentStoryIndex() int { return r.current }
+
+// Stories returns a copy of the backlog, estimated stories first.
+func (r *Room) Stories() []Story { return append([]Story(nil), r.stories...) }
+
+func (r *Room) requirePending(index int) error {
+	if index < r.current || index >= len(r.stories) {
+		return fmt.Errorf("%w: no pending story at %d", ErrInvalidStory, index)
+	}
+	return nil
+}
+
+func normalizeStory(s Story) (Story, error) {
+	s.Title = strings.TrimSpace(s.Title)
+	s.Key = strings.TrimSpace(s.Key)
+	s.Link = strings.TrimSpace(s.Link)
+	s.Estimate = ""
+	if s.Title == "" {
+		return Story{}, fmt.Errorf("%w: empty title", ErrInvalidStory)
+	}
+	if utf8.RuneCountInString(s.Title) > MaxLabelLen {
+		return Story{}, fmt.Errorf("%w: title longer than %d characters", ErrInvalidStory, MaxLabelLen)
+	}
+	if utf8.RuneCountInString(s.Key) > MaxStoryKeyLen {
+		return Story{}, fmt.Errorf("%w: key longer than %d characters", ErrInvalidStory, MaxStoryKeyLen)
+	}
+	if s.Link != "" {
+		if len(s.Link) > MaxLinkLen {
+			return Story{}, fmt.Errorf("%w: link longer than %d characters", ErrInvalidStory, MaxLinkLen)
+		}
+		u, err := url.Parse(s.Link)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return Story{}, fmt.Errorf("%w: link must be an http(s) URL", ErrInvalidStory)
+		}
+	}
+	return s, nil
+}
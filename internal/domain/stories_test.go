@@ -0,0 +1,184 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func storyRoom(t *testing.T) *Room {
+	t.Helper()
+	r := NewRoom(RoomID("r1"))
+	if err := r.Join(ParticipantID("p1"), "Alice"); err != nil {
+		t.Fatalf("join: %v", err)
+	}
+	if err := r.Join(ParticipantID("p2"), "Bob"); err != nil {
+		t.Fatalf("join: %v", err)
+	}
+	return r
+}
+
+func titles(ss []Story) string {
+	out := make([]string, len(ss))
+	for i, s := range ss {
+		out[i] = s.Title
+	}
+	return strings.Join(out, ",")
+}
+
+func TestRoom_SetTitle(t *testing.T) {
+	r := NewRoom(RoomID("r1"))
+	if err := r.SetTitle("  Sprint 12  "); err != nil || r.Title() != "Sprint 12" {
+		t.Fatalf("title: %q, %v", r.Title(), err)
+	}
+	if err := r.SetTitle(strings.Repeat("x", MaxLabelLen+1)); err == nil {
+		t.Fatalf("expected error for overlong title")
+	}
+}
+
+func TestRoom_AddStory_Validation(t *testing.T) {
+	r := storyRoom(t)
+	host := ParticipantID("p1")
+
+	if err := r.AddStory(ParticipantID("p2"), Story{Title: "Login"}); !errors.Is(err, ErrNotFacilitator) {
+		t.Fatalf("expected ErrNotFacilitator, got %v", err)
+	}
+	bad := []Story{
+		{Title: "   "},
+		{Title: strings.Repeat("x", MaxLabelLen+1)},
+		{Title: "Login", Key: strings.Repeat("K", MaxStoryKeyLen+1)},
+		{Title: "Login", Link: "javascript:alert(1)"},
+		{Title: "Login", Link: "not a url"},
+	}
+	for _, s := range bad {
+		if err := r.AddStory(host, s); err == nil {
+			t.Fatalf("expected error for %+v", s)
+		}
+	}
+	if err := r.AddStory(host, Story{Title: " Login ", Key: " PROJ-1 ", Link: " https://example.com/PROJ-1 ", Estimate: "8"}); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	got := r.Stories()
+	if len(got) != 1 || got[0] != (Story{Title: "Login", Key: "PROJ-1", Link: "https://example.com/PROJ-1"}) {
+		t.Fatalf("unexpected stories: %+v", got)
+	}
+	if r.Label() != "PROJ-1 Login" {
+		t.Fatalf("label should default to the current story, got %q", r.Label())
+	}
+}
+
+func TestRoom_AddStory_Capacity(t *testing.T) {
+	r := storyRoom(t)
+	for i := 0; i < MaxStories; i++ {
+		if err := r.AddStory(ParticipantID("p1"), Story{Title: "s"}); err != nil {
+			t.Fatalf("add %d: %v", i, err)
+		}
+	}
+	if err := r.AddStory(ParticipantID("p1"), Story{Title: "s"}); err == nil {
+		t.Fatalf("expected capacity error")
+	}
+}
+
+func TestRoom_MoveRemoveStory(t *testing.T) {
+	r := storyRoom(t)
+	host := ParticipantID("p1")
+	for _, title := range []string{"A", "B", "C", "D"} {
+		_ = r.AddStory(host, Story{Title: title})
+	}
+	if err := r.MoveStory(host, 3, 1); err != nil {
+		t.Fatalf("move: %v", err)
+	}
+	if got := titles(r.Stories()); got != "A,D,B,C" {
+		t.Fatalf("after move up: %s", got)
+	}
+	if err := r.MoveStory(host, 0, 2); err != nil {
+		t.Fatalf("move: %v", err)
+	}
+	if got := titles(r.Stories()); got != "D,B,A,C" {
+		t.Fatalf("after move down: %s", got)
+	}
+	if err := r.RemoveStory(host, 1); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if got := titles(r.Stories()); got != "D,A,C" {
+		t.Fatalf("after remove: %s", got)
+	}
+	if err := r.MoveStory(host, 0, 3); err == nil {
+		t.Fatalf("expected error for out-of-range move")
+	}
+	if err := r.RemoveStory(ParticipantID("p2"), 0); !errors.Is(err, ErrNotFacilitator) {
+		t.Fatalf("expected ErrNotFacilitator, got %v", err)
+	}
+
+	// Estimated stories are frozen.
+	if err := r.NextStory(host, "", time.Time{}); err != nil {
+		t.Fatalf("next: %v", err)
+	}
+	if err := r.RemoveStory(host, 0); err == nil {
+		t.Fatalf("expected error removing an estimated story")
+	}
+	if err := r.MoveStory(host, 2, 0); err == nil {
+		t.Fatalf("expected error moving before the current story")
+	}
+}
+
+func TestRoom_NextStory(t *testing.T) {
+	r := storyRoom(t)
+	host := ParticipantID("p1")
+	_ = r.AddStory(host, Story{Title: "Login", Key: "PROJ-1"})
+	_ = r.AddStory(host, Story{Title: "Logout"})
+
+	_ = r.CastVote(ParticipantID("p1"), "5")
+	_ = r.CastVote(ParticipantID("p2"), "8")
+	revealedAt := time.Date(2025, 9, 3, 10, 0, 0, 0, time.UTC)
+	_ = r.Reveal(host, revealedAt)
+
+	if err := r.NextStory(ParticipantID("p2"), "8", revealedAt); !errors.Is(err, ErrNotFacilitator) {
+		t.Fatalf("expected ErrNotFacilitator, got %v", err)
+	}
+	if err := r.NextStory(host, "7", revealedAt); err == nil {
+		t.Fatalf("expected error for estimate outside the deck")
+	}
+	if err := r.NextStory(host, "8", revealedAt.Add(time.Minute)); err != nil {
+		t.Fatalf("next: %v", err)
+	}
+
+	if s := r.Stories()[0]; s.Estimate != "8" {
+		t.Fatalf("estimate not recorded: %+v", s)
+	}
+	if cur, ok := r.CurrentStory(); !ok || cur.Title != "Logout" || r.CurrentStoryIndex() != 1 {
+		t.Fatalf("expected to advance to Logout, got %+v (%v)", cur, ok)
+	}
+	if r.IsRevealed() || len(r.Votes()) != 0 || r.RoundIndex() != 1 || r.Label() != "Logout" {
+		t.Fatalf("expected a fresh round for the next story")
+	}
+	h := r.History()
+	if len(h) != 1 || h[0].Label != "PROJ-1 Login" || h[0].Estimate != "8" || len(h[0].Votes) != 2 {
+		t.Fatalf("unexpected history: %+v", h)
+	}
+
+	// Skipping an unrevealed story leaves it unestimated and unarchived.
+	if err := r.NextStory(host, "", revealedAt); err != nil {
+		t.Fatalf("next: %v", err)
+	}
+	if _, ok := r.CurrentStory(); ok || r.Stories()[1].Estimate != "" || len(r.History()) != 1 {
+		t.Fatalf("expected backlog exhausted without archiving")
+	}
+	if err := r.NextStory(host, "", revealedAt); err == nil {
+		t.Fatalf("expected error once the backlog is exhausted")
+	}
+}
+
+func TestRoom_Reset_KeepsCurrentStory(t *testing.T) {
+	r := storyRoom(t)
+	host := ParticipantID("p1")
+	_ = r.AddStory(host, Story{Title: "Login"})
+	_ = r.SetLabel(host, "Login (re-vote)")
+	if err := r.Reset(host, time.Time{}); err != nil {
+		t.Fatalf("reset: %v", err)
+	}
+	if cur, ok := r.CurrentStory(); !ok || cur.Title != "Login" || r.Label() != "Login" {
+		t.Fatalf("reset should keep the current story, got %+v label %q", cur, r.Label())
+	}
+}
@@ -3,6 +3,7 @@ package domain
 import (
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestRoom_CastVote_Rules(t *testing.T) {
@@ -33,7 +34,7 @@ func TestRoom_CastVote_Rules(t *testing.T) {
 	}
 
 	// Reveal locks votes
-	must(r.Reveal())
+	must(r.Reveal(ParticipantID("p1"), time.Now()))
 	if err := r.CastVote(ParticipantID("p2"), "8"); err == nil {
 		t.Fatalf("expected voting-closed error after reveal, got nil")
 	}
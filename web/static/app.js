@@ -73,5 +73,52 @@
       this.innerHTML = '<span class="icon"><i class="fas fa-check"></i></span><span>Voted!</span>';
     });
   }
-})();
 
+  // Live room updates: subscribe to the room's SSE stream and re-render the
+  // dynamic sections from a fresh copy of the page whenever an event arrives.
+  const roomEl = document.querySelector('[data-room-id]');
+  const roomID = roomEl ? roomEl.getAttribute('data-room-id') : '';
+  if (roomID && window.EventSource) {
+    const source = new EventSource('/rooms/' + encodeURIComponent(roomID) + '/events');
+    let pending = null;
+
+    function refreshRoom() {
+      fetch(window.location.pathname, { credentials: 'same-origin' })
+        .then(function(res) { return res.ok ? res.text() : null; })
+        .then(function(html) {
+          if (!html) return;
+          const doc = new DOMParser().parseFromString(html, 'text/html');
+          ['status', 'participants', 'observers', 'actions', 'stories', 'history'].forEach(function(id) {
+            const next = doc.getElementById(id);
+            const current = document.getElementById(id);
+            if (next && current) current.replaceWith(next);
+          });
+        })
+        .catch(function() {});
+    }
+
+    // Coalesce bursts of events into a single refresh.
+    function scheduleRefresh() {
+      if (pending) return;
+      pending = setTimeout(function() { pending = null; refreshRoom(); }, 100);
+    }
+
+    ['ParticipantJoined', 'ParticipantLeft', 'VoteCast', 'VoteCleared', 'VotesRevealed', 'RoundReset', 'RoundLabeled', 'FacilitatorChanged', 'StoriesChanged', 'StoryEstimated', 'RoomLocked'].forEach(function(name) {
+      source.addEventListener(name, scheduleRefresh);
+    });
+    // Too far behind to replay what was missed while disconnected: reload.
+    source.addEventListener('Resync', scheduleRefresh);
+    // The room was removed by the server; stop listening and tell the user.
+    source.addEventListener('RoomExpired', function() {
+      source.close();
+      const note = document.createElement('div');
+      note.className = 'notification is-warning has-text-centered';
+      note.innerHTML = 'This room has expired. <a href="/">Create a new room</a>';
+      roomEl.prepend(note);
+      document.querySelectorAll('#actions form, #deck form').forEach(function(f) {
+        f.querySelectorAll('button').forEach(function(b) { b.disabled = true; });
+      });
+    });
+    window.addEventListener('beforeunload', function() { source.close(); });
+  }
+})();